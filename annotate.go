@@ -0,0 +1,88 @@
+package flaw
+
+import (
+	"fmt"
+	"strings"
+)
+
+const keyAnnotations = "error_annotations"
+
+// Annotate wraps the error with a layer that describes what was being done when
+// the error occurred, e.g. "loading user profile". The layers are rendered as
+// a "while X / while Y / caused by Z" narrative in verbose mode and as an
+// array in JSON. The context of the layer is optional. It returns nil if the
+// error is nil.
+func Annotate(err error, msg string, context ...Map) *Error {
+	if err == nil {
+		return nil
+	}
+
+	errx := wrap(err)
+	errx.msg = msg
+	errx.annotation = true
+
+	for _, item := range context {
+		for k, v := range item {
+			errx.put(k, v)
+		}
+	}
+
+	return errx.capture(1)
+}
+
+// layers returns the annotation layers from the top to the bottom and the
+// error that they annotate
+func (x *Error) layers() ([]*Error, error) {
+	var (
+		items []*Error
+		err   error = x
+	)
+
+	for {
+		errx, ok := err.(*Error)
+		if !ok || !errx.annotation || contains(items, errx) {
+			return items, err
+		}
+
+		items = append(items, errx)
+		err = errx.reason
+	}
+}
+
+// cause returns the error that is rendered as the cause
+func (x *Error) cause() error {
+	if !x.annotation {
+		return x.reason
+	}
+
+	_, err := x.layers()
+	return err
+}
+
+// narrate writes the annotation layers as an indented narrative
+func (x *Error) narrate(state fmt.State) {
+	items, err := x.layers()
+
+	for index, item := range items {
+		if index > 0 {
+			fmt.Fprint(state, "\n", strings.Repeat("  ", index))
+		}
+
+		fmt.Fprint(state, "while ", item.message())
+	}
+
+	fmt.Fprint(state, "\n", strings.Repeat("  ", len(items)))
+	fmt.Fprintf(state, "caused by %v", err)
+}
+
+// contains reports whether the layer has been visited already, so that a
+// cycle of layers is not walked forever
+func contains(items []*Error, errx *Error) bool {
+	for _, item := range items {
+		if item == errx {
+			return true
+		}
+	}
+
+	return false
+}
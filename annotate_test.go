@@ -0,0 +1,66 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Annotate", func() {
+	It("returns nil for a nil error", func() {
+		Expect(flaw.Annotate(nil, "loading user profile")).To(BeNil())
+	})
+
+	It("annotates the error", func() {
+		cause := fmt.Errorf("sql: no rows")
+		err := flaw.Annotate(cause, "loading user profile", flaw.Map{"user_id": "42"})
+
+		Expect(err.Message()).To(Equal("loading user profile"))
+		Expect(err.Context()).To(HaveKeyWithValue("user_id", "42"))
+		Expect(err.StackTrace()).NotTo(BeEmpty())
+		Expect(err).To(MatchError(cause))
+	})
+
+	It("inherits the code and the status", func() {
+		err := flaw.Annotate(os.ErrNotExist, "reading config")
+
+		Expect(err.Code()).To(Equal(5))
+		Expect(err.Status()).To(Equal(404))
+	})
+
+	It("formats the layers as a narrative", func() {
+		cause := fmt.Errorf("sql: no rows")
+		err := flaw.Annotate(flaw.Annotate(cause, "querying users"), "loading user profile")
+
+		text := fmt.Sprintf("%+v", err)
+		Expect(text).To(ContainSubstring("message: while loading user profile\n"))
+		Expect(text).To(ContainSubstring("            while querying users\n"))
+		Expect(text).To(ContainSubstring("              caused by sql: no rows\n"))
+		Expect(text).NotTo(ContainSubstring("cause:"))
+	})
+
+	It("marshals the layers as an array", func() {
+		cause := flaw.Errorf("user not found").WithCode(5)
+		err := flaw.Annotate(
+			flaw.Annotate(cause, "querying users", flaw.Map{"table": "users", "user_id": "1"}),
+			"loading user profile",
+			flaw.Map{"user_id": "42"},
+		)
+
+		data, jerr := json.Marshal(err)
+		Expect(jerr).NotTo(HaveOccurred())
+
+		m := map[string]interface{}{}
+		Expect(json.Unmarshal(data, &m)).To(Succeed())
+		Expect(m).To(HaveKeyWithValue("error_annotations", []interface{}{"loading user profile", "querying users"}))
+		Expect(m).To(HaveKeyWithValue("table", "users"))
+		Expect(m).To(HaveKeyWithValue("user_id", "42"))
+		Expect(m).NotTo(HaveKey("error_message"))
+		Expect(m).To(HaveKeyWithValue("error_cause", HaveKeyWithValue("error_message", "user not found")))
+	})
+})
@@ -0,0 +1,77 @@
+package flaw
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+)
+
+// MaxAttachmentSize is the maximum size of the attachment data in bytes.
+// The data that exceeds the size is truncated.
+const MaxAttachmentSize = 16 << 10
+
+// Attachment represents a small payload carried by the error, such as the
+// failed request body or the input of a parser. The attachments are
+// serialized as base64 and they are not included in the text formatting.
+type Attachment struct {
+	// Name is the attachment name
+	Name string `json:"name"`
+	// MimeType is the attachment mime type
+	MimeType string `json:"mime_type,omitempty"`
+	// Data is the attachment data
+	Data []byte `json:"data"`
+	// Truncated is true if the data has been truncated, e.g. because it exceeds
+	// MaxAttachmentSize
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// NewAttachment creates a new attachment. The data is copied and truncated
+// to MaxAttachmentSize.
+func NewAttachment(name string, data []byte, mime string) Attachment {
+	attachment := Attachment{
+		Name:     name,
+		MimeType: mime,
+	}
+
+	if len(data) > MaxAttachmentSize {
+		data = data[:MaxAttachmentSize]
+		attachment.Truncated = true
+	}
+
+	attachment.Data = make([]byte, len(data))
+	copy(attachment.Data, data)
+
+	return attachment
+}
+
+// MarshalXML marshals the attachment as xml
+func (x Attachment) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "Name"}, Value: x.Name},
+		xml.Attr{Name: xml.Name{Local: "MimeType"}, Value: x.MimeType},
+	)
+
+	if x.Truncated {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "Truncated"}, Value: "true"})
+	}
+
+	return encoder.EncodeElement(base64.StdEncoding.EncodeToString(x.Data), start)
+}
+
+type attachments []Attachment
+
+// MarshalXML marshals the attachments as xml
+func (x attachments) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	element := xml.StartElement{Name: xml.Name{Local: "Attachment"}}
+
+	for _, attachment := range x {
+		if err := encoder.EncodeElement(attachment, element); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
@@ -0,0 +1,97 @@
+package flaw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Attachment", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("invalid payload").WithAttachment("body", []byte(`{"name":`), "application/json")
+	})
+
+	It("creates an error successfully", func() {
+		Expect(errx.Attachments()).To(HaveLen(1))
+		Expect(errx.Attachments()[0].Name).To(Equal("body"))
+		Expect(errx.Attachments()[0].MimeType).To(Equal("application/json"))
+		Expect(errx.Attachments()[0].Data).To(Equal([]byte(`{"name":`)))
+	})
+
+	It("does not print the attachments", func() {
+		Expect(fmt.Sprintf("%v", errx)).To(Equal("message: invalid payload"))
+	})
+
+	It("marshals the attachments as base64 json", func() {
+		data, err := json.Marshal(errx)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(`{"error_attachments":[{"name":"body","mime_type":"application/json","data":"eyJuYW1lIjo="}],"error_message":"invalid payload"}`))
+	})
+
+	It("marshals the attachments as base64 xml", func() {
+		data, err := xml.Marshal(errx)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring(`<ErrorAttachments><Attachment Name="body" MimeType="application/json">eyJuYW1lIjo=</Attachment></ErrorAttachments>`))
+	})
+
+	It("does not share the attachments between the copies", func() {
+		first := errx.WithAttachment("first", []byte("1"), "text/plain")
+		second := errx.WithAttachment("second", []byte("2"), "text/plain")
+
+		Expect(first.Attachments()).To(HaveLen(2))
+		Expect(first.Attachments()[1].Name).To(Equal("first"))
+		Expect(second.Attachments()).To(HaveLen(2))
+		Expect(second.Attachments()[1].Name).To(Equal("second"))
+	})
+
+	It("adds the attachments as they are", func() {
+		attachment := flaw.Attachment{Name: "input", Data: []byte("a"), Truncated: true}
+
+		first := errx.WithAttachments(attachment)
+		second := errx.WithAttachments()
+
+		Expect(first.Attachments()).To(HaveLen(2))
+		Expect(first.Attachments()[1]).To(Equal(attachment))
+		Expect(second.Attachments()).To(HaveLen(1))
+	})
+
+	Context("when the data exceeds the max size", func() {
+		It("truncates the data", func() {
+			data := bytes.Repeat([]byte("a"), flaw.MaxAttachmentSize+1)
+
+			attachment := flaw.NewAttachment("input", data, "text/plain")
+			Expect(attachment.Data).To(HaveLen(flaw.MaxAttachmentSize))
+			Expect(attachment.Truncated).To(BeTrue())
+		})
+
+		It("truncates the data of the given attachments", func() {
+			data := bytes.Repeat([]byte("a"), flaw.MaxAttachmentSize+1)
+
+			err := flaw.Errorf("oh no").WithAttachments(flaw.Attachment{Name: "input", Data: data})
+			Expect(err.Attachments()).To(HaveLen(1))
+			Expect(err.Attachments()[0].Data).To(HaveLen(flaw.MaxAttachmentSize))
+			Expect(err.Attachments()[0].Truncated).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("WithGoroutineDump", func() {
+	It("attaches the goroutine dump", func() {
+		err := flaw.Errorf("oh no").WithGoroutineDump()
+
+		attachments := err.Attachments()
+		Expect(attachments).To(HaveLen(1))
+		Expect(attachments[0].Name).To(Equal("goroutines"))
+		Expect(attachments[0].MimeType).To(Equal("text/plain"))
+		Expect(string(attachments[0].Data)).To(HavePrefix("goroutine "))
+		Expect(len(attachments[0].Data)).To(BeNumerically("<=", flaw.MaxAttachmentSize))
+	})
+})
@@ -0,0 +1,67 @@
+//go:build !race
+
+package bench_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/phogolabs/flaw"
+)
+
+// TestAllocs guards the allocations of the hot paths. Lower the budgets when
+// a change reduces the allocations.
+func TestAllocs(t *testing.T) {
+	err := flaw.Errorf("user not found").
+		WithCode(5).
+		WithDetails("the user might be deleted").
+		WithContext(flaw.Map{"user": "root"}).
+		WithError(errCause)
+
+	assertAllocs(t, []allocCase{
+		{
+			name:   "Errorf",
+			budget: 12,
+			fn:     func() { sink = flaw.Errorf("user %v not found", 42) },
+		},
+		{
+			name:   "Wrap",
+			budget: 11,
+			fn:     func() { sink = flaw.Wrap(errCause) },
+		},
+		{
+			name:   "WithContext",
+			budget: 3,
+			fn:     func() { sink = err.WithContext(flaw.Map{"user": "root"}) },
+		},
+		{
+			name:   "Format",
+			budget: 12,
+			fn:     func() { fmt.Fprintf(io.Discard, "%v", err) },
+		},
+		{
+			name:   "MarshalJSON",
+			budget: 32,
+			fn:     func() { sink, _ = json.Marshal(err) },
+		},
+	})
+}
+
+// allocCase is a hot path with its allocation budget
+type allocCase struct {
+	name   string
+	budget float64
+	fn     func()
+}
+
+func assertAllocs(t *testing.T, cases []allocCase) {
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, item.fn); allocs > item.budget {
+				t.Errorf("allocs %v exceed the budget %v", allocs, item.budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package bench_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/phogolabs/flaw"
+)
+
+var (
+	errCause = fmt.Errorf("oh no")
+	sink     interface{}
+)
+
+func BenchmarkErrorf(b *testing.B) {
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink = flaw.Errorf("user %v not found", index)
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink = flaw.Wrap(errCause)
+	}
+}
+
+func BenchmarkFormat(b *testing.B) {
+	err := flaw.Errorf("user not found").
+		WithCode(404).
+		WithDetails("the user might be deleted").
+		WithError(errCause)
+
+	b.Run("v", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for index := 0; index < b.N; index++ {
+			fmt.Fprintf(io.Discard, "%v", err)
+		}
+	})
+
+	b.Run("+v", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for index := 0; index < b.N; index++ {
+			fmt.Fprintf(io.Discard, "%+v", err)
+		}
+	})
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	err := flaw.Errorf("user not found").
+		WithCode(404).
+		WithDetails("the user might be deleted").
+		WithContext(flaw.Map{"user": "root"}).
+		WithError(errCause)
+
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink, _ = json.Marshal(err)
+	}
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	err := flaw.Errorf("user not found").
+		WithCode(404).
+		WithDetails("the user might be deleted").
+		WithContext(flaw.Map{"user": "root"}).
+		WithError(errCause)
+
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink, _ = err.MarshalBinary()
+	}
+}
+
+func BenchmarkContext(b *testing.B) {
+	for _, size := range []int{1, 16, 256} {
+		context := flaw.Map{}
+
+		for index := 0; index < size; index++ {
+			context[fmt.Sprintf("key_%d", index)] = index
+		}
+
+		err := flaw.Errorf("user not found").WithCode(5)
+
+		b.Run(fmt.Sprintf("WithContext/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for index := 0; index < b.N; index++ {
+				sink = err.WithContext(context)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Context/%d", size), func(b *testing.B) {
+			errx := err.WithContext(context)
+
+			b.ReportAllocs()
+
+			for index := 0; index < b.N; index++ {
+				sink = errx.Context()
+			}
+		})
+	}
+}
+
+func BenchmarkErrorCollector(b *testing.B) {
+	b.Run("Wrap", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for index := 0; index < b.N; index++ {
+			errs := flaw.ErrorCollector{}
+
+			for count := 0; count < 10; count++ {
+				errs.Wrap(errCause)
+			}
+
+			sink = errs
+		}
+	})
+
+	b.Run("MarshalJSON", func(b *testing.B) {
+		errs := flaw.ErrorCollector{}
+
+		for count := 0; count < 10; count++ {
+			errs.Wrap(flaw.Errorf("item %d is invalid", count))
+		}
+
+		b.ReportAllocs()
+
+		for index := 0; index < b.N; index++ {
+			sink, _ = json.Marshal(errs)
+		}
+	})
+}
+
+func BenchmarkStackTrace(b *testing.B) {
+	var (
+		stack = flaw.Errorf("oh no").StackTrace()
+		other = flaw.Errorf("oh no").StackTrace()
+	)
+
+	b.Run("Hash", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for index := 0; index < b.N; index++ {
+			sink = stack.Hash()
+		}
+	})
+
+	b.Run("Equal", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for index := 0; index < b.N; index++ {
+			sink = stack.Equal(other)
+		}
+	})
+}
+
+func BenchmarkErrorfLite(b *testing.B) {
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink = flaw.ErrorfLite("user %v not found", index)
+	}
+}
+
+func BenchmarkWrapLite(b *testing.B) {
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink = flaw.WrapLite(errCause)
+	}
+}
+
+func BenchmarkErrorfContext(b *testing.B) {
+	b.ReportAllocs()
+
+	context := flaw.Map{"user_id": 42}
+
+	for index := 0; index < b.N; index++ {
+		sink = flaw.Errorf("user %v not found", index).WithContext(context)
+	}
+}
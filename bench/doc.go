@@ -0,0 +1,5 @@
+// Package bench contains the benchmarks of the flaw package. The benchmarks
+// are the baseline that the performance changes are measured against:
+//
+//	go test -run=^$ -bench=. -benchmem ./bench
+package bench
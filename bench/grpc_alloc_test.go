@@ -0,0 +1,33 @@
+//go:build !race && !flaw_nogrpc
+
+package bench_test
+
+import (
+	"testing"
+
+	"github.com/phogolabs/flaw"
+)
+
+// TestGRPCAllocs guards the allocations of the gRPC status
+func TestGRPCAllocs(t *testing.T) {
+	err := flaw.Errorf("user not found").
+		WithCode(5).
+		WithDetails("the user might be deleted").
+		WithContext(flaw.Map{"user": "root"}).
+		WithError(errCause)
+
+	frozen := err.WithCode(5).Freeze()
+
+	assertAllocs(t, []allocCase{
+		{
+			name:   "GRPCStatus",
+			budget: 40,
+			fn:     func() { sink = err.GRPCStatus() },
+		},
+		{
+			name:   "GRPCStatusFrozen",
+			budget: 0,
+			fn:     func() { sink = frozen.GRPCStatus() },
+		},
+	})
+}
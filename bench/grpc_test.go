@@ -0,0 +1,36 @@
+//go:build !flaw_nogrpc
+
+package bench_test
+
+import (
+	"testing"
+
+	"github.com/phogolabs/flaw"
+)
+
+func BenchmarkGRPCStatus(b *testing.B) {
+	err := flaw.Errorf("user not found").
+		WithCode(5).
+		WithDetails("the user might be deleted").
+		WithContext(flaw.Map{"user": "root"})
+
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink = err.GRPCStatus()
+	}
+}
+
+func BenchmarkGRPCStatusFrozen(b *testing.B) {
+	err := flaw.Errorf("user not found").
+		WithCode(5).
+		WithDetails("the user might be deleted").
+		WithContext(flaw.Map{"user": "root"}).
+		Freeze()
+
+	b.ReportAllocs()
+
+	for index := 0; index < b.N; index++ {
+		sink = err.GRPCStatus()
+	}
+}
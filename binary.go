@@ -0,0 +1,569 @@
+package flaw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Compressor compresses the binary encoding of the errors, e.g. with zstd
+type Compressor interface {
+	// Compress compresses the data
+	Compress(data []byte) ([]byte, error)
+	// Decompress decompresses the data
+	Decompress(data []byte) ([]byte, error)
+}
+
+type compressor struct {
+	Compressor
+}
+
+var compressors atomic.Value
+
+// SetCompressor sets the compressor of the binary encoding. The encoding is
+// not compressed by default. Pass nil to disable the compression.
+func SetCompressor(value Compressor) {
+	compressors.Store(compressor{Compressor: value})
+}
+
+// the version of the binary encoding
+const binaryVersion = 1
+
+// the flags of the binary encoding
+const flagCompressed = 1
+
+// the fields of the binary encoding
+const (
+	fieldCode = iota + 1
+	fieldStatus
+	fieldMessage
+	fieldNamespace
+	fieldUser
+	fieldTenant
+	fieldFallback
+	fieldRunbook
+	fieldIncident
+	fieldDetail
+	fieldArg
+	fieldContext
+	fieldCause
+	fieldFrame
+	fieldAttachment
+	fieldAnnotation
+	fieldSeverity
+	fieldEscalation
+	fieldRemoteService
+	fieldRemoteEndpoint
+	fieldRemoteFrame
+	fieldPointer
+	fieldComponent
+)
+
+// the kinds of the encoded causes
+const (
+	kindFlaw = iota + 1
+	kindText
+	kindCollection
+	kindJSON
+)
+
+var errBinaryTruncated = errors.New("flaw: truncated binary encoding")
+
+// MarshalBinary encodes the error in a compact binary form, which is cheaper
+// than json for the high-volume event streams. The fields are length-prefixed
+// and the integers are varints. The encoding is compressed if a compressor is
+// set.
+func (x *Error) MarshalBinary() ([]byte, error) {
+	body, err := x.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := byte(0)
+
+	if item, _ := compressors.Load().(compressor); item.Compressor != nil {
+		if body, err = item.Compress(body); err != nil {
+			return nil, err
+		}
+
+		flags |= flagCompressed
+	}
+
+	data := make([]byte, 0, len(body)+2)
+	data = append(data, binaryVersion, flags)
+	data = append(data, body...)
+
+	stats.bytes.Add(uint64(len(data)))
+	return data, nil
+}
+
+// UnmarshalBinary decodes the error from its binary form. The causes are
+// decoded in the same way as by UnmarshalJSON. The unknown fields are skipped.
+func (x *Error) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errBinaryTruncated
+	}
+
+	if data[0] != binaryVersion {
+		return fmt.Errorf("flaw: unsupported binary version %d", data[0])
+	}
+
+	body := data[2:]
+
+	if data[1]&flagCompressed != 0 {
+		item, _ := compressors.Load().(compressor)
+		if item.Compressor == nil {
+			return errors.New("flaw: binary encoding is compressed, but a compressor is not set")
+		}
+
+		var err error
+
+		if body, err = item.Decompress(body); err != nil {
+			return err
+		}
+	}
+
+	errx := Error{
+		status:  500,
+		context: Map{},
+	}
+
+	if err := errx.unpack(body); err != nil {
+		return err
+	}
+
+	*x = errx
+	return nil
+}
+
+// encode returns the fields of the error
+func (x *Error) encode() ([]byte, error) {
+	w := &packer{}
+
+	w.int(fieldCode, x.code)
+	// the status is written even if zero, because the default status is 500
+	w.field(fieldStatus, binary.AppendVarint(nil, int64(x.status)))
+	w.string(fieldMessage, x.message())
+	w.string(fieldNamespace, x.namespace)
+	w.string(fieldUser, x.user)
+	w.string(fieldTenant, x.tenant)
+	w.string(fieldFallback, x.fallback)
+	w.string(fieldRunbook, x.runbook)
+	w.string(fieldIncident, x.incident)
+
+	for _, item := range x.details {
+		w.field(fieldDetail, []byte(item))
+	}
+
+	for _, item := range x.args {
+		w.field(fieldArg, []byte(item))
+	}
+
+	if len(x.context) > 0 {
+		context := make(dictionary, len(x.context))
+
+		for key, value := range x.context {
+			if !x.exported(key) {
+				continue
+			}
+
+			if x.loops(value) {
+				value = cycleMarker
+			}
+
+			context[key] = value
+		}
+
+		data, err := json.Marshal(context.render())
+		if err != nil {
+			return nil, err
+		}
+
+		w.field(fieldContext, data)
+	}
+
+	if x.reason != nil {
+		var reason error = x.reason
+
+		if x.loops(reason) {
+			reason = errors.New(cycleMarker)
+		}
+
+		data, err := encodeCause(reason)
+		if err != nil {
+			return nil, err
+		}
+
+		w.field(fieldCause, data)
+	}
+
+	for _, frame := range x.stack {
+		w.field(fieldFrame, packFrame(frame))
+	}
+
+	for _, attachment := range x.attachments {
+		item := &packer{}
+		item.string(1, attachment.Name)
+		item.string(2, attachment.MimeType)
+		item.field(3, attachment.Data)
+
+		if attachment.Truncated {
+			item.int(4, 1)
+		}
+
+		w.field(fieldAttachment, item.data)
+	}
+
+	if x.annotation {
+		w.int(fieldAnnotation, 1)
+	}
+
+	w.int(fieldSeverity, int(x.severity))
+
+	w.string(fieldRemoteService, x.remote.Service)
+	w.string(fieldRemoteEndpoint, x.remote.Endpoint)
+
+	for _, frame := range x.remoteStack {
+		w.field(fieldRemoteFrame, packFrame(frame))
+	}
+
+	w.string(fieldPointer, x.pointer)
+	w.string(fieldComponent, x.component)
+
+	for _, escalation := range x.escalations {
+		item := &packer{}
+		item.int(1, int(escalation.From))
+		item.int(2, int(escalation.To))
+		item.string(3, escalation.Reason)
+
+		w.field(fieldEscalation, item.data)
+	}
+
+	return w.data, nil
+}
+
+// unpack decodes the fields of the error
+func (x *Error) unpack(data []byte) error {
+	r := &unpacker{data: data}
+
+	for {
+		field, value, ok, err := r.next()
+		if err != nil || !ok {
+			return err
+		}
+
+		switch field {
+		case fieldCode:
+			x.code, err = varint(value)
+		case fieldStatus:
+			x.status, err = varint(value)
+		case fieldMessage:
+			x.msg = string(value)
+		case fieldNamespace:
+			x.namespace = string(value)
+		case fieldUser:
+			x.user = string(value)
+		case fieldTenant:
+			x.tenant = string(value)
+		case fieldFallback:
+			x.fallback = string(value)
+		case fieldRunbook:
+			x.runbook = string(value)
+		case fieldIncident:
+			x.incident = string(value)
+		case fieldDetail:
+			x.details = append(x.details, string(value))
+		case fieldArg:
+			x.args = append(x.args, string(value))
+		case fieldContext:
+			decoder := json.NewDecoder(bytes.NewReader(value))
+			// the numbers keep their exact representation
+			decoder.UseNumber()
+			err = decoder.Decode(&x.context)
+		case fieldCause:
+			x.reason, err = decodeBinaryCause(value)
+		case fieldFrame:
+			var frame StackFrame
+
+			frame, err = unpackFrame(value)
+			x.stack = append(x.stack, frame)
+		case fieldAttachment:
+			var attachment Attachment
+
+			err = unpack(value, func(field int, value []byte) error {
+				switch field {
+				case 1:
+					attachment.Name = string(value)
+				case 2:
+					attachment.MimeType = string(value)
+				case 3:
+					attachment.Data = append([]byte{}, value...)
+				case 4:
+					attachment.Truncated = true
+				}
+
+				return nil
+			})
+
+			x.attachments = append(x.attachments, attachment)
+		case fieldAnnotation:
+			x.annotation = true
+		case fieldSeverity:
+			var severity int
+			severity, err = varint(value)
+			x.severity = Severity(severity)
+		case fieldEscalation:
+			var escalation Escalation
+
+			err = unpack(value, func(field int, value []byte) (err error) {
+				var level int
+
+				switch field {
+				case 1:
+					level, err = varint(value)
+					escalation.From = Severity(level)
+				case 2:
+					level, err = varint(value)
+					escalation.To = Severity(level)
+				case 3:
+					escalation.Reason = string(value)
+				}
+
+				return err
+			})
+
+			x.escalations = append(x.escalations, escalation)
+		case fieldRemoteService:
+			x.remote.Service = string(value)
+		case fieldRemoteEndpoint:
+			x.remote.Endpoint = string(value)
+		case fieldRemoteFrame:
+			var frame StackFrame
+
+			frame, err = unpackFrame(value)
+			x.remoteStack = append(x.remoteStack, frame)
+		case fieldPointer:
+			x.pointer = string(value)
+		case fieldComponent:
+			x.component = string(value)
+		}
+
+		if err != nil {
+			return fmt.Errorf("flaw: cannot decode binary field %d: %w", field, err)
+		}
+	}
+}
+
+// encodeCause encodes the cause prefixed with its kind
+func encodeCause(err error) ([]byte, error) {
+	switch cause := err.(type) {
+	case *Error:
+		data, err := cause.encode()
+		return append([]byte{kindFlaw}, data...), err
+	case ErrorCollector:
+		w := &packer{data: []byte{kindCollection}}
+
+		for _, item := range cause {
+			entry, ok := item.(*keyed)
+			if !ok {
+				data, err := encodeCause(item)
+				if err != nil {
+					return nil, err
+				}
+
+				w.field(1, data)
+				continue
+			}
+
+			data, err := encodeCause(entry.err)
+			if err != nil {
+				return nil, err
+			}
+
+			// the keyed errors are encoded with their key
+			value := &packer{}
+			value.string(1, entry.key)
+			value.field(2, data)
+
+			w.field(2, value.data)
+		}
+
+		return w.data, nil
+	case json.Marshaler:
+		data, err := cause.MarshalJSON()
+		return append([]byte{kindJSON}, data...), err
+	default:
+		return append([]byte{kindText}, cause.Error()...), nil
+	}
+}
+
+// decodeBinaryCause decodes a cause encoded by encodeCause
+func decodeBinaryCause(data []byte) (error, error) {
+	if len(data) == 0 {
+		return nil, errBinaryTruncated
+	}
+
+	kind, data := data[0], data[1:]
+
+	switch kind {
+	case kindFlaw:
+		errx := &Error{
+			status:  500,
+			context: Map{},
+		}
+
+		return errx, errx.unpack(data)
+	case kindCollection:
+		errs := ErrorCollector{}
+
+		err := unpack(data, func(field int, value []byte) error {
+			if field == 1 {
+				cause, err := decodeBinaryCause(value)
+				errs = append(errs, cause)
+				return err
+			}
+
+			var (
+				key   string
+				cause error
+			)
+
+			err := unpack(value, func(field int, value []byte) (err error) {
+				switch field {
+				case 1:
+					key = string(value)
+				case 2:
+					cause, err = decodeBinaryCause(value)
+				}
+
+				return err
+			})
+
+			if err == nil && cause == nil {
+				err = errBinaryTruncated
+			}
+
+			errs.WrapKey(key, cause)
+			return err
+		})
+
+		return errs, err
+	case kindJSON:
+		return rawError(append(json.RawMessage{}, data...)), nil
+	case kindText:
+		return errors.New(string(data)), nil
+	default:
+		return nil, fmt.Errorf("unknown kind %d", kind)
+	}
+}
+
+// packer appends the length-prefixed fields
+type packer struct {
+	data []byte
+}
+
+func (w *packer) field(field int, value []byte) {
+	w.data = binary.AppendUvarint(w.data, uint64(field))
+	w.data = binary.AppendUvarint(w.data, uint64(len(value)))
+	w.data = append(w.data, value...)
+}
+
+// int appends a non-zero integer field
+func (w *packer) int(field int, value int) {
+	if value != 0 {
+		w.field(field, binary.AppendVarint(nil, int64(value)))
+	}
+}
+
+// string appends a non-empty string field
+func (w *packer) string(field int, value string) {
+	if value != "" {
+		w.field(field, []byte(value))
+	}
+}
+
+// unpacker reads the length-prefixed fields
+type unpacker struct {
+	data []byte
+}
+
+func (r *unpacker) next() (int, []byte, bool, error) {
+	if len(r.data) == 0 {
+		return 0, nil, false, nil
+	}
+
+	field, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		return 0, nil, false, errBinaryTruncated
+	}
+
+	r.data = r.data[n:]
+
+	size, n := binary.Uvarint(r.data)
+	if n <= 0 || uint64(len(r.data)-n) < size {
+		return 0, nil, false, errBinaryTruncated
+	}
+
+	value := r.data[n : n+int(size)]
+	r.data = r.data[n+int(size):]
+
+	return int(field), value, true, nil
+}
+
+// unpack calls the function for every field of the data
+func unpack(data []byte, fn func(field int, value []byte) error) error {
+	r := &unpacker{data: data}
+
+	for {
+		field, value, ok, err := r.next()
+		if err != nil || !ok {
+			return err
+		}
+
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+}
+
+func varint(data []byte) (int, error) {
+	value, n := binary.Varint(data)
+	if n <= 0 {
+		return 0, errBinaryTruncated
+	}
+
+	return int(value), nil
+}
+
+// packFrame encodes the stack frame
+func packFrame(frame StackFrame) []byte {
+	item := &packer{}
+	item.string(1, frame.File)
+	item.int(2, frame.Line)
+	item.string(3, frame.Function)
+
+	return item.data
+}
+
+// unpackFrame decodes the stack frame
+func unpackFrame(data []byte) (StackFrame, error) {
+	var frame StackFrame
+
+	err := unpack(data, func(field int, value []byte) (err error) {
+		switch field {
+		case 1:
+			frame.File = string(value)
+		case 2:
+			frame.Line, err = varint(value)
+		case 3:
+			frame.Function = string(value)
+		}
+
+		return err
+	})
+
+	return frame, err
+}
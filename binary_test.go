@@ -0,0 +1,150 @@
+package flaw_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type FlateCompressor struct{}
+
+func (FlateCompressor) Compress(data []byte) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+
+	writer, err := flate.NewWriter(buffer, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (FlateCompressor) Decompress(data []byte) ([]byte, error) {
+	return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+}
+
+var _ = Describe("MarshalBinary", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithDetails("id is invalid").
+			WithArgs("root", 42).
+			WithRunbook("https://runbooks.example.com/users").
+			WithContext(flaw.Map{"user_id": 42}).
+			WithAttachment("body", []byte(`{"id":42}`), "application/json").
+			WithError(flaw.ErrorCollector{
+				fmt.Errorf("oh no"),
+				flaw.Errorf("oh yes").WithCode(13),
+			})
+	})
+
+	AfterEach(func() {
+		flaw.SetCompressor(nil)
+	})
+
+	roundtrip := func(err *flaw.Error) *flaw.Error {
+		data, merr := err.MarshalBinary()
+		Expect(merr).NotTo(HaveOccurred())
+
+		errx := &flaw.Error{}
+		Expect(errx.UnmarshalBinary(data)).To(Succeed())
+		return errx
+	}
+
+	It("decodes the error", func() {
+		item := roundtrip(errx)
+
+		Expect(item.Code()).To(Equal(5))
+		Expect(item.Status()).To(Equal(404))
+		Expect(item.Message()).To(Equal("user not found"))
+		Expect(item.Details()).To(ConsistOf("id is invalid"))
+		Expect(item.Runbook()).To(Equal("https://runbooks.example.com/users"))
+		Expect(item.Context()).To(HaveKeyWithValue("user_id", json.Number("42")))
+		Expect(item.Context()).To(HaveKeyWithValue("error_args", ConsistOf("root", "42")))
+		Expect(item.Attachments()).To(Equal(errx.Attachments()))
+		Expect(item.StackTrace()).To(HaveLen(len(errx.StackTrace())))
+		Expect(item.StackTrace()[0].File).To(Equal(errx.StackTrace()[0].File))
+		Expect(item.StackTrace()[0].Line).To(Equal(errx.StackTrace()[0].Line))
+	})
+
+	It("decodes the causes", func() {
+		item := roundtrip(errx)
+
+		errs, ok := item.Cause().(flaw.ErrorCollector)
+		Expect(ok).To(BeTrue())
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0]).To(MatchError("oh no"))
+
+		var cause *flaw.Error
+		Expect(errors.As(errs[1], &cause)).To(BeTrue())
+		Expect(cause.Code()).To(Equal(13))
+	})
+
+	It("keeps the zero status", func() {
+		Expect(roundtrip(flaw.Errorf("oh no").WithStatus(0)).Status()).To(Equal(0))
+	})
+
+	It("keeps the annotations", func() {
+		err := flaw.Annotate(fmt.Errorf("sql: no rows"), "loading user profile")
+		Expect(fmt.Sprintf("%+v", roundtrip(err))).To(ContainSubstring("while loading user profile"))
+	})
+
+	It("is smaller than json", func() {
+		flaw.SetStackSampling(0)
+		defer flaw.SetStackSampling(1)
+
+		// the json does not contain the stack trace
+		errx = flaw.Errorf("user not found").
+			WithCode(5).
+			WithDetails("id is invalid").
+			WithContext(flaw.Map{"user_id": 42}).
+			WithError(flaw.Errorf("oh no"))
+
+		data, err := errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		payload, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(data)).To(BeNumerically("<", len(payload)))
+	})
+
+	It("compresses the encoding", func() {
+		plain, err := errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		flaw.SetCompressor(FlateCompressor{})
+
+		data, err := errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).NotTo(Equal(plain))
+		Expect(roundtrip(errx).Message()).To(Equal("user not found"))
+
+		flaw.SetCompressor(nil)
+		Expect((&flaw.Error{}).UnmarshalBinary(data)).To(MatchError(ContainSubstring("compressor is not set")))
+	})
+
+	It("returns an error for an invalid encoding", func() {
+		Expect((&flaw.Error{}).UnmarshalBinary(nil)).To(HaveOccurred())
+		Expect((&flaw.Error{}).UnmarshalBinary([]byte{9, 0})).To(MatchError(ContainSubstring("unsupported binary version")))
+		Expect((&flaw.Error{}).UnmarshalBinary([]byte{1, 0, 3, 10, 'a'})).To(HaveOccurred())
+	})
+})
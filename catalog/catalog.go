@@ -0,0 +1,115 @@
+// Package catalog defines the catalog of error definitions, which is used to
+// keep the service error inventories consistent and reviewable.
+package catalog
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entry represents an error definition
+type Entry struct {
+	// Name is the name of the error in PascalCase
+	Name string `yaml:"name" json:"name"`
+	// Description describes when the error occurs
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Code is the error code
+	Code int `yaml:"code,omitempty" json:"code,omitempty"`
+	// Status is the error status
+	Status int `yaml:"status,omitempty" json:"status,omitempty"`
+	// Message is the error message template
+	Message string `yaml:"message" json:"message"`
+	// DocsURL is the URL of the error documentation
+	DocsURL string `yaml:"docs_url,omitempty" json:"docs_url,omitempty"`
+}
+
+// Catalog represents a set of error definitions
+type Catalog struct {
+	// Package is the name of the package that owns the errors
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+	// Errors are the error definitions
+	Errors []Entry `yaml:"errors" json:"errors"`
+}
+
+// Load loads the catalog from a YAML or JSON document
+func Load(reader io.Reader) (*Catalog, error) {
+	catalog := &Catalog{}
+
+	if err := yaml.NewDecoder(reader).Decode(catalog); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+// Open loads the catalog from a YAML or JSON file
+func Open(path string) (*Catalog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// close the file
+	defer file.Close()
+
+	return Load(file)
+}
+
+// Validate validates the catalog
+func (c *Catalog) Validate() error {
+	names := make(map[string]struct{})
+
+	for index, entry := range c.Errors {
+		if !token.IsIdentifier(entry.Name) || !token.IsExported(entry.Name) {
+			return fmt.Errorf("catalog: entry %d has invalid name %q", index, entry.Name)
+		}
+
+		if _, ok := names[entry.Name]; ok {
+			return fmt.Errorf("catalog: entry %q is duplicated", entry.Name)
+		}
+
+		if entry.Message == "" {
+			return fmt.Errorf("catalog: entry %q does not have a message", entry.Name)
+		}
+
+		// the description is generated as a comment, which lines are
+		// prefixed, while the url must fit on a single line
+		if strings.ContainsFunc(entry.Description, control) {
+			return fmt.Errorf("catalog: entry %q has a control character in the description", entry.Name)
+		}
+
+		if strings.ContainsFunc(entry.DocsURL, unicode.IsControl) {
+			return fmt.Errorf("catalog: entry %q has a control character in the docs url", entry.Name)
+		}
+
+		names[entry.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// control reports whether the rune is a control character other than a new
+// line or a tab
+func control(r rune) bool {
+	return unicode.IsControl(r) && r != '\n' && r != '\t'
+}
+
+// Lookup returns the entry with the given name
+func (c *Catalog) Lookup(name string) (*Entry, bool) {
+	for index := range c.Errors {
+		if entry := &c.Errors[index]; entry.Name == name {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,86 @@
+package catalog_test
+
+import (
+	"strings"
+
+	"github.com/phogolabs/flaw/catalog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Catalog", func() {
+	Describe("Load", func() {
+		It("loads a yaml catalog successfully", func() {
+			errs, err := catalog.Load(strings.NewReader(`
+package: billing
+errors:
+  - name: UserNotFound
+    code: 5
+    status: 404
+    message: user %q not found
+    docs_url: https://example.com/errors/user-not-found
+`))
+			Expect(err).To(BeNil())
+			Expect(errs.Package).To(Equal("billing"))
+			Expect(errs.Errors).To(HaveLen(1))
+
+			entry, ok := errs.Lookup("UserNotFound")
+			Expect(ok).To(BeTrue())
+			Expect(entry.Code).To(Equal(5))
+			Expect(entry.Status).To(Equal(404))
+			Expect(entry.Message).To(Equal("user %q not found"))
+			Expect(entry.DocsURL).To(Equal("https://example.com/errors/user-not-found"))
+		})
+
+		It("loads a json catalog successfully", func() {
+			errs, err := catalog.Load(strings.NewReader(`{"errors": [{"name": "Conflict", "message": "conflict"}]}`))
+			Expect(err).To(BeNil())
+			Expect(errs.Errors).To(HaveLen(1))
+		})
+
+		Context("when the name is invalid", func() {
+			It("returns an error", func() {
+				_, err := catalog.Load(strings.NewReader(`{"errors": [{"name": "user-not-found", "message": "oh no"}]}`))
+				Expect(err).To(MatchError(`catalog: entry 0 has invalid name "user-not-found"`))
+			})
+		})
+
+		Context("when the name is duplicated", func() {
+			It("returns an error", func() {
+				_, err := catalog.Load(strings.NewReader(`{"errors": [{"name": "Conflict", "message": "oh no"}, {"name": "Conflict", "message": "oh no"}]}`))
+				Expect(err).To(MatchError(`catalog: entry "Conflict" is duplicated`))
+			})
+		})
+
+		Context("when the description has a control character", func() {
+			It("returns an error", func() {
+				_, err := catalog.Load(strings.NewReader(`{"errors": [{"name": "Conflict", "message": "oh no", "description": "oh\rno"}]}`))
+				Expect(err).To(MatchError(`catalog: entry "Conflict" has a control character in the description`))
+			})
+		})
+
+		Context("when the docs url has a new line", func() {
+			It("returns an error", func() {
+				_, err := catalog.Load(strings.NewReader(`{"errors": [{"name": "Conflict", "message": "oh no", "docs_url": "https://example.com\nfunc"}]}`))
+				Expect(err).To(MatchError(`catalog: entry "Conflict" has a control character in the docs url`))
+			})
+		})
+
+		Context("when the message is missing", func() {
+			It("returns an error", func() {
+				_, err := catalog.Load(strings.NewReader(`{"errors": [{"name": "Conflict"}]}`))
+				Expect(err).To(MatchError(`catalog: entry "Conflict" does not have a message`))
+			})
+		})
+	})
+
+	Describe("Lookup", func() {
+		Context("when the entry does not exist", func() {
+			It("returns false", func() {
+				_, ok := (&catalog.Catalog{}).Lookup("Conflict")
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
+})
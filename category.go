@@ -0,0 +1,183 @@
+package flaw
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// the categories of the errors, which gRPC codes and HTTP statuses follow the
+// mapping of the gRPC gateway
+var (
+	categoryInvalidArgument    = classification{codeInvalidArgument, http.StatusBadRequest}
+	categoryFailedPrecondition = classification{codeFailedPrecondition, http.StatusBadRequest}
+	categoryUnauthenticated    = classification{codeUnauthenticated, http.StatusUnauthorized}
+	categoryPermissionDenied   = classification{codePermissionDenied, http.StatusForbidden}
+	categoryNotFound           = classification{codeNotFound, http.StatusNotFound}
+	categoryConflict           = classification{codeAlreadyExists, http.StatusConflict}
+	categoryResourceExhausted  = classification{codeResourceExhausted, http.StatusTooManyRequests}
+	categoryInternal           = classification{codeInternal, http.StatusInternalServerError}
+	categoryUnimplemented      = classification{codeUnimplemented, http.StatusNotImplemented}
+	categoryUnavailable        = classification{codeUnavailable, http.StatusServiceUnavailable}
+	categoryDeadlineExceeded   = classification{codeDeadlineExceeded, http.StatusGatewayTimeout}
+)
+
+// InvalidArgumentf creates a new error with the InvalidArgument code and the
+// 400 status
+func InvalidArgumentf(msg string, data ...interface{}) *Error {
+	return categorize(categoryInvalidArgument, msg, data)
+}
+
+// FailedPreconditionf creates a new error with the FailedPrecondition code and
+// the 400 status
+func FailedPreconditionf(msg string, data ...interface{}) *Error {
+	return categorize(categoryFailedPrecondition, msg, data)
+}
+
+// Unauthenticatedf creates a new error with the Unauthenticated code and the
+// 401 status
+func Unauthenticatedf(msg string, data ...interface{}) *Error {
+	return categorize(categoryUnauthenticated, msg, data)
+}
+
+// PermissionDeniedf creates a new error with the PermissionDenied code and the
+// 403 status
+func PermissionDeniedf(msg string, data ...interface{}) *Error {
+	return categorize(categoryPermissionDenied, msg, data)
+}
+
+// NotFoundf creates a new error with the NotFound code and the 404 status
+func NotFoundf(msg string, data ...interface{}) *Error {
+	return categorize(categoryNotFound, msg, data)
+}
+
+// Conflictf creates a new error with the AlreadyExists code and the 409 status
+func Conflictf(msg string, data ...interface{}) *Error {
+	return categorize(categoryConflict, msg, data)
+}
+
+// ResourceExhaustedf creates a new error with the ResourceExhausted code and
+// the 429 status
+func ResourceExhaustedf(msg string, data ...interface{}) *Error {
+	return categorize(categoryResourceExhausted, msg, data)
+}
+
+// Internalf creates a new error with the Internal code and the 500 status
+func Internalf(msg string, data ...interface{}) *Error {
+	return categorize(categoryInternal, msg, data)
+}
+
+// Unimplementedf creates a new error with the Unimplemented code and the 501
+// status
+func Unimplementedf(msg string, data ...interface{}) *Error {
+	return categorize(categoryUnimplemented, msg, data)
+}
+
+// Unavailablef creates a new error with the Unavailable code and the 503
+// status
+func Unavailablef(msg string, data ...interface{}) *Error {
+	return categorize(categoryUnavailable, msg, data)
+}
+
+// DeadlineExceededf creates a new error with the DeadlineExceeded code and the
+// 504 status
+func DeadlineExceededf(msg string, data ...interface{}) *Error {
+	return categorize(categoryDeadlineExceeded, msg, data)
+}
+
+// IsInvalidArgument reports whether the error has the InvalidArgument code
+func IsInvalidArgument(err error) bool {
+	return categorized(err, categoryInvalidArgument)
+}
+
+// IsFailedPrecondition reports whether the error has the FailedPrecondition
+// code
+func IsFailedPrecondition(err error) bool {
+	return categorized(err, categoryFailedPrecondition)
+}
+
+// IsUnauthenticated reports whether the error has the Unauthenticated code or
+// the 401 status
+func IsUnauthenticated(err error) bool {
+	return categorized(err, categoryUnauthenticated)
+}
+
+// IsPermissionDenied reports whether the error has the PermissionDenied code
+// or the 403 status
+func IsPermissionDenied(err error) bool {
+	return categorized(err, categoryPermissionDenied)
+}
+
+// IsNotFound reports whether the error has the NotFound code or the 404 status
+func IsNotFound(err error) bool {
+	return categorized(err, categoryNotFound)
+}
+
+// IsConflict reports whether the error has the AlreadyExists code or the 409
+// status
+func IsConflict(err error) bool {
+	return categorized(err, categoryConflict)
+}
+
+// IsResourceExhausted reports whether the error has the ResourceExhausted code
+// or the 429 status
+func IsResourceExhausted(err error) bool {
+	return categorized(err, categoryResourceExhausted)
+}
+
+// IsInternal reports whether the error has the Internal code
+func IsInternal(err error) bool {
+	return categorized(err, categoryInternal)
+}
+
+// IsUnimplemented reports whether the error has the Unimplemented code or the
+// 501 status
+func IsUnimplemented(err error) bool {
+	return categorized(err, categoryUnimplemented)
+}
+
+// IsUnavailable reports whether the error has the Unavailable code or the 503
+// status
+func IsUnavailable(err error) bool {
+	return categorized(err, categoryUnavailable)
+}
+
+// IsDeadlineExceeded reports whether the error has the DeadlineExceeded code
+// or the 504 status
+func IsDeadlineExceeded(err error) bool {
+	return categorized(err, categoryDeadlineExceeded)
+}
+
+// categorize creates a new error of the category. The stack trace starts at
+// the caller of the category constructor.
+func categorize(item classification, msg string, data []interface{}) *Error {
+	errx := &Error{
+		code:   int(item.code),
+		status: item.status,
+		msg:    fmt.Sprintf(msg, data...),
+	}
+
+	return errx.capture(2)
+}
+
+// categorized reports whether the first flaw error in the chain belongs to
+// the category. The errors without a code are matched by their status, unless
+// the status is shared by several categories, e.g. 400 and 500.
+func categorized(err error, item classification) bool {
+	var errx *Error
+
+	if !errors.As(err, &errx) {
+		return false
+	}
+
+	if errx.code != 0 {
+		return errx.code == int(item.code)
+	}
+
+	switch item.status {
+	case http.StatusBadRequest, http.StatusInternalServerError:
+		return false
+	default:
+		return errx.status == item.status
+	}
+}
@@ -0,0 +1,53 @@
+package flaw_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Category", func() {
+	DescribeTable("constructors",
+		func(fn func(string, ...interface{}) *flaw.Error, is func(error) bool, code, status int) {
+			err := fn("user %v", 42)
+			Expect(err.Message()).To(Equal("user 42"))
+			Expect(err.Code()).To(Equal(code))
+			Expect(err.Status()).To(Equal(status))
+			Expect(err.StackTrace()[0].File).To(HaveSuffix("category_test.go"))
+			Expect(is(err)).To(BeTrue())
+			Expect(is(flaw.Wrap(fmt.Errorf("wrapped: %w", err)))).To(BeTrue())
+			Expect(is(flaw.Errorf("oh no").WithCode(1))).To(BeFalse())
+		},
+		Entry("InvalidArgument", flaw.InvalidArgumentf, flaw.IsInvalidArgument, 3, 400),
+		Entry("FailedPrecondition", flaw.FailedPreconditionf, flaw.IsFailedPrecondition, 9, 400),
+		Entry("Unauthenticated", flaw.Unauthenticatedf, flaw.IsUnauthenticated, 16, 401),
+		Entry("PermissionDenied", flaw.PermissionDeniedf, flaw.IsPermissionDenied, 7, 403),
+		Entry("NotFound", flaw.NotFoundf, flaw.IsNotFound, 5, 404),
+		Entry("Conflict", flaw.Conflictf, flaw.IsConflict, 6, 409),
+		Entry("ResourceExhausted", flaw.ResourceExhaustedf, flaw.IsResourceExhausted, 8, 429),
+		Entry("Internal", flaw.Internalf, flaw.IsInternal, 13, 500),
+		Entry("Unimplemented", flaw.Unimplementedf, flaw.IsUnimplemented, 12, 501),
+		Entry("Unavailable", flaw.Unavailablef, flaw.IsUnavailable, 14, 503),
+		Entry("DeadlineExceeded", flaw.DeadlineExceededf, flaw.IsDeadlineExceeded, 4, 504),
+	)
+
+	It("matches the errors without a code by their status", func() {
+		Expect(flaw.IsNotFound(flaw.Errorf("oh no").WithStatus(404))).To(BeTrue())
+		Expect(flaw.IsInvalidArgument(flaw.Errorf("oh no").WithStatus(400))).To(BeFalse())
+		Expect(flaw.IsInternal(flaw.Errorf("oh no"))).To(BeFalse())
+	})
+
+	It("matches the classified errors", func() {
+		_, err := os.Open("/does/not/exist")
+		Expect(flaw.IsNotFound(flaw.Wrap(err))).To(BeTrue())
+	})
+
+	It("does not match the errors without a flaw error", func() {
+		Expect(flaw.IsNotFound(nil)).To(BeFalse())
+		Expect(flaw.IsNotFound(fmt.Errorf("oh no"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,75 @@
+package flaw
+
+import "errors"
+
+// Chain creates a pre-linked chain of errors, in which the top error wraps
+// causes[0], which wraps causes[1] and so on. It's useful when the errors are
+// reconstructed from decoded transport data or from the failure records of an
+// event store. The flaw errors are copied, so the given errors are not
+// modified, and keep their stack traces. The other errors are linked by a
+// wrapper, which message is the message of the error followed by the message
+// of its cause. The nil causes are skipped. Chain returns nil if top is nil.
+func Chain(top error, causes ...error) *Error {
+	if top == nil {
+		return nil
+	}
+
+	var cause error
+
+	for index := len(causes) - 1; index >= 0; index-- {
+		if causes[index] != nil {
+			cause = link(causes[index], cause)
+		}
+	}
+
+	if errx, ok := top.(*Error); ok {
+		if cause == nil {
+			return errx
+		}
+
+		return link(errx, cause).(*Error)
+	}
+
+	return wrap(link(top, cause)).capture(1)
+}
+
+// link returns the error linked to the given cause
+func link(err, cause error) error {
+	if cause == nil {
+		return err
+	}
+
+	if errx, ok := err.(*Error); ok {
+		copy := *errx
+		copy.reason = cause
+		return &copy
+	}
+
+	return &linked{err: err, cause: cause}
+}
+
+// linked links an error, which cannot wrap other errors, to its cause
+type linked struct {
+	err   error
+	cause error
+}
+
+// Error returns the error message followed by the cause message
+func (x *linked) Error() string {
+	return buildMessage(x.err.Error(), x.cause.Error())
+}
+
+// Unwrap returns the cause
+func (x *linked) Unwrap() error {
+	return x.cause
+}
+
+// Is reports whether the linked error matches the target
+func (x *linked) Is(target error) bool {
+	return errors.Is(x.err, target)
+}
+
+// As finds the first error in the linked error's chain that matches the target
+func (x *linked) As(target interface{}) bool {
+	return errors.As(x.err, target)
+}
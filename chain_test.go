@@ -0,0 +1,61 @@
+package flaw_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chain", func() {
+	It("links the errors", func() {
+		var (
+			top    = flaw.Errorf("cannot charge").WithCode(9)
+			middle = flaw.Errorf("cannot load the card")
+			root   = fmt.Errorf("sql: no rows")
+		)
+
+		errx := flaw.Chain(top, middle, root)
+		Expect(errx.Code()).To(Equal(9))
+		Expect(errx.Error()).To(Equal("code: 9 message: cannot charge cause: message: cannot load the card cause: sql: no rows"))
+		Expect(flaw.Depth(errx)).To(Equal(3))
+		Expect(errors.Is(errx, root)).To(BeTrue())
+
+		By("not modifying the given errors")
+		Expect(top.Cause()).To(BeNil())
+		Expect(middle.Cause()).To(BeNil())
+		Expect(errx.StackTrace()).To(Equal(top.StackTrace()))
+	})
+
+	It("links the errors that cannot wrap", func() {
+		var (
+			middle = fmt.Errorf("cannot open the file")
+			root   = fs.ErrNotExist
+		)
+
+		errx := flaw.Chain(fmt.Errorf("cannot start"), middle, root)
+		Expect(errx.Error()).To(Equal("code: 5 cause: cannot start: cannot open the file: file does not exist"))
+		Expect(errx.StackTrace()).NotTo(BeEmpty())
+		Expect(errors.Is(errx, middle)).To(BeTrue())
+		Expect(errors.Is(errx, fs.ErrNotExist)).To(BeTrue())
+		Expect(flaw.Status(errx)).To(Equal(404))
+	})
+
+	It("skips the nil causes", func() {
+		errx := flaw.Chain(flaw.Errorf("cannot charge"), nil, fmt.Errorf("timeout"), nil)
+		Expect(errx.Error()).To(Equal("message: cannot charge cause: timeout"))
+	})
+
+	It("returns the top error when there are no causes", func() {
+		top := flaw.Errorf("cannot charge")
+		Expect(flaw.Chain(top)).To(BeIdenticalTo(top))
+	})
+
+	It("returns nil for a nil top", func() {
+		Expect(flaw.Chain(nil, fmt.Errorf("timeout"))).To(BeNil())
+	})
+})
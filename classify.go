@@ -0,0 +1,64 @@
+package flaw
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// KeyPath is the context key of the path of a failed filesystem operation
+const KeyPath = "error_path"
+
+// classification is the code and the status of a well known error
+type classification struct {
+	code   grpcCode
+	status int
+}
+
+// errnos classifies the system call errors
+var errnos = map[syscall.Errno]classification{
+	syscall.ENOSPC:       {codeResourceExhausted, http.StatusInsufficientStorage},
+	syscall.EMFILE:       {codeResourceExhausted, http.StatusServiceUnavailable},
+	syscall.ENFILE:       {codeResourceExhausted, http.StatusServiceUnavailable},
+	syscall.ECONNREFUSED: {codeUnavailable, http.StatusServiceUnavailable},
+	syscall.ECONNRESET:   {codeUnavailable, http.StatusServiceUnavailable},
+	syscall.EROFS:        {codeFailedPrecondition, http.StatusInternalServerError},
+}
+
+// classify sets the code and the status of the wrapped filesystem, os and
+// system call errors, and puts the path of a *fs.PathError into the context
+func (x *Error) classify() *Error {
+	var (
+		item  classification
+		errno syscall.Errno
+	)
+
+	switch {
+	case errors.Is(x.reason, fs.ErrNotExist):
+		item = classification{codeNotFound, http.StatusNotFound}
+	case errors.Is(x.reason, fs.ErrPermission):
+		item = classification{codePermissionDenied, http.StatusForbidden}
+	case errors.Is(x.reason, fs.ErrExist):
+		item = classification{codeAlreadyExists, http.StatusConflict}
+	case os.IsTimeout(x.reason), errors.Is(x.reason, os.ErrDeadlineExceeded):
+		item = classification{codeDeadlineExceeded, http.StatusGatewayTimeout}
+	case errors.As(x.reason, &errno):
+		item = errnos[errno]
+	}
+
+	if item.code != codeOK {
+		x.code = int(item.code)
+		x.status = item.status
+	}
+
+	var failure *fs.PathError
+
+	if errors.As(x.reason, &failure) {
+		x.put(KeyPath, failure.Path)
+		x.put(keyOperation, failure.Op)
+	}
+
+	return x
+}
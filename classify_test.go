@@ -0,0 +1,62 @@
+package flaw_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Wrap", func() {
+	Context("when the error is a filesystem error", func() {
+		It("classifies the missing file", func() {
+			path := filepath.Join(os.TempDir(), "flaw-missing-file")
+
+			_, cause := os.Open(path)
+			err := flaw.Wrap(cause)
+
+			Expect(err.Code()).To(Equal(5))
+			Expect(err.Status()).To(Equal(404))
+			Expect(err.Context()).To(HaveKeyWithValue("error_path", path))
+			Expect(err.Context()).To(HaveKeyWithValue("error_operation", "open"))
+		})
+
+		It("classifies the permission error", func() {
+			err := flaw.Wrap(&fs.PathError{Op: "write", Path: "/etc/passwd", Err: fs.ErrPermission})
+			Expect(err.Code()).To(Equal(7))
+			Expect(err.Status()).To(Equal(403))
+		})
+
+		It("classifies the existing file", func() {
+			err := flaw.WrapAll(fmt.Errorf("mkdir: %w", fs.ErrExist))
+			Expect(flaw.Code(err)).To(Equal(6))
+			Expect(flaw.Status(err)).To(Equal(409))
+		})
+
+		It("classifies the timeout", func() {
+			err := flaw.Wrap(&fs.PathError{Op: "read", Path: "/dev/tty", Err: os.ErrDeadlineExceeded})
+			Expect(err.Code()).To(Equal(4))
+			Expect(err.Status()).To(Equal(504))
+		})
+	})
+
+	Context("when the error is a system call error", func() {
+		It("classifies the known errno", func() {
+			err := flaw.Wrap(os.NewSyscallError("write", syscall.ENOSPC))
+			Expect(err.Code()).To(Equal(8))
+			Expect(err.Status()).To(Equal(507))
+		})
+
+		It("does not classify the unknown errno", func() {
+			err := flaw.Wrap(syscall.EINVAL)
+			Expect(err.Code()).To(Equal(0))
+			Expect(err.Status()).To(Equal(500))
+		})
+	})
+})
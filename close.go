@@ -0,0 +1,56 @@
+package flaw
+
+import "io"
+
+const keySecondary = "error_secondary"
+
+// CloseWith closes the closer and annotates the close error with the given
+// message. It is meant to be deferred by functions with a named error result:
+//
+//	defer flaw.CloseWith(&err, response.Body, "closing response body")
+//
+// The close error becomes the result if the result is nil. Otherwise it is
+// attached to the "error_secondary" context key of the result, so the primary
+// error is not clobbered.
+func CloseWith(err *error, closer io.Closer, msg string) {
+	cerr := closer.Close()
+	if cerr == nil {
+		return
+	}
+
+	secondary := &Error{
+		status: 500,
+		msg:    msg,
+		reason: cerr,
+	}
+
+	secondary.capture(1)
+
+	if *err == nil {
+		*err = secondary
+		return
+	}
+
+	errx, ok := (*err).(*Error)
+
+	if ok {
+		clone := *errx
+		errx = &clone
+	} else {
+		errx = wrap(*err)
+		errx.capture(1)
+	}
+
+	context := Map{}
+
+	for key, value := range errx.context {
+		context[key] = value
+	}
+
+	items, _ := context[keySecondary].(ErrorCollector)
+	// the full slice expression prevents sharing the backing array
+	context[keySecondary] = append(items[:len(items):len(items)], secondary)
+
+	errx.context = context
+	*err = errx
+}
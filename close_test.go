@@ -0,0 +1,73 @@
+package flaw_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CloseWith", func() {
+	It("does not change the result when the close succeeds", func() {
+		closer := &Closer{}
+
+		var err error
+		flaw.CloseWith(&err, closer, "closing body")
+
+		Expect(err).To(BeNil())
+		Expect(closer.Closed).To(BeTrue())
+	})
+
+	It("sets the close error as the result", func() {
+		closer := &Closer{Err: fmt.Errorf("broken pipe")}
+
+		var err error
+		flaw.CloseWith(&err, closer, "closing body")
+
+		Expect(err).To(MatchError("message: closing body cause: broken pipe"))
+		Expect(errors.Is(err, closer.Err)).To(BeTrue())
+	})
+
+	It("attaches the close error to the flaw error", func() {
+		closer := &Closer{Err: fmt.Errorf("broken pipe")}
+		primary := flaw.Errorf("not found").WithCode(5)
+
+		var err error = primary
+		flaw.CloseWith(&err, closer, "closing body")
+		flaw.CloseWith(&err, closer, "closing file")
+
+		Expect(flaw.Code(err)).To(Equal(5))
+		Expect(flaw.Message(err)).To(Equal("not found"))
+		Expect(flaw.Context(primary)).NotTo(HaveKey("error_secondary"))
+
+		secondary, ok := flaw.Context(err)["error_secondary"].(flaw.ErrorCollector)
+		Expect(ok).To(BeTrue())
+		Expect(secondary).To(HaveLen(2))
+		Expect(secondary[0]).To(MatchError("message: closing body cause: broken pipe"))
+		Expect(secondary[1]).To(MatchError("message: closing file cause: broken pipe"))
+	})
+
+	It("wraps the primary error", func() {
+		closer := &Closer{Err: fmt.Errorf("broken pipe")}
+		primary := fmt.Errorf("oh no")
+
+		var err error = primary
+		flaw.CloseWith(&err, closer, "closing body")
+
+		Expect(errors.Is(err, primary)).To(BeTrue())
+		Expect(flaw.Context(err)).To(HaveKeyWithValue("error_secondary", HaveLen(1)))
+	})
+})
+
+type Closer struct {
+	Err    error
+	Closed bool
+}
+
+func (c *Closer) Close() error {
+	c.Closed = true
+	return c.Err
+}
@@ -0,0 +1,45 @@
+// Command flawdiff compares two error catalogs and reports the added, the
+// removed and the changed errors. It exits with status 1 if any of the changes
+// is breaking, so it can guard the error contract in a release pipeline.
+//
+// Usage:
+//
+//	flawdiff old/errors.yaml new/errors.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/phogolabs/flaw/catalog"
+	"github.com/phogolabs/flaw/flawdiff"
+)
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: flawdiff <old catalog> <new catalog>")
+		os.Exit(2)
+	}
+
+	prev, err := catalog.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "flawdiff:", err)
+		os.Exit(2)
+	}
+
+	next, err := catalog.Open(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "flawdiff:", err)
+		os.Exit(2)
+	}
+
+	report := flawdiff.Compare(prev, next)
+	report.WriteTo(os.Stdout)
+
+	if len(report.Breaking()) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/phogolabs/flaw/catalog"
+)
+
+var source = template.Must(template.New("catalog").Funcs(template.FuncMap{
+	"quote":   strconv.Quote,
+	"comment": comment,
+}).Parse(`// Code generated by flawgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import "github.com/phogolabs/flaw"
+
+const (
+{{- range .Errors }}
+	// Err{{ .Name }} is the {{ .Name }} error constant
+	Err{{ .Name }} = flaw.ErrorConstant({{ quote .Message }})
+{{- end }}
+)
+{{ range .Errors }}
+// {{ .Name }} creates a new {{ .Name }} error.
+{{- if .Description }}
+//
+{{ comment .Description }}
+{{- end }}
+{{- if .DocsURL }}
+//
+// See {{ .DocsURL }}
+{{- end }}
+func {{ .Name }}(args ...interface{}) *flaw.Error {
+	return Err{{ .Name }}.WithSkip(1, args...)
+	{{- if .Code }}.WithCode({{ .Code }}){{ end }}
+	{{- if .Status }}.WithStatus({{ .Status }}){{ end }}
+	{{- if .DocsURL }}.WithContext(flaw.Map{"error_docs_url": {{ quote .DocsURL }}}){{ end }}
+}
+{{ end }}`))
+
+// comment returns the text as a comment, which lines are prefixed with //
+func comment(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	for index, line := range lines {
+		lines[index] = strings.TrimRight("// "+line, " \t")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Generate generates the error constants and constructors of the catalog
+func Generate(writer io.Writer, catalog *catalog.Catalog) error {
+	buffer := &bytes.Buffer{}
+
+	if err := source.Execute(buffer, catalog); err != nil {
+		return err
+	}
+
+	data, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
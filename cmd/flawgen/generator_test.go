@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/phogolabs/flaw/catalog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	It("generates the constants and constructors", func() {
+		buffer := &bytes.Buffer{}
+
+		errs := &catalog.Catalog{
+			Package: "billing",
+			Errors: []catalog.Entry{
+				{
+					Name:        "UserNotFound",
+					Description: "UserNotFound is returned when the user does not exist.",
+					Code:        5,
+					Status:      404,
+					Message:     "user %q not found",
+					DocsURL:     "https://example.com/errors/user-not-found",
+				},
+				{
+					Name:    "Internal",
+					Message: "internal error",
+				},
+			},
+		}
+
+		Expect(Generate(buffer, errs)).To(Succeed())
+
+		source := buffer.String()
+		Expect(source).To(HavePrefix("// Code generated by flawgen. DO NOT EDIT.\n\npackage billing\n"))
+		Expect(source).To(ContainSubstring(`ErrUserNotFound = flaw.ErrorConstant("user %q not found")`))
+		Expect(source).To(ContainSubstring(`ErrInternal = flaw.ErrorConstant("internal error")`))
+		Expect(source).To(ContainSubstring("// See https://example.com/errors/user-not-found\n"))
+		Expect(source).To(ContainSubstring(`return ErrUserNotFound.WithSkip(1, args...).WithCode(5).WithStatus(404).WithContext(flaw.Map{"error_docs_url": "https://example.com/errors/user-not-found"})`))
+		Expect(source).To(ContainSubstring("return ErrInternal.WithSkip(1, args...)\n"))
+	})
+
+	It("generates the multi-line descriptions as comments", func() {
+		buffer := &bytes.Buffer{}
+
+		errs := &catalog.Catalog{
+			Package: "billing",
+			Errors: []catalog.Entry{
+				{
+					Name:        "UserNotFound",
+					Description: "UserNotFound is returned when the user does not exist.\n\nThe user might be deleted.\n",
+					Message:     "user %q not found",
+				},
+			},
+		}
+
+		Expect(Generate(buffer, errs)).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring("// UserNotFound is returned when the user does not exist.\n//\n// The user might be deleted.\nfunc UserNotFound("))
+	})
+})
@@ -0,0 +1,60 @@
+// Command flawgen generates typed error constructors and constants from a
+// YAML or JSON catalog of error definitions.
+//
+// Usage:
+//
+//	flawgen -catalog errors.yaml -output errors_gen.go -package billing
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/phogolabs/flaw/catalog"
+)
+
+func main() {
+	var (
+		path   = flag.String("catalog", "errors.yaml", "path to the error catalog")
+		output = flag.String("output", "errors_gen.go", "path to the generated file")
+		pkg    = flag.String("package", "", "name of the generated package")
+	)
+
+	flag.Parse()
+
+	if err := run(*path, *output, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "flawgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, output, pkg string) error {
+	errs, err := catalog.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if pkg != "" {
+		errs.Package = pkg
+	}
+
+	if errs.Package == "" {
+		dir, err := filepath.Abs(filepath.Dir(output))
+		if err != nil {
+			return err
+		}
+
+		errs.Package = filepath.Base(dir)
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if err := Generate(buffer, errs); err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, buffer.Bytes(), 0o644)
+}
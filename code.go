@@ -0,0 +1,56 @@
+package flaw
+
+// grpcCode is a gRPC code. The codes are declared by the package, so the core
+// does not depend on grpc when it's built with the flaw_nogrpc tag.
+type grpcCode int
+
+// the gRPC codes as declared by google.golang.org/grpc/codes
+const (
+	codeOK grpcCode = iota
+	codeCanceled
+	codeUnknown
+	codeInvalidArgument
+	codeDeadlineExceeded
+	codeNotFound
+	codeAlreadyExists
+	codePermissionDenied
+	codeResourceExhausted
+	codeFailedPrecondition
+	codeAborted
+	codeOutOfRange
+	codeUnimplemented
+	codeInternal
+	codeUnavailable
+	codeDataLoss
+	codeUnauthenticated
+)
+
+var codeNames = [...]string{
+	codeOK:                 "OK",
+	codeCanceled:           "Canceled",
+	codeUnknown:            "Unknown",
+	codeInvalidArgument:    "InvalidArgument",
+	codeDeadlineExceeded:   "DeadlineExceeded",
+	codeNotFound:           "NotFound",
+	codeAlreadyExists:      "AlreadyExists",
+	codePermissionDenied:   "PermissionDenied",
+	codeResourceExhausted:  "ResourceExhausted",
+	codeFailedPrecondition: "FailedPrecondition",
+	codeAborted:            "Aborted",
+	codeOutOfRange:         "OutOfRange",
+	codeUnimplemented:      "Unimplemented",
+	codeInternal:           "Internal",
+	codeUnavailable:        "Unavailable",
+	codeDataLoss:           "DataLoss",
+	codeUnauthenticated:    "Unauthenticated",
+}
+
+// codeName returns the name of the gRPC code, e.g. "NotFound". The OK code
+// and the codes out of the gRPC range do not have a name.
+func codeName(code int) (string, bool) {
+	if code > int(codeOK) && code <= int(codeUnauthenticated) {
+		return codeNames[code], true
+	}
+
+	return "", false
+}
@@ -0,0 +1,40 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetCodeNames", func() {
+	BeforeEach(func() {
+		flaw.SetCodeNames(true)
+	})
+
+	AfterEach(func() {
+		flaw.SetCodeNames(false)
+	})
+
+	It("prints the name of the grpc code", func() {
+		errx := flaw.Errorf("user not found").WithCode(5)
+		Expect(errx.Error()).To(Equal("code: NotFound (5) message: user not found"))
+		Expect(fmt.Sprintf("%c", errx)).To(Equal("5"))
+	})
+
+	It("prints the codes out of the grpc range as numbers", func() {
+		errx := flaw.Errorf("user not found").WithCode(404)
+		Expect(errx.Error()).To(Equal("code: 404 message: user not found"))
+	})
+
+	Context("when the names are disabled", func() {
+		It("prints the code as number", func() {
+			flaw.SetCodeNames(false)
+
+			errx := flaw.Errorf("user not found").WithCode(5)
+			Expect(errx.Error()).To(Equal("code: 5 message: user not found"))
+		})
+	})
+})
@@ -0,0 +1,73 @@
+package flaw
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// joined is the type of the errors joined by errors.Join
+var joined = reflect.TypeOf(errors.Join(io.EOF))
+
+// Collect returns a collector of the given errors. The nil errors are dropped,
+// and the nested collectors and the errors joined by errors.Join are
+// flattened, so the collector contains the leaf errors only. The other errors
+// that wrap many errors, e.g. the ones created by fmt.Errorf with many %w
+// verbs, are kept as they are, so their message is not lost. Collect returns
+// nil if there are no errors.
+func Collect(errs ...error) ErrorCollector {
+	var items ErrorCollector
+
+	for _, err := range errs {
+		items = collect(items, err)
+	}
+
+	return items
+}
+
+// Join is a drop-in replacement of errors.Join. The nil errors are dropped and
+// the nested collectors and joined errors are flattened as by Collect. The
+// errors that are not flaw errors are wrapped with the stack trace of the
+// join point. Join returns nil if there are no errors.
+func Join(errs ...error) error {
+	items := Collect(errs...)
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	for index, err := range items {
+		if _, ok := err.(*Error); !ok {
+			items[index] = wrap(err).capture(1)
+		}
+	}
+
+	return items
+}
+
+func collect(items ErrorCollector, err error) ErrorCollector {
+	type Joiner interface {
+		Unwrap() []error
+	}
+
+	switch errx := err.(type) {
+	case nil:
+		return items
+	case ErrorCollector:
+		for _, child := range errx {
+			items = collect(items, child)
+		}
+	case Joiner:
+		if reflect.TypeOf(err) != joined {
+			return append(items, err)
+		}
+
+		for _, child := range errx.Unwrap() {
+			items = collect(items, child)
+		}
+	default:
+		items = append(items, err)
+	}
+
+	return items
+}
@@ -0,0 +1,93 @@
+package flaw_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Collect", func() {
+	var (
+		first  = fmt.Errorf("first")
+		second = flaw.Errorf("second")
+		third  = fmt.Errorf("third")
+	)
+
+	It("collects the errors", func() {
+		errs := flaw.Collect(first, second)
+		Expect(errs).To(Equal(flaw.ErrorCollector{first, second}))
+	})
+
+	It("drops the nil errors", func() {
+		errs := flaw.Collect(nil, first, nil, second)
+		Expect(errs).To(Equal(flaw.ErrorCollector{first, second}))
+	})
+
+	It("flattens the nested collectors", func() {
+		errs := flaw.Collect(first, flaw.ErrorCollector{second, flaw.ErrorCollector{nil, third}})
+		Expect(errs).To(Equal(flaw.ErrorCollector{first, second, third}))
+		Expect(errs.Error()).To(Equal("[first, message: second, third]"))
+	})
+
+	It("flattens the joined errors", func() {
+		errs := flaw.Collect(errors.Join(first, errors.Join(second, third)))
+		Expect(errs).To(Equal(flaw.ErrorCollector{first, second, third}))
+	})
+
+	It("keeps the errors wrapped by fmt.Errorf with many verbs", func() {
+		err := fmt.Errorf("query failed: %w, %w", first, third)
+
+		errs := flaw.Collect(err, second)
+		Expect(errs).To(Equal(flaw.ErrorCollector{err, second}))
+		Expect(errs[0]).To(MatchError("query failed: first, third"))
+	})
+
+	It("keeps the wrapped errors", func() {
+		err := flaw.Wrap(errors.Join(first, second))
+		Expect(flaw.Collect(err)).To(Equal(flaw.ErrorCollector{err}))
+	})
+
+	Context("when there are no errors", func() {
+		It("returns nil", func() {
+			Expect(flaw.Collect()).To(BeNil())
+			Expect(flaw.Collect(nil, flaw.ErrorCollector{}, errors.Join(nil))).To(BeNil())
+		})
+	})
+})
+
+var _ = Describe("Join", func() {
+	var (
+		first  = fmt.Errorf("first")
+		second = flaw.Errorf("second")
+	)
+
+	It("returns nil if there are no errors", func() {
+		Expect(flaw.Join()).To(BeNil())
+		Expect(flaw.Join(nil, nil)).To(BeNil())
+	})
+
+	It("joins the errors", func() {
+		err := flaw.Join(first, nil, errors.Join(second, nil))
+		Expect(err).To(HaveLen(2))
+		Expect(errors.Is(err, first)).To(BeTrue())
+		Expect(errors.Is(err, second)).To(BeTrue())
+	})
+
+	It("keeps the flaw errors as they are", func() {
+		errs := flaw.Join(first, second).(flaw.ErrorCollector)
+		Expect(errs[1]).To(BeIdenticalTo(second))
+	})
+
+	It("captures the stack trace at the join point", func() {
+		errs := flaw.Join(first, second).(flaw.ErrorCollector)
+
+		errx, ok := errs[0].(*flaw.Error)
+		Expect(ok).To(BeTrue())
+		Expect(errx.Unwrap()).To(Equal(first))
+		Expect(errx.StackTrace()[0].File).To(HaveSuffix("collect_test.go"))
+	})
+})
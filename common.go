@@ -1,14 +1,25 @@
 package flaw
 
 import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
-	"go/build"
+	"fmt"
+	"hash/fnv"
+	"io"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
 func relative(path string) string {
-	if root := build.Default.GOPATH; root != "" {
+	if root := gopath(); root != "" {
 		if strings.HasPrefix(path, root) {
 			if file, err := filepath.Rel(root, path); err == nil {
 				const (
@@ -41,27 +52,377 @@ func function(name string) string {
 	return name
 }
 
+func formatJSON(state fmt.State, value interface{}) {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch {
+	case state.Flag('+'):
+		data, err = json.MarshalIndent(value, "", "  ")
+	default:
+		data, err = json.Marshal(value)
+	}
+
+	if err != nil {
+		fmt.Fprintf(state, "%%!j(%v)", err)
+		return
+	}
+
+	stats.bytes.Add(uint64(len(data)))
+	state.Write(data)
+}
+
+// MaxArgSize is the maximum size in bytes of an argument snapshot
+const MaxArgSize = 256
+
+// truncate truncates the text to the given size without splitting a rune
+func truncate(text string, size int) string {
+	const ellipsis = "..."
+
+	if len(text) <= size {
+		return text
+	}
+
+	end := size - len(ellipsis)
+
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return text[:end] + ellipsis
+}
+
+// Truncator shortens the long messages and causes, e.g. the SQL statements
+// embedded in the causes
+type Truncator func(text string) string
+
+type truncator struct {
+	handler Truncator
+}
+
+var truncators atomic.Value
+
+// SetTruncator sets the truncator of the messages and the causes. It's applied
+// by Error, Format, the gRPC status and the serializers. The texts are not
+// truncated by default. Pass nil to disable the truncation.
+func SetTruncator(handler Truncator) {
+	truncators.Store(truncator{handler: handler})
+}
+
+// Ellipsis returns a truncator that shortens the texts longer than the given
+// number of runes by replacing their middle with an ellipsis, so both the
+// beginning and the end of the text are preserved.
+func Ellipsis(size int) Truncator {
+	const ellipsis = "…"
+
+	return func(text string) string {
+		if utf8.RuneCountInString(text) <= size {
+			return text
+		}
+
+		if size < 1 {
+			return ellipsis
+		}
+
+		runes := []rune(text)
+		// the ellipsis takes one of the runes
+		head := size / 2
+		tail := size - 1 - head
+
+		return string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+	}
+}
+
+// clip truncates the text by the truncator if it's set
+func clip(text string) string {
+	if item, _ := truncators.Load().(truncator); item.handler != nil {
+		return item.handler(text)
+	}
+
+	return text
+}
+
+// overhead is the approximate number of bytes that the serialization adds to
+// every field (quotes, separators, keys and etc.)
+const overhead = 8
+
+func approxSize(value interface{}) int {
+	type Sizer interface {
+		ApproxSize() int
+	}
+
+	switch item := value.(type) {
+	case nil:
+		return 4
+	case Sizer:
+		return item.ApproxSize()
+	case string:
+		return len(item)
+	case json.RawMessage:
+		return len(item)
+	case []byte:
+		return base64.StdEncoding.EncodedLen(len(item))
+	case error:
+		return len(item.Error())
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return overhead
+	default:
+		return len(fmt.Sprint(item))
+	}
+}
+
+// fingerprint returns a short hex encoded hash of the given fields
+func fingerprint(fields ...string) string {
+	hash := fnv.New64a()
+
+	for _, field := range fields {
+		io.WriteString(hash, field)
+		// the separator prevents collisions between the adjacent fields
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+var nested atomic.Bool
+
+// SetNestedKeys enables or disables the grouping of the dotted context keys.
+// When enabled the keys "db.query" and "db.duration_ms" are serialized as a
+// nested "db" object in JSON and as a nested "Db" element in XML. The grouping
+// is disabled by default.
+func SetNestedKeys(enabled bool) {
+	nested.Store(enabled)
+}
+
+var names atomic.Bool
+
+// SetCodeNames enables or disables the names of the gRPC codes in the text
+// format, e.g. "code: NotFound (5)" instead of "code: 5". The codes out of the
+// gRPC range are printed as numbers. The names are disabled by default.
+func SetCodeNames(enabled bool) {
+	names.Store(enabled)
+}
+
+var textCodes atomic.Bool
+
+// SetTextCodes enables or disables the serialization of the codes as JSON
+// strings, e.g. "error_code": "5", for the JavaScript clients that lose the
+// precision of the large numbers. The codes are serialized as numbers by
+// default. The decoder accepts both.
+func SetTextCodes(enabled bool) {
+	textCodes.Store(enabled)
+}
+
+var (
+	layouts   atomic.Value
+	durations atomic.Bool
+)
+
+// SetTimeLayout sets the layout of the time values in the serialized context.
+// The layout is time.RFC3339Nano by default.
+func SetTimeLayout(layout string) {
+	layouts.Store(layout)
+}
+
+// SetNumericDurations enables or disables the serialization of the durations
+// in the context as nanoseconds. The durations are serialized as text, e.g.
+// "1.5s", by default.
+func SetNumericDurations(enabled bool) {
+	durations.Store(enabled)
+}
+
+// render returns the value formatted in the same way by all encoders
+func render(value interface{}) interface{} {
+	switch item := value.(type) {
+	case time.Duration:
+		if durations.Load() {
+			return int64(item)
+		}
+
+		return item.String()
+	case time.Time:
+		layout, _ := layouts.Load().(string)
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+
+		return item.Format(layout)
+	case map[string]interface{}:
+		return map[string]interface{}(dictionary(item).render())
+	default:
+		return value
+	}
+}
+
 type dictionary map[string]interface{}
 
-// MarshalXML marshals the dictionary
+// render returns a copy of the dictionary with the durations and the times
+// formatted
+func (x dictionary) render() dictionary {
+	m := make(dictionary, len(x))
+
+	for key, value := range x {
+		m[key] = render(value)
+	}
+
+	return m
+}
+
+// nest groups the dotted keys into nested dictionaries if the grouping is
+// enabled. A key that conflicts with an existing value is kept flat.
+func (x dictionary) nest() dictionary {
+	if !nested.Load() {
+		return x
+	}
+
+	keys := make([]string, 0, len(x))
+
+	for key := range x {
+		keys = append(keys, key)
+	}
+
+	// the shorter keys come first, so the conflicts are resolved in the same
+	// way every time
+	sort.Strings(keys)
+
+	m := dictionary{}
+
+	for _, key := range keys {
+		if !m.insert(strings.Split(key, "."), x[key]) {
+			m[key] = x[key]
+		}
+	}
+
+	return m
+}
+
+func (x dictionary) insert(path []string, value interface{}) bool {
+	name := path[0]
+
+	if name == "" {
+		return false
+	}
+
+	if len(path) == 1 {
+		if _, ok := x[name]; ok {
+			return false
+		}
+
+		x[name] = value
+		return true
+	}
+
+	child, ok := x[name].(dictionary)
+
+	if !ok {
+		if _, ok := x[name]; ok {
+			return false
+		}
+
+		child = dictionary{}
+	}
+
+	if !child.insert(path[1:], value) {
+		return false
+	}
+
+	x[name] = child
+	return true
+}
+
+// MarshalXML marshals the dictionary. The elements are ordered by their keys,
+// so the output is stable as the output of encoding/json.
 func (x dictionary) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
 	err := encoder.EncodeToken(start)
 	if err != nil {
 		return err
 	}
 
-	type entry struct {
-		XMLName xml.Name
-		Value   interface{} `xml:",chardata"`
+	keys := make([]string, 0, len(x))
+
+	for key := range x {
+		keys = append(keys, key)
 	}
 
-	for key, value := range x {
-		encoder.Encode(entry{XMLName: xml.Name{Local: x.pascal(key)}, Value: value})
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		element := xml.StartElement{Name: xml.Name{Local: x.name(key)}}
+
+		if err := x.encode(encoder, element, x[key]); err != nil {
+			return err
+		}
 	}
 
 	return encoder.EncodeToken(start.End())
 }
 
+func (x dictionary) encode(encoder *xml.Encoder, start xml.StartElement, value interface{}) error {
+	switch item := value.(type) {
+	case nil:
+		return encoder.EncodeElement("", start)
+	case json.RawMessage:
+		// the pre-encoded json is passed through as text
+		return encoder.EncodeElement(string(item), start)
+	case xml.Marshaler, encoding.TextMarshaler, []byte:
+		return encoder.EncodeElement(item, start)
+	case map[string]interface{}:
+		return dictionary(item).MarshalXML(encoder, start)
+	}
+
+	switch kind := reflect.ValueOf(value); kind.Kind() {
+	case reflect.Slice, reflect.Array:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+
+		element := xml.StartElement{Name: xml.Name{Local: "Item"}}
+
+		for index := 0; index < kind.Len(); index++ {
+			if err := x.encode(encoder, element, kind.Index(index).Interface()); err != nil {
+				return err
+			}
+		}
+
+		return encoder.EncodeToken(start.End())
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return encoder.EncodeElement(value, start)
+	default:
+		return encoder.EncodeElement(fmt.Sprint(value), start)
+	}
+}
+
+// name returns a valid xml element name for the given key
+func (x dictionary) name(key string) string {
+	runes := []rune(x.pascal(key))
+
+	for index, char := range runes {
+		switch {
+		case char == '_' || x.letter(char):
+		case index > 0 && (char == '-' || char == '.' || (char >= '0' && char <= '9')):
+		default:
+			runes[index] = '_'
+		}
+	}
+
+	if len(runes) == 0 {
+		runes = append([]rune{'_'}, runes...)
+	}
+
+	return string(runes)
+}
+
+// letter reports whether the char is an ascii letter, which is a valid name
+// character in every version of the xml specification
+func (x dictionary) letter(char rune) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
 func (x dictionary) pascal(text string) string {
 	parts := strings.Split(text, "_")
 
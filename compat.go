@@ -0,0 +1,101 @@
+package flaw
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// foreignStack returns the stack trace of the deepest error in the chain that
+// has been created by github.com/pkg/errors or github.com/cockroachdb/errors,
+// so a wrapped error keeps the stack trace of its origin. The packages are
+// not imported. Their errors are recognized by a StackTrace method that
+// returns a slice of program counters. The chain below a flaw error is not
+// searched, since the flaw error has a stack trace already.
+func foreignStack(err error) StackTrace {
+	var pcs []uintptr
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if _, ok := err.(*Error); ok {
+			break
+		}
+
+		if items := programCounters(err); len(items) > 0 {
+			pcs = items
+		}
+	}
+
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var (
+		frames = runtime.CallersFrames(pcs)
+		stack  = StackTrace{}
+	)
+
+	for {
+		frame, ok := frames.Next()
+		if !ok {
+			return stack
+		}
+
+		stack = append(stack, StackFrame(frame))
+	}
+}
+
+// programCounters returns the program counters of the error's StackTrace
+// method, e.g. errors.StackTrace of github.com/pkg/errors, which frames are
+// the program counters returned by runtime.Callers
+func programCounters(err error) []uintptr {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+
+	if !method.IsValid() {
+		return nil
+	}
+
+	kind := method.Type()
+
+	if kind.NumIn() != 0 || kind.NumOut() != 1 {
+		return nil
+	}
+
+	if out := kind.Out(0); out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	value := method.Call(nil)[0]
+	pcs := make([]uintptr, value.Len())
+
+	for index := range pcs {
+		pcs[index] = uintptr(value.Index(index).Uint())
+	}
+
+	return pcs
+}
+
+// verboseTrace returns the text that the deepest third-party error in the
+// chain adds to its message when it's formatted with %+v, which is usually
+// its stack trace. The chain below a flaw error is not searched.
+func verboseTrace(err error) string {
+	trace := ""
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch err.(type) {
+		case *Error, ErrorCollector:
+			return trace
+		case fmt.Formatter:
+			text := fmt.Sprintf("%+v", err)
+
+			if rest := strings.TrimPrefix(text, err.Error()); rest != text {
+				if rest = strings.Trim(rest, "\n"); rest != "" {
+					trace = rest
+				}
+			}
+		}
+	}
+
+	return trace
+}
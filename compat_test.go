@@ -0,0 +1,123 @@
+package flaw_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// pkgFrame mimics errors.Frame of github.com/pkg/errors
+type pkgFrame uintptr
+
+// pkgStackTrace mimics errors.StackTrace of github.com/pkg/errors
+type pkgStackTrace []pkgFrame
+
+// pkgError mimics an error of github.com/pkg/errors
+type pkgError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newPkgError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &pkgError{msg: msg, stack: pcs[:n]}
+}
+
+func (x *pkgError) Error() string {
+	return x.msg
+}
+
+func (x *pkgError) StackTrace() pkgStackTrace {
+	frames := make(pkgStackTrace, len(x.stack))
+
+	for index, pc := range x.stack {
+		frames[index] = pkgFrame(pc)
+	}
+
+	return frames
+}
+
+func originOfPkgError() error {
+	return newPkgError("oh no")
+}
+
+// verboseError mimics an error that prints its stack trace with %+v
+type verboseError struct{}
+
+func (x *verboseError) Error() string {
+	return "oh no"
+}
+
+func (x *verboseError) Format(state fmt.State, verb rune) {
+	fmt.Fprint(state, x.Error())
+
+	if state.Flag('+') {
+		fmt.Fprint(state, "\nmain.main\n\t/app/main.go:42")
+	}
+}
+
+var _ = Describe("Compatibility", func() {
+	function := func(stack flaw.StackTrace) string {
+		Expect(stack).NotTo(BeEmpty())
+		return stack[0].Function
+	}
+
+	It("uses the stack trace of a pkg/errors error", func() {
+		errx := flaw.Wrap(originOfPkgError())
+		Expect(function(errx.StackTrace())).To(HaveSuffix("originOfPkgError"))
+	})
+
+	It("uses the stack trace of the deepest pkg/errors error", func() {
+		err := fmt.Errorf("wrapped: %w", originOfPkgError())
+		errx := flaw.Errorf("failed").WithError(err)
+		Expect(function(errx.StackTrace())).To(HaveSuffix("originOfPkgError"))
+	})
+
+	It("does not search below a flaw error", func() {
+		err := flaw.Wrap(originOfPkgError())
+		errx := flaw.Wrap(fmt.Errorf("wrapped: %w", err))
+		Expect(function(errx.StackTrace())).NotTo(HaveSuffix("originOfPkgError"))
+	})
+
+	It("captures the stack trace of the other errors", func() {
+		errx := flaw.Wrap(fmt.Errorf("oh no"))
+		Expect(strings.Contains(function(errx.StackTrace()), "flaw_test")).To(BeTrue())
+	})
+
+	Describe("Format", func() {
+		AfterEach(func() {
+			flaw.SetStackSampling(1)
+		})
+
+		It("prints the stack trace of a third-party cause", func() {
+			flaw.SetStackSampling(0)
+
+			text := fmt.Sprintf("%+v", flaw.Wrap(originOfPkgError()))
+			Expect(text).To(ContainSubstring("cause stack:"))
+			Expect(text).To(ContainSubstring("originOfPkgError"))
+		})
+
+		It("does not print the stack trace that the error has already", func() {
+			text := fmt.Sprintf("%+v", flaw.Wrap(originOfPkgError()))
+			Expect(text).NotTo(ContainSubstring("cause stack:"))
+			Expect(strings.Count(text, "originOfPkgError")).To(Equal(1))
+		})
+
+		It("prints the verbose output of a third-party cause", func() {
+			text := fmt.Sprintf("%+v", flaw.Errorf("failed").WithError(fmt.Errorf("wrapped: %w", &verboseError{})))
+			Expect(text).To(ContainSubstring("cause stack:"))
+			Expect(text).To(ContainSubstring("/app/main.go:42"))
+		})
+
+		It("does not print the verbose output in the default format", func() {
+			text := fmt.Sprintf("%v", flaw.Errorf("failed").WithError(&verboseError{}))
+			Expect(text).NotTo(ContainSubstring("/app/main.go:42"))
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package flaw
+
+import "errors"
+
+const keyComponent = "error_component"
+
+// WithComponent creates an error copy with given component that the error has
+// originated from, e.g. "payment-service/worker". The component is propagated
+// by the serializers and the gRPC status, so the service that has received
+// the error knows where it was born.
+func (x Error) WithComponent(name string) *Error {
+	x.component = name
+	return &x
+}
+
+// Component returns the component that the error has originated from
+func (x *Error) Component() string {
+	return x.component
+}
+
+// Component returns the component that the error has originated from. It's
+// the component of the deepest error in the chain that has one, so the
+// wrappers added by the other components do not hide it.
+func Component(err error) string {
+	type Componenter interface {
+		Component() string
+	}
+
+	component := ""
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if item, ok := err.(Componenter); ok && item.Component() != "" {
+			component = item.Component()
+		}
+	}
+
+	return component
+}
@@ -0,0 +1,44 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithComponent", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("card declined").WithComponent("payment-service/worker")
+	})
+
+	It("returns the component of the origin", func() {
+		wrapper := flaw.Errorf("cannot checkout").WithComponent("checkout-service").WithError(fmt.Errorf("charge: %w", errx))
+
+		Expect(wrapper.Component()).To(Equal("checkout-service"))
+		Expect(flaw.Component(wrapper)).To(Equal("payment-service/worker"))
+		Expect(flaw.Component(fmt.Errorf("oh no"))).To(BeEmpty())
+	})
+
+	It("marshals the component", func() {
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_component":"payment-service/worker"`))
+
+		decoded := &flaw.Error{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded.Component()).To(Equal("payment-service/worker"))
+
+		data, err = errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded = &flaw.Error{}
+		Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+		Expect(decoded.Component()).To(Equal("payment-service/worker"))
+	})
+})
@@ -0,0 +1,84 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sync"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Concurrency", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("failed").
+			WithCode(404).
+			WithDetails("some more details").
+			WithContext(flaw.Map{"user": "root"}).
+			WithError(fmt.Errorf("oh no")).
+			Freeze()
+	})
+
+	It("reads the error from multiple goroutines", func() {
+		group := sync.WaitGroup{}
+
+		for index := 0; index < 8; index++ {
+			group.Add(1)
+
+			go func() {
+				defer GinkgoRecover()
+				defer group.Done()
+
+				Expect(errx.Error()).To(Equal("code: 404 message: failed details: [some more details] cause: oh no"))
+				Expect(fmt.Sprintf("%+v", errx)).NotTo(BeEmpty())
+				Expect(errx.Context()).To(HaveKeyWithValue("user", "root"))
+
+				_, err := json.Marshal(errx)
+				Expect(err).To(BeNil())
+
+				_, err = xml.Marshal(errx)
+				Expect(err).To(BeNil())
+			}()
+		}
+
+		group.Wait()
+	})
+
+	It("copies the error from multiple goroutines", func() {
+		group := sync.WaitGroup{}
+		errs := make([]*flaw.Error, 8)
+
+		for index := range errs {
+			group.Add(1)
+
+			go func(index int) {
+				defer group.Done()
+				errs[index] = errx.WithDetails(fmt.Sprintf("detail %d", index))
+			}(index)
+		}
+
+		group.Wait()
+
+		for index, err := range errs {
+			Expect(err.Details()).To(Equal([]string{"some more details", fmt.Sprintf("detail %d", index)}))
+		}
+
+		Expect(errx.Details()).To(Equal([]string{"some more details"}))
+	})
+
+	Describe("Freeze", func() {
+		It("freezes the error", func() {
+			Expect(errx.Frozen()).To(BeTrue())
+			Expect(flaw.Errorf("oh no").Frozen()).To(BeFalse())
+		})
+
+		It("panics when the error is wrapped", func() {
+			Expect(func() { errx.Wrap(fmt.Errorf("oh no")) }).To(PanicWith("flaw: wrap of frozen error"))
+		})
+	})
+})
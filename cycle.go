@@ -0,0 +1,127 @@
+package flaw
+
+import (
+	"errors"
+	"reflect"
+)
+
+// cycleMarker replaces the values that reference themselves, so that the
+// formatting and the encoding do not recurse forever
+const cycleMarker = "…(cycle)"
+
+// node identifies a map, a slice or a pointer in the error graph
+type node struct {
+	kind    reflect.Kind
+	pointer uintptr
+}
+
+// tracer finds the cycles in the error graph. The path contains the nodes
+// that are being visited.
+type tracer struct {
+	path map[node]bool
+}
+
+// loops reports whether the value references the error or itself
+func (x *Error) loops(value interface{}) bool {
+	switch value.(type) {
+	case nil, string, bool, int, int64, float64:
+		// the common values are checked without allocations
+		return false
+	}
+
+	t := &tracer{path: map[node]bool{}}
+	t.path[identify(reflect.ValueOf(x))] = true
+
+	return t.cyclic(value)
+}
+
+func (t *tracer) cyclic(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	item := reflect.ValueOf(value)
+
+	switch item.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr:
+		if item.IsNil() {
+			return false
+		}
+	default:
+		return false
+	}
+
+	key := identify(item)
+
+	if t.path[key] {
+		return true
+	}
+
+	t.path[key] = true
+	defer delete(t.path, key)
+
+	switch errx := value.(type) {
+	case *Error:
+		if t.cyclic(errx.reason) {
+			return true
+		}
+
+		for _, value := range errx.context {
+			if t.cyclic(value) {
+				return true
+			}
+		}
+
+		return false
+	case error:
+		if item.Kind() != reflect.Slice {
+			return t.cyclic(errors.Unwrap(errx))
+		}
+	}
+
+	switch item.Kind() {
+	case reflect.Map:
+		if !traversable(item.Type().Elem()) {
+			return false
+		}
+
+		iterator := item.MapRange()
+
+		for iterator.Next() {
+			if t.cyclic(iterator.Value().Interface()) {
+				return true
+			}
+		}
+	case reflect.Slice:
+		if !traversable(item.Type().Elem()) {
+			return false
+		}
+
+		for index := 0; index < item.Len(); index++ {
+			if t.cyclic(item.Index(index).Interface()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// identify returns the node of the value
+func identify(value reflect.Value) node {
+	return node{
+		kind:    value.Kind(),
+		pointer: value.Pointer(),
+	}
+}
+
+// traversable reports whether the values of given type might reference the
+// error graph
+func traversable(kind reflect.Type) bool {
+	switch kind.Kind() {
+	case reflect.Interface, reflect.Map, reflect.Slice, reflect.Ptr:
+		return true
+	default:
+		return false
+	}
+}
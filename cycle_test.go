@@ -0,0 +1,100 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cycle", func() {
+	var warnings []string
+
+	BeforeEach(func() {
+		warnings = nil
+
+		flaw.SetWarningHandler(func(msg string) {
+			warnings = append(warnings, msg)
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SetWarningHandler(nil)
+	})
+
+	encode := func(errx *flaw.Error) {
+		Expect(errx.Error()).To(ContainSubstring("…(cycle)"))
+		Expect(fmt.Sprintf("%+v", errx)).To(ContainSubstring("…(cycle)"))
+
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("…(cycle)"))
+
+		_, err = xml.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+	}
+
+	It("marks the error that causes itself", func() {
+		errx := flaw.Errorf("oh no")
+		errx.Wrap(errx)
+
+		encode(errx)
+		Expect(errx.Error()).To(Equal("message: oh no cause: …(cycle)"))
+	})
+
+	It("marks the cycle of causes", func() {
+		first := flaw.Errorf("first")
+		second := flaw.Errorf("second").WithError(first)
+		first.Wrap(second)
+
+		encode(first)
+		Expect(first.Error()).To(Equal("message: first cause: …(cycle)"))
+		Expect(second.Error()).To(Equal("message: second cause: …(cycle)"))
+	})
+
+	It("marks the collector that contains the error", func() {
+		errs := flaw.ErrorCollector{fmt.Errorf("oh no")}
+		errx := flaw.Errorf("failed").WithError(errs)
+		errs[0] = errx
+
+		encode(errx)
+	})
+
+	It("marks the context that contains the error", func() {
+		inner := flaw.Map{}
+		errx := flaw.Errorf("failed").WithContext(flaw.Map{"inner": inner})
+		inner["error"] = errx
+		errx.Wrap(fmt.Errorf("…(cycle)"))
+
+		encode(errx)
+		Expect(errx.Context()).To(HaveKeyWithValue("inner", "…(cycle)"))
+	})
+
+	It("marks the context map that contains itself", func() {
+		self := flaw.Map{}
+		self["self"] = self
+
+		errx := flaw.Errorf("failed").WithContext(flaw.Map{"self": self, "user": "root"})
+		errx.Wrap(fmt.Errorf("…(cycle)"))
+
+		encode(errx)
+		Expect(errx.Context()).To(HaveKeyWithValue("self", "…(cycle)"))
+		Expect(errx.Context()).To(HaveKeyWithValue("user", "root"))
+	})
+
+	It("does not mark the shared values", func() {
+		shared := flaw.Map{"user": "root"}
+		errx := flaw.Errorf("failed").WithContext(flaw.Map{"first": shared, "second": shared})
+
+		Expect(errx.Context()).To(HaveKeyWithValue("first", shared))
+		Expect(errx.Context()).To(HaveKeyWithValue("second", shared))
+	})
+})
@@ -0,0 +1,275 @@
+package flaw
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const keyCauseType = "error_cause_type"
+
+// the type tags of the cause
+const (
+	causeFlaw       = "flaw"
+	causeText       = "text"
+	causeCollection = "collection"
+	causeJSON       = "json"
+)
+
+// causeType returns the type tag of the cause, so that the cause is decoded
+// as the same kind of error
+func causeType(err error) string {
+	switch err.(type) {
+	case *Error:
+		return causeFlaw
+	case ErrorCollector:
+		return causeCollection
+	case json.Marshaler:
+		return causeJSON
+	default:
+		return causeText
+	}
+}
+
+// inferred reports whether the decoder infers the type of the marshaled cause
+// from its json, so the type tag is not needed
+func inferred(err error) bool {
+	switch cause := err.(type) {
+	case *Error:
+		return true
+	case ErrorCollector:
+		// the keyed collections are marshaled as objects
+		return !positions.Load() && !cause.keyed()
+	default:
+		return false
+	}
+}
+
+// Parse decodes an error from the json produced by MarshalJSON, e.g. the
+// body of an error response, so the clients rebuild the code, the message,
+// the details, the context and the causes of the error. The stack trace is
+// not restored.
+func Parse(data []byte) (*Error, error) {
+	errx := &Error{}
+
+	if err := json.Unmarshal(data, errx); err != nil {
+		return nil, err
+	}
+
+	return errx, nil
+}
+
+// rawError is a decoded cause that was marshaled by an error other than a
+// flaw error. It marshals back to the same json.
+type rawError json.RawMessage
+
+// Error returns the json of the error
+func (x rawError) Error() string {
+	return string(x)
+}
+
+// MarshalJSON returns the json of the error
+func (x rawError) MarshalJSON() ([]byte, error) {
+	return []byte(x), nil
+}
+
+// decodeInt decodes an integer from a json number or a json string, since
+// some gateways serialize the numbers as strings
+func decodeInt(value json.RawMessage, target *int) error {
+	var text string
+
+	if err := json.Unmarshal(value, &text); err != nil {
+		return json.Unmarshal(value, target)
+	}
+
+	number, err := strconv.Atoi(text)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", text)
+	}
+
+	*target = number
+	return nil
+}
+
+// decode decodes the error from its json fields
+func (x *Error) decode(m map[string]json.RawMessage) error {
+	for key, value := range m {
+		var err error
+
+		switch key {
+		case keyCode:
+			err = decodeInt(value, &x.code)
+		case keyStatus:
+			err = decodeInt(value, &x.status)
+		case keyMessage:
+			err = json.Unmarshal(value, &x.msg)
+		case keyNamespace:
+			err = json.Unmarshal(value, &x.namespace)
+		case keyUser:
+			err = json.Unmarshal(value, &x.user)
+		case keyTenant:
+			err = json.Unmarshal(value, &x.tenant)
+		case keyFallback:
+			err = json.Unmarshal(value, &x.fallback)
+		case keyRunbook:
+			err = json.Unmarshal(value, &x.runbook)
+		case keyIncidentKey:
+			err = json.Unmarshal(value, &x.incident)
+		case keySeverity:
+			err = json.Unmarshal(value, &x.severity)
+		case keyEscalations:
+			err = json.Unmarshal(value, &x.escalations)
+		case keyRemote:
+			err = json.Unmarshal(value, &x.remote)
+		case keyPointer:
+			err = json.Unmarshal(value, &x.pointer)
+		case keyComponent:
+			err = json.Unmarshal(value, &x.component)
+		case keyDetails:
+			err = json.Unmarshal(value, &x.details)
+		case keyArgs:
+			err = json.Unmarshal(value, &x.args)
+		case keyAttachments:
+			err = json.Unmarshal(value, &x.attachments)
+		case keyCodeText, keyStatusText, keyCause, keyCauseType, keyAnnotations, keyPosition:
+			// the texts are derived and the rest is decoded with the cause
+		default:
+			var item interface{}
+
+			decoder := json.NewDecoder(bytes.NewReader(value))
+			// the numbers keep their exact representation
+			decoder.UseNumber()
+
+			if err = decoder.Decode(&item); err == nil {
+				x.context[key] = item
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("flaw: cannot decode %s: %w", key, err)
+		}
+	}
+
+	var kind string
+
+	if value, ok := m[keyCauseType]; ok {
+		if err := json.Unmarshal(value, &kind); err != nil {
+			return fmt.Errorf("flaw: cannot decode %s: %w", keyCauseType, err)
+		}
+	}
+
+	reason, err := decodeCause(m[keyCause], kind)
+	if err != nil {
+		return fmt.Errorf("flaw: cannot decode %s: %w", keyCause, err)
+	}
+
+	x.reason = reason
+
+	if value, ok := m[keyAnnotations]; ok {
+		var layers []string
+
+		if err := json.Unmarshal(value, &layers); err != nil {
+			return fmt.Errorf("flaw: cannot decode %s: %w", keyAnnotations, err)
+		}
+
+		x.annotate(layers)
+	}
+
+	return nil
+}
+
+// annotate restores the annotation layers around the cause. The context of
+// the layers is kept by the top layer.
+func (x *Error) annotate(layers []string) {
+	if len(layers) == 0 {
+		return
+	}
+
+	for index := len(layers) - 1; index > 0; index-- {
+		x.reason = &Error{
+			code:       x.code,
+			status:     x.status,
+			msg:        layers[index],
+			context:    Map{},
+			reason:     x.reason,
+			annotation: true,
+		}
+	}
+
+	x.msg = layers[0]
+	x.annotation = true
+}
+
+// decodeCause decodes the cause by its type tag. The type is inferred from the
+// json if the tag is missing.
+func decodeCause(data json.RawMessage, kind string) (error, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if kind == "" {
+		switch data[0] {
+		case '{':
+			kind = causeFlaw
+		case '[':
+			kind = causeCollection
+		default:
+			kind = causeText
+		}
+	}
+
+	switch kind {
+	case causeFlaw:
+		errx := &Error{}
+
+		if err := json.Unmarshal(data, errx); err != nil {
+			return nil, err
+		}
+
+		return errx, nil
+	case causeCollection:
+		if data[0] == '{' {
+			return decodeKeyed(data)
+		}
+
+		var items []json.RawMessage
+
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+
+		errs := make(ErrorCollector, 0, len(items))
+
+		for index, item := range items {
+			// the items are either flaw errors or texts
+			cause, err := decodeCause(item, "")
+			if err != nil {
+				return nil, err
+			}
+
+			if cause == nil {
+				return nil, fmt.Errorf("missing error at index %d", index)
+			}
+
+			errs = append(errs, cause)
+		}
+
+		return errs, nil
+	case causeJSON:
+		return rawError(append(json.RawMessage{}, data...)), nil
+	case causeText:
+		var text string
+
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, err
+		}
+
+		return errors.New(text), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", kind)
+	}
+}
@@ -0,0 +1,191 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type MarshalerError struct {
+	Reason string `json:"reason"`
+}
+
+func (x *MarshalerError) Error() string {
+	return x.Reason
+}
+
+func (x *MarshalerError) MarshalJSON() ([]byte, error) {
+	type plain MarshalerError
+	return json.Marshal((*plain)(x))
+}
+
+var _ = Describe("UnmarshalJSON", func() {
+	roundtrip := func(err error) (*flaw.Error, []byte) {
+		data, jerr := json.Marshal(err)
+		Expect(jerr).NotTo(HaveOccurred())
+
+		errx := &flaw.Error{}
+		Expect(json.Unmarshal(data, errx)).To(Succeed())
+
+		again, jerr := json.Marshal(errx)
+		Expect(jerr).NotTo(HaveOccurred())
+		Expect(again).To(MatchJSON(data))
+
+		return errx, data
+	}
+
+	It("decodes the fields", func() {
+		err := flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithDetails("id is invalid").
+			WithContext(flaw.Map{"user_id": 42})
+
+		errx, _ := roundtrip(err)
+		Expect(errx.Code()).To(Equal(5))
+		Expect(errx.Message()).To(Equal("user not found"))
+		Expect(errx.Details()).To(ConsistOf("id is invalid"))
+		Expect(errx.Context()).To(HaveKeyWithValue("user_id", json.Number("42")))
+	})
+
+	It("decodes the code and the status from strings", func() {
+		errx, err := flaw.Parse([]byte(`{"error_code":"5","error_status":"404","error_message":"user not found"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errx.Code()).To(Equal(5))
+		Expect(errx.Status()).To(Equal(404))
+	})
+
+	It("fails to decode a code that is not a number", func() {
+		_, err := flaw.Parse([]byte(`{"error_code":"five"}`))
+		Expect(err).To(MatchError(ContainSubstring(`invalid number "five"`)))
+	})
+
+	Context("when the codes are text", func() {
+		BeforeEach(func() {
+			flaw.SetTextCodes(true)
+		})
+
+		AfterEach(func() {
+			flaw.SetTextCodes(false)
+		})
+
+		It("encodes the code as a string", func() {
+			err := flaw.Errorf("user not found").WithCode(5)
+
+			data, jerr := json.Marshal(err)
+			Expect(jerr).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"error_code":"5"`))
+
+			errx, _ := roundtrip(err)
+			Expect(errx.Code()).To(Equal(5))
+		})
+	})
+
+	It("decodes a flaw cause as a flaw error", func() {
+		err := flaw.Errorf("failed").WithError(flaw.Errorf("oh no").WithCode(5))
+
+		errx, data := roundtrip(err)
+		Expect(string(data)).NotTo(ContainSubstring(`"error_cause_type"`))
+
+		var cause *flaw.Error
+		Expect(errors.As(errors.Unwrap(errx), &cause)).To(BeTrue())
+		Expect(cause.Code()).To(Equal(5))
+	})
+
+	It("decodes a plain cause as a plain error", func() {
+		err := flaw.Errorf("failed").WithError(fmt.Errorf("oh no"))
+
+		errx, data := roundtrip(err)
+		Expect(string(data)).NotTo(ContainSubstring(`"error_cause_type"`))
+
+		var cause *flaw.Error
+		Expect(errors.As(errors.Unwrap(errx), &cause)).To(BeFalse())
+		Expect(errors.Unwrap(errx)).To(MatchError("oh no"))
+	})
+
+	It("decodes a json cause as it was", func() {
+		err := flaw.Errorf("failed").WithError(&MarshalerError{Reason: "oh no"})
+
+		errx, data := roundtrip(err)
+		Expect(string(data)).To(ContainSubstring(`"error_cause_type":"json"`))
+
+		var cause *flaw.Error
+		Expect(errors.As(errors.Unwrap(errx), &cause)).To(BeFalse())
+	})
+
+	It("decodes a collection cause", func() {
+		errs := flaw.ErrorCollector{fmt.Errorf("oh no"), flaw.Errorf("oh yes")}
+		err := flaw.Errorf("failed").WithError(errs)
+
+		errx, _ := roundtrip(err)
+
+		collector, ok := errors.Unwrap(errx).(flaw.ErrorCollector)
+		Expect(ok).To(BeTrue())
+		Expect(collector).To(HaveLen(2))
+		Expect(collector[0]).To(MatchError("oh no"))
+		Expect(collector[1]).To(BeAssignableToTypeOf(&flaw.Error{}))
+	})
+
+	It("decodes the annotation layers", func() {
+		err := flaw.Annotate(flaw.Annotate(fmt.Errorf("sql: no rows"), "querying users"), "loading user profile")
+
+		errx, _ := roundtrip(err)
+		Expect(fmt.Sprintf("%+v", errx)).To(ContainSubstring("while querying users"))
+		Expect(errx).To(MatchError(ContainSubstring("sql: no rows")))
+	})
+
+	It("infers the cause type when the tag is missing", func() {
+		errx := &flaw.Error{}
+		Expect(json.Unmarshal([]byte(`{"error_message":"failed","error_cause":{"error_message":"oh no"}}`), errx)).To(Succeed())
+
+		var cause *flaw.Error
+		Expect(errors.As(errors.Unwrap(errx), &cause)).To(BeTrue())
+		Expect(cause.Message()).To(Equal("oh no"))
+	})
+
+	It("returns an error for a null collection item", func() {
+		_, err := flaw.Parse([]byte(`{"error_message":"failed","error_cause":[null,"oh no"]}`))
+		Expect(err).To(MatchError(ContainSubstring("missing error at index 0")))
+	})
+
+	It("returns an error for an unknown cause type", func() {
+		errx := &flaw.Error{}
+		Expect(json.Unmarshal([]byte(`{"error_cause":"oh no","error_cause_type":"unknown"}`), errx)).To(MatchError(ContainSubstring("unknown type")))
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("parses the error", func() {
+		err := flaw.Errorf("failed").
+			WithCode(13).
+			WithDetails("try again").
+			WithContext(flaw.Map{"user": "root"}).
+			WithError(flaw.Errorf("oh no").WithCode(5))
+
+		data, jerr := json.Marshal(err)
+		Expect(jerr).NotTo(HaveOccurred())
+
+		errx, perr := flaw.Parse(data)
+		Expect(perr).NotTo(HaveOccurred())
+		Expect(errx.Code()).To(Equal(13))
+		Expect(errx.Message()).To(Equal("failed"))
+		Expect(errx.Details()).To(ConsistOf("try again"))
+		Expect(errx.Context()).To(HaveKeyWithValue("user", "root"))
+
+		var cause *flaw.Error
+		Expect(errors.As(errors.Unwrap(errx), &cause)).To(BeTrue())
+		Expect(cause.Code()).To(Equal(5))
+		Expect(cause.Message()).To(Equal("oh no"))
+	})
+
+	It("returns an error for an invalid json", func() {
+		errx, err := flaw.Parse([]byte(`[]`))
+		Expect(err).To(HaveOccurred())
+		Expect(errx).To(BeNil())
+	})
+})
@@ -0,0 +1,314 @@
+package flaw
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Encoder writes errors as JSON to an output stream. The errors are written
+// value by value, so large error collections are not built in memory. The
+// object keys are sorted as by json.Marshal, so the output of the same error
+// is byte for byte stable, e.g. for caching or signing the responses.
+type Encoder struct {
+	writer     io.Writer
+	escapeHTML bool
+	prefix     string
+	indent     string
+	envelope   string
+	stack      bool
+}
+
+// NewEncoder returns a new encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		writer:     w,
+		escapeHTML: true,
+	}
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be
+// escaped inside JSON quoted strings. The default behavior is to escape &, <,
+// and > to &, <, and >.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.escapeHTML = on
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded value as
+// if indented by json.Indent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+}
+
+// SetEnvelope instructs the encoder to wrap each subsequent encoded value in
+// an object under the given key, e.g. {"error": {...}}. An empty key disables
+// the envelope.
+func (e *Encoder) SetEnvelope(key string) {
+	e.envelope = key
+}
+
+// SetStackTrace instructs the encoder to include the stack traces of the
+// errors as arrays of objects with the function, the file and the line of
+// every frame, e.g. for the internal logging pipelines. The stack traces are
+// omitted by default, as by json.Marshal, so they do not reach the clients.
+func (e *Encoder) SetStackTrace(on bool) {
+	e.stack = on
+}
+
+// Encode writes the JSON encoding of the error to the stream, followed by a
+// newline character. The errors that are not json.Marshaler are encoded as
+// their message.
+func (e *Encoder) Encode(err error) error {
+	var (
+		buffer = bufio.NewWriter(e.writer)
+		stream = &stream{Encoder: e, writer: buffer}
+	)
+
+	depth := 0
+
+	if e.envelope != "" {
+		depth++
+
+		stream.write("{")
+		stream.newline(depth)
+		stream.scalar(e.envelope, depth)
+		stream.separator()
+	}
+
+	switch err.(type) {
+	case nil:
+		stream.write("null")
+	case json.Marshaler:
+		stream.value(err, depth)
+	default:
+		stream.scalar(err.Error(), depth)
+	}
+
+	if e.envelope != "" {
+		stream.newline(0)
+		stream.write("}")
+	}
+
+	if stream.err == nil {
+		stream.write("\n")
+	}
+
+	if stream.err == nil {
+		stream.err = buffer.Flush()
+	}
+
+	stats.bytes.Add(uint64(stream.size))
+	return stream.err
+}
+
+// frames adds the stack traces of the error to its payload
+func (x *Error) frames(data dictionary) {
+	objects := func(stack StackTrace) []interface{} {
+		items := make([]interface{}, len(stack))
+
+		for index, frame := range stack {
+			file, line := frame.source()
+
+			items[index] = map[string]interface{}{
+				"function": frame.Function,
+				"file":     file,
+				"line":     line,
+			}
+		}
+
+		return items
+	}
+
+	if x.stack != nil {
+		data[keyStack] = objects(x.stack)
+	}
+
+	if x.remoteStack != nil {
+		data[keyRemoteStack] = objects(x.remoteStack)
+	}
+}
+
+// flushInterval is the number of the collector items after which the stream
+// is flushed to the output
+const flushInterval = 256
+
+// stream holds the state of a single Encode call
+type stream struct {
+	*Encoder
+	writer *bufio.Writer
+	size   int
+	err    error
+}
+
+func (s *stream) write(text string) {
+	if s.err != nil {
+		return
+	}
+
+	n, err := s.writer.WriteString(text)
+	s.size += n
+	s.err = err
+}
+
+func (s *stream) value(value interface{}, depth int) {
+	switch item := value.(type) {
+	case *Error:
+		if item == nil {
+			s.write("null")
+			return
+		}
+
+		data := item.payload()
+
+		if s.stack {
+			item.frames(data)
+		}
+
+		s.object(data, depth)
+	case ErrorCollector:
+		if positions.Load() {
+			// the positions depend on the parents, so the items are not
+			// streamed
+			s.scalar(item.positions(""), depth)
+			return
+		}
+
+		if item.keyed() {
+			s.object(map[string]interface{}{keyItems: item.items()}, depth)
+		} else {
+			s.array(item, depth)
+		}
+	case dictionary:
+		s.object(item, depth)
+	case map[string]interface{}:
+		s.object(item, depth)
+	default:
+		s.scalar(value, depth)
+	}
+}
+
+func (s *stream) object(data map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(data))
+
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	s.write("{")
+
+	for index, key := range keys {
+		if index > 0 {
+			s.write(",")
+		}
+
+		s.newline(depth + 1)
+		s.scalar(key, depth+1)
+		s.separator()
+
+		s.value(data[key], depth+1)
+	}
+
+	if len(keys) > 0 {
+		s.newline(depth)
+	}
+
+	s.write("}")
+}
+
+func (s *stream) array(errs ErrorCollector, depth int) {
+	s.write("[")
+
+	for index, err := range errs {
+		if index > 0 {
+			s.write(",")
+		}
+
+		s.newline(depth + 1)
+
+		if _, ok := err.(json.Marshaler); ok {
+			s.value(err, depth+1)
+		} else {
+			s.scalar(err.Error(), depth+1)
+		}
+
+		if (index+1)%flushInterval == 0 {
+			s.flush()
+		}
+	}
+
+	if len(errs) > 0 {
+		s.newline(depth)
+	}
+
+	s.write("]")
+}
+
+func (s *stream) scalar(value interface{}, depth int) {
+	if s.err != nil {
+		return
+	}
+
+	buffer := &bytes.Buffer{}
+
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(s.escapeHTML)
+
+	if s.err = encoder.Encode(value); s.err != nil {
+		return
+	}
+
+	// the encoder terminates every value with a new line
+	data := bytes.TrimSuffix(buffer.Bytes(), []byte("\n"))
+
+	if s.indent != "" || s.prefix != "" {
+		// the scalar might be a json.Marshaler that produces an object
+		indented := &bytes.Buffer{}
+
+		if err := json.Indent(indented, data, s.prefix+strings.Repeat(s.indent, depth), s.indent); err == nil {
+			data = indented.Bytes()
+		}
+	}
+
+	s.write(string(data))
+}
+
+// flush writes the buffered data to the output. The output is flushed too if
+// it's buffered, e.g. http.ResponseWriter, so the client receives the items
+// while the rest is being encoded.
+func (s *stream) flush() {
+	type Flusher interface {
+		Flush()
+	}
+
+	if s.err == nil {
+		s.err = s.writer.Flush()
+	}
+
+	if flusher, ok := s.Encoder.writer.(Flusher); ok && s.err == nil {
+		flusher.Flush()
+	}
+}
+
+func (s *stream) separator() {
+	s.write(":")
+
+	if s.indent != "" || s.prefix != "" {
+		s.write(" ")
+	}
+}
+
+func (s *stream) newline(depth int) {
+	if s.indent == "" && s.prefix == "" {
+		return
+	}
+
+	s.write("\n")
+	s.write(s.prefix)
+	s.write(strings.Repeat(s.indent, depth))
+}
@@ -0,0 +1,177 @@
+package flaw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *flaw.Encoder
+		errx    *flaw.Error
+	)
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		encoder = flaw.NewEncoder(buffer)
+
+		errx = flaw.Errorf("<user> not found").
+			WithCode(5).
+			WithDetails("the user might be deleted").
+			WithContext(flaw.Map{"user": "root", "ids": []int{1, 2}}).
+			WithError(flaw.ErrorCollector{fmt.Errorf("oh no"), flaw.Errorf("a & b")})
+	})
+
+	It("encodes the error as json.Marshal does", func() {
+		Expect(encoder.Encode(errx)).To(Succeed())
+
+		data, err := json.Marshal(errx)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(string(data) + "\n"))
+	})
+
+	It("encodes the error as json.MarshalIndent does", func() {
+		encoder.SetIndent(">", "  ")
+		Expect(encoder.Encode(errx)).To(Succeed())
+
+		data, err := json.MarshalIndent(errx, ">", "  ")
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(string(data) + "\n"))
+	})
+
+	It("encodes the collector", func() {
+		errs := flaw.ErrorCollector{fmt.Errorf("oh no"), flaw.Errorf("oh yes")}
+		Expect(encoder.Encode(errs)).To(Succeed())
+		Expect(buffer.String()).To(Equal(`["oh no",{"error_message":"oh yes"}]` + "\n"))
+	})
+
+	It("encodes the other errors as string", func() {
+		Expect(encoder.Encode(fmt.Errorf("oh no"))).To(Succeed())
+		Expect(buffer.String()).To(Equal(`"oh no"` + "\n"))
+	})
+
+	Context("when the stack trace is enabled", func() {
+		BeforeEach(func() {
+			encoder.SetStackTrace(true)
+		})
+
+		It("encodes the stack traces as objects", func() {
+			Expect(encoder.Encode(errx)).To(Succeed())
+
+			data := map[string]interface{}{}
+			Expect(json.Unmarshal(buffer.Bytes(), &data)).To(Succeed())
+			Expect(data).To(HaveKey("error_stack"))
+
+			frames := data["error_stack"].([]interface{})
+			Expect(frames).To(HaveLen(len(errx.StackTrace())))
+
+			frame := errx.StackTrace()[0]
+			Expect(frames[0]).To(Equal(map[string]interface{}{
+				"function": frame.Function,
+				"file":     frame.File,
+				"line":     float64(frame.Line),
+			}))
+		})
+
+		It("encodes the stack traces of the causes", func() {
+			Expect(encoder.Encode(errx)).To(Succeed())
+
+			data := map[string]interface{}{}
+			Expect(json.Unmarshal(buffer.Bytes(), &data)).To(Succeed())
+
+			cause := data["error_cause"].([]interface{})[1]
+			Expect(cause).To(HaveKey("error_stack"))
+		})
+
+		It("does not change json.Marshal", func() {
+			data, err := json.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).NotTo(ContainSubstring("error_stack"))
+		})
+	})
+
+	Context("when the html escaping is disabled", func() {
+		BeforeEach(func() {
+			encoder.SetEscapeHTML(false)
+		})
+
+		It("does not escape the html characters", func() {
+			Expect(encoder.Encode(errx)).To(Succeed())
+			Expect(buffer.String()).To(ContainSubstring(`"error_message":"<user> not found"`))
+			Expect(buffer.String()).To(ContainSubstring(`{"error_message":"a & b"}`))
+		})
+	})
+
+	Context("when the context contains raw json", func() {
+		It("passes the raw json through", func() {
+			errx := flaw.Errorf("failed").WithContext(flaw.Map{
+				"response": json.RawMessage(`{"id": "<42>"}`),
+			})
+
+			encoder.SetEscapeHTML(false)
+			Expect(encoder.Encode(errx)).To(Succeed())
+			Expect(buffer.String()).To(Equal(`{"error_message":"failed","response":{"id":"<42>"}}` + "\n"))
+		})
+	})
+
+	Context("when the envelope is set", func() {
+		BeforeEach(func() {
+			encoder.SetEnvelope("error")
+		})
+
+		It("wraps the error in the envelope", func() {
+			Expect(encoder.Encode(flaw.Errorf("failed"))).To(Succeed())
+			Expect(buffer.String()).To(Equal(`{"error":{"error_message":"failed"}}` + "\n"))
+		})
+
+		It("wraps the indented error in the envelope", func() {
+			encoder.SetIndent("", "  ")
+			Expect(encoder.Encode(errx)).To(Succeed())
+
+			data, err := json.MarshalIndent(map[string]interface{}{"error": errx}, "", "  ")
+			Expect(err).To(BeNil())
+			Expect(buffer.String()).To(Equal(string(data) + "\n"))
+		})
+	})
+
+	Context("when the writer fails", func() {
+		It("returns the error", func() {
+			encoder = flaw.NewEncoder(&FailingWriter{})
+			Expect(encoder.Encode(errx)).NotTo(Succeed())
+		})
+	})
+
+	Describe("ErrorCollector.Encode", func() {
+		It("streams the collector", func() {
+			errs := flaw.ErrorCollector{}
+
+			for index := 0; index < 1000; index++ {
+				errs.Wrap(flaw.Errorf("item %d failed", index))
+			}
+
+			writer := &FlushRecorder{}
+			Expect(errs.Encode(writer)).To(Succeed())
+			Expect(writer.flushes).To(Equal(3))
+
+			data, err := json.Marshal(errs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.String()).To(Equal(string(data) + "\n"))
+		})
+	})
+})
+
+type FlushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (w *FlushRecorder) Flush() {
+	w.flushes++
+}
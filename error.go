@@ -1,26 +1,37 @@
 package flaw
 
 import (
-	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/phogolabs/flaw/format"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/structpb"
-	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 const (
-	keyCode    = "error_code"
-	keyMessage = "error_message"
-	keyDetails = "error_details"
-	keyCause   = "error_cause"
-	keyStack   = "error_stack"
+	keyCode        = "error_code"
+	keyCodeText    = "error_code_text"
+	keyStatus      = "error_status"
+	keyStatusText  = "error_status_text"
+	keyMessage     = "error_message"
+	keyNamespace   = "error_namespace"
+	keyUser        = "error_user"
+	keyTenant      = "error_tenant"
+	keyFallback    = "error_fallback"
+	keyRunbook     = "error_runbook"
+	keyIncidentKey = "error_incident_key"
+	keyArgs        = "error_args"
+	keyDetails     = "error_details"
+	keyCause       = "error_cause"
+	keyStack       = "error_stack"
+	keyAttachments = "error_attachments"
 )
 
 var (
@@ -45,69 +56,246 @@ func (x ErrorConstant) Format(state fmt.State, verb rune) {
 	fmt.Fprintf(state, "%v", string(x))
 }
 
-// Error represents a wrapped error
+// Is reports whether the target's message equals the constant.
+func (x ErrorConstant) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+
+	return string(x) == target.Error()
+}
+
+// With creates a new error which message is the constant formatted with the
+// given arguments. The error matches the constant when used with errors.Is.
+func (x ErrorConstant) With(data ...interface{}) *Error {
+	return x.new(data).capture(1)
+}
+
+// WithSkip creates a new error as With, which stack trace skips the given
+// number of the caller frames, e.g. the frames of the generated constructors
+// that call it, so the stack trace starts at their caller
+func (x ErrorConstant) WithSkip(skip int, data ...interface{}) *Error {
+	return x.new(data).capture(1 + skip)
+}
+
+func (x ErrorConstant) new(data []interface{}) *Error {
+	return &Error{
+		status:   500,
+		msg:      fmt.Sprintf(string(x), data...),
+		template: x,
+	}
+}
+
+// statusCache caches the gRPC status of a frozen error
+type statusCache struct {
+	owner  *Error
+	once   sync.Once
+	status interface{}
+}
+
+// Error represents a wrapped error. The With* methods create copies of the
+// error, which makes a constructed error safe for concurrent reads.
 type Error struct {
-	code    int
-	status  int
-	msg     string
-	details format.StringSlice
-	stack   StackTrace
-	context map[string]interface{}
-	reason  error
+	code        int
+	status      int
+	msg         string
+	values      []interface{}
+	namespace   string
+	user        string
+	tenant      string
+	fallback    string
+	runbook     string
+	incident    string
+	severity    Severity
+	escalations []Escalation
+	remote      Remote
+	remoteStack StackTrace
+	pointer     string
+	component   string
+	private     format.StringSlice
+	template    ErrorConstant
+	details     format.StringSlice
+	args        format.StringSlice
+	stack       StackTrace
+	context     map[string]interface{}
+	reason      error
+	attachments attachments
+	skipped     bool
+	frozen      bool
+	annotation  bool
+	cache       *statusCache
 }
 
 // Errorf creates a new error
 func Errorf(msg string, data ...interface{}) *Error {
-	return &Error{
-		status:  500,
-		msg:     fmt.Sprintf(msg, data...),
-		context: Map{},
-		stack:   NewStackTrace(),
+	errx := &Error{
+		status: 500,
+		msg:    fmt.Sprintf(msg, data...),
 	}
+
+	return errx.capture(1)
 }
 
-// Wrap wraps an error
+// Wrap wraps an error. A flaw error is returned as it is. An error which chain
+// contains a flaw error gets a new wrapper, which inherits the code and the
+// status of the flaw error. The code and the status of the well known
+// filesystem, os and system call errors are set, and the path of a
+// *fs.PathError is put into the context.
 func Wrap(err error, frames ...StackFrame) *Error {
-	var errx *Error
+	if errx, ok := err.(*Error); ok {
+		if len(frames) > 0 {
+			warnf("flaw: Wrap of a flaw error ignores the frames")
+		}
 
-	if !errors.As(err, &errx) {
-		stack := StackTrace(frames)
+		return errx
+	}
 
-		if len(stack) == 0 {
-			stack = NewStackTrace()
-		}
+	errx := wrap(err)
 
-		errx = &Error{
-			status:  500,
-			reason:  err,
-			context: Map{},
-			stack:   stack,
-		}
+	if len(frames) == 0 {
+		return errx.capture(1)
 	}
 
+	errx.stack = StackTrace(frames)
+	stats.errors.Add(1)
 	return errx
 }
 
+// wrap creates a new error that wraps the given error. The new error inherits
+// the code and the status of a flaw error in the chain, or of a well known
+// error otherwise.
+func wrap(err error) *Error {
+	errx := &Error{
+		status: 500,
+		reason: err,
+	}
+
+	return errx.inherit()
+}
+
+// inherit sets the code and the status of a flaw error in the chain, or of a
+// well known error otherwise
+func (x *Error) inherit() *Error {
+	var cause *Error
+
+	if errors.As(x.reason, &cause) {
+		x.code = cause.code
+		x.status = cause.status
+		return x
+	}
+
+	return x.classify()
+}
+
+// WrapAll wraps the given errors. It returns nil if all errors are nil, the
+// wrapped error if only one of them is not nil and an ErrorCollector of the
+// errors that are not nil otherwise.
+func WrapAll(errs ...error) error {
+	items := ErrorCollector{}
+
+	for _, err := range errs {
+		if err != nil {
+			items = append(items, err)
+		}
+	}
+
+	switch len(items) {
+	case 0:
+		return nil
+	case 1:
+		if errx, ok := items[0].(*Error); ok {
+			return errx
+		}
+
+		return wrap(items[0]).capture(1)
+	default:
+		return items
+	}
+}
+
 // WithError creates an error copy with given error wrapped
 func (x Error) WithError(err error) *Error {
 	x.reason = err
-	x.stack = NewStackTrace()
-	return &x
+	return x.trace(1)
 }
 
 // WithMessage creates an error copy with given message
 func (x Error) WithMessage(text string) *Error {
 	x.msg = text
+	x.values = nil
 	return &x
 }
 
-// WithDetails creates an error copy with given details
+// WithDetails creates an error copy with given details. The details are kept
+// in the order of their addition, the oldest first. The details that the
+// error already has are skipped, so the wrapping layers that add the same
+// detail do not duplicate it.
 func (x Error) WithDetails(text string, details ...string) *Error {
-	x.details = append(x.details, text)
-	x.details = append(x.details, details...)
+	items := make(format.StringSlice, 0, len(x.details)+len(details)+1)
+	items = append(items, x.details...)
+
+	for _, detail := range append([]string{text}, details...) {
+		if !items.Contains(detail) {
+			items = append(items, detail)
+		}
+	}
+
+	x.details = items
+	return &x
+}
+
+// WithArgs creates an error copy with a snapshot of given arguments. The
+// arguments are stringified and truncated to MaxArgSize.
+func (x Error) WithArgs(values ...interface{}) *Error {
+	items := make(format.StringSlice, len(values))
+
+	for index, value := range values {
+		items[index] = truncate(fmt.Sprintf("%v", value), MaxArgSize)
+	}
+
+	x.args = items
+	return &x
+}
+
+// WithAttachment creates an error copy with given attachment. The data is
+// truncated to MaxAttachmentSize.
+func (x Error) WithAttachment(name string, data []byte, mime string) *Error {
+	items := make(attachments, 0, len(x.attachments)+1)
+	items = append(items, x.attachments...)
+	items = append(items, NewAttachment(name, data, mime))
+
+	x.attachments = items
 	return &x
 }
 
+// WithAttachments creates an error copy with given attachments. The data of
+// the attachments is copied and truncated to MaxAttachmentSize as by
+// NewAttachment.
+func (x Error) WithAttachments(items ...Attachment) *Error {
+	collection := make(attachments, 0, len(x.attachments)+len(items))
+	collection = append(collection, x.attachments...)
+
+	for _, item := range items {
+		attachment := NewAttachment(item.Name, item.Data, item.MimeType)
+		attachment.Truncated = attachment.Truncated || item.Truncated
+		collection = append(collection, attachment)
+	}
+
+	x.attachments = collection
+	return &x
+}
+
+// WithGoroutineDump creates an error copy with the stack traces of all
+// goroutines attached as "goroutines". The dump is truncated to
+// MaxAttachmentSize. The dump stops the world, use it for fatal errors only.
+func (x Error) WithGoroutineDump() *Error {
+	// the extra byte marks the dump as truncated
+	data := make([]byte, MaxAttachmentSize+1)
+	data = data[:runtime.Stack(data, true)]
+
+	return x.WithAttachment("goroutines", data, "text/plain")
+}
+
 // WithCode creates an error copy with given status
 func (x Error) WithCode(code int) *Error {
 	x.code = code
@@ -120,13 +308,66 @@ func (x Error) WithStatus(status int) *Error {
 	return &x
 }
 
-// WithContext creates an error copy with given map
+// WithUser creates an error copy with given user id. The reporters place it
+// in their dedicated user field.
+func (x Error) WithUser(id string) *Error {
+	x.user = id
+	return &x
+}
+
+// WithTenant creates an error copy with given tenant id
+func (x Error) WithTenant(id string) *Error {
+	x.tenant = id
+	return &x
+}
+
+// WithRunbook creates an error copy with given runbook url, so that the alerts
+// created from the error link to the runbook
+func (x Error) WithRunbook(url string) *Error {
+	x.runbook = url
+	return &x
+}
+
+// WithIncidentKey creates an error copy with given incident key. The alerts
+// with the same key are grouped into a single incident.
+func (x Error) WithIncidentKey(key string) *Error {
+	x.incident = key
+	return &x
+}
+
+// put sets the value of the given context key. The context is allocated on
+// first use, so the errors without context do not allocate it.
+func (x *Error) put(key string, value interface{}) {
+	if x.context == nil {
+		x.context = Map{}
+	}
+
+	x.context[key] = value
+}
+
+// WithFallbackUsed creates an error copy marked that the named fallback has
+// served the request
+func (x Error) WithFallbackUsed(name string) *Error {
+	x.fallback = name
+	return &x
+}
+
+// WithContext creates an error copy with given map. The map is copied, so
+// the later changes of the map do not affect the error. The values are
+// converted to the types declared by the schema of the error namespace.
 func (x Error) WithContext(context Map) *Error {
-	if context == nil {
-		context = Map{}
+	x.context = make(Map, len(context))
+
+	for key, value := range context {
+		x.context[key] = value
+	}
+
+	if schema := schema(x.namespace); schema != nil {
+		for _, key := range schema.apply(x.context) {
+			warnf("flaw: context %q of namespace %q is not %s", key, x.namespace, schema[key])
+		}
 	}
 
-	x.context = context
 	return &x
 }
 
@@ -142,7 +383,37 @@ func (x *Error) Status() int {
 
 // Message returns the error message
 func (x *Error) Message() string {
-	return x.msg
+	return x.message()
+}
+
+// Namespace returns the error namespace
+func (x *Error) Namespace() string {
+	return x.namespace
+}
+
+// User returns the id of the user that the error occurred for
+func (x *Error) User() string {
+	return x.user
+}
+
+// Tenant returns the id of the tenant that the error occurred for
+func (x *Error) Tenant() string {
+	return x.tenant
+}
+
+// Runbook returns the url of the runbook for the error
+func (x *Error) Runbook() string {
+	return x.runbook
+}
+
+// IncidentKey returns the key of the incident that the error belongs to
+func (x *Error) IncidentKey() string {
+	return x.incident
+}
+
+// FallbackUsed returns the name of the fallback that has served the request
+func (x *Error) FallbackUsed() string {
+	return x.fallback
 }
 
 // Details returns the error details
@@ -150,77 +421,134 @@ func (x *Error) Details() []string {
 	return x.details
 }
 
+// Attachments returns the error attachments
+func (x *Error) Attachments() []Attachment {
+	return x.attachments
+}
+
 // Cause returns the underlying error
 func (x *Error) Cause() error {
 	return x.reason
 }
 
-// GRPCStatus returns the grpc status of this error
-func (x *Error) GRPCStatus() *status.Status {
-	type Provider interface {
-		GRPCStatus() *status.Status
-	}
+// StackTrace returns the stack trace where the error occurred. The stack trace
+// is empty if its capture has been skipped by the sampling.
+func (x *Error) StackTrace() StackTrace {
+	return x.stack
+}
 
-	if provider, ok := x.reason.(Provider); ok {
-		return provider.GRPCStatus()
+// capture counts a newly created error and captures its stack trace, skipping
+// the given number of frames. The copies of an error call trace instead.
+func (x *Error) capture(skip int) *Error {
+	stats.errors.Add(1)
+	return x.trace(skip + 1)
+}
+
+// trace captures the stack trace of the caller, skipping the given number of
+// frames, unless the capture is skipped by the sampling.
+func (x *Error) trace(skip int) *Error {
+	x.stack = nil
+	x.skipped = !sample()
+
+	if x.skipped {
+		stats.skipped.Add(1)
+	} else {
+		stats.captured.Add(1)
+
+		// the stack trace of a wrapped pkg/errors error is where it has
+		// occurred, unless the error has been received from a remote
+		if x.remote.Service == "" {
+			x.stack = foreignStack(x.reason)
+		}
+
+		if x.stack == nil {
+			x.stack = NewStackTraceAt(skip)
+		}
 	}
 
-	var (
-		code   = codes.Internal
-		buffer = &bytes.Buffer{}
-	)
+	return x
+}
 
-	if x.code > 0 {
-		code = codes.Code(x.code)
+// ApproxSize returns an estimation of the error size in bytes once it's
+// serialized. The estimation includes the message, details, context, cause,
+// stack trace and attachments.
+func (x *Error) ApproxSize() int {
+	size := len(x.message()) + len(x.namespace) + len(x.user) + len(x.tenant) + len(x.fallback) +
+		len(x.runbook) + len(x.incident) + len(x.remote.Service) + len(x.remote.Endpoint) + len(x.pointer) +
+		len(x.component)
+
+	for _, detail := range x.details {
+		size += len(detail) + overhead
 	}
 
-	if x.msg != "" {
-		fmt.Fprint(buffer, x.msg)
+	for _, escalation := range x.escalations {
+		size += len(escalation.Reason) + 3*overhead
 	}
 
-	if x.reason != nil {
-		if buffer.Len() > 0 {
-			fmt.Fprint(buffer, ": ")
-		}
+	for _, arg := range x.args {
+		size += len(arg) + overhead
+	}
 
-		fmt.Fprintf(buffer, x.reason.Error())
+	for key, value := range x.context {
+		size += len(key) + approxSize(value) + overhead
 	}
 
-	payload := status.New(code, buffer.String())
+	for _, frame := range x.stack {
+		size += len(frame.File) + len(frame.Function) + overhead
+	}
 
-	// prepare the details
-	for _, item := range x.details {
-		// append the details
-		payload, _ = payload.WithDetails(&wrapperspb.StringValue{
-			Value: item,
-		})
+	for _, frame := range x.remoteStack {
+		size += len(frame.File) + len(frame.Function) + overhead
 	}
 
-	if len(x.context) > 0 {
-		// prepare the context
-		if details, err := structpb.NewStruct(x.context); err == nil {
-			// add the error as details
-			payload, _ = payload.WithDetails(details)
-		}
+	for _, item := range x.attachments {
+		// the data is serialized as base64
+		size += len(item.Data)*4/3 + len(item.Name) + len(item.MimeType) + overhead
 	}
 
-	return payload
-}
+	if x.reason != nil {
+		size += approxSize(x.reason)
+	}
 
-// StackTrace returns the stack trace where the error occurred
-func (x *Error) StackTrace() StackTrace {
-	return x.stack
+	return size
 }
 
-// Context returns the error's context
+// Context returns a copy of the error's context, which contains the error
+// fields as well
 func (x *Error) Context() Map {
 	return x.data()
 }
 
-// Wrap wraps the given error
+// Freeze creates an immutable copy of the error. Wrap panics if it's called
+// on a frozen error. The gRPC status of a frozen error is computed once and
+// cached.
+func (x Error) Freeze() *Error {
+	x.frozen = true
+	x.cache = &statusCache{owner: &x}
+	return &x
+}
+
+// Frozen returns true if the error is immutable
+func (x *Error) Frozen() bool {
+	return x.frozen
+}
+
+// Wrap wraps the given error. The wrapped copy is created as by WithError and
+// it replaces the error at once, which is still not safe when the error is
+// shared between goroutines.
+//
+// Deprecated: Wrap will be removed in the next major version. Use WithError
+// which creates a copy of the error instead.
 func (x *Error) Wrap(err error) {
-	x.stack = NewStackTrace()
-	x.reason = err
+	if x.frozen {
+		panic("flaw: wrap of frozen error")
+	}
+
+	warnf("flaw: (*Error).Wrap is deprecated, use WithError instead")
+
+	clone := *x
+	clone.reason = err
+	*x = *clone.trace(1)
 }
 
 // Unwrap unwraps the underlying error
@@ -228,6 +556,70 @@ func (x *Error) Unwrap() error {
 	return x.reason
 }
 
+// Is reports whether the error has been created from the target constant or
+// its message equals the constant.
+func (x *Error) Is(target error) bool {
+	if constant, ok := target.(ErrorConstant); ok && constant != "" {
+		return x.template == constant || x.message() == string(constant)
+	}
+
+	return false
+}
+
+// SameAs reports whether the error has the same semantic identity as the
+// other error. The errors are the same if they have the same code, namespace
+// and message template, regardless of the template arguments, the context and
+// the stack trace. The message is compared if an error has no template, and
+// the cause is compared if both errors have no message, e.g. the errors
+// created by Wrap.
+func (x *Error) SameAs(other error) bool {
+	y, ok := other.(*Error)
+	if !ok || y == nil {
+		return false
+	}
+
+	if x.code != y.code || x.namespace != y.namespace {
+		return false
+	}
+
+	if x.template != "" && y.template != "" {
+		return x.template == y.template
+	}
+
+	if x.message() != "" || y.message() != "" {
+		return x.message() == y.message()
+	}
+
+	return x.causeFingerprint() == y.causeFingerprint()
+}
+
+// Fingerprint returns a stable hash of the error semantic identity, which
+// consists of the code, the namespace and the message template. The
+// fingerprint of the cause is included if the error has no message. The
+// errors that are the same have the same fingerprint.
+func (x *Error) Fingerprint() string {
+	text := x.message()
+
+	if x.template != "" {
+		text = string(x.template)
+	}
+
+	if text == "" {
+		return fingerprint(strconv.Itoa(x.code), x.namespace, text, x.causeFingerprint())
+	}
+
+	return fingerprint(strconv.Itoa(x.code), x.namespace, text)
+}
+
+// causeFingerprint returns the fingerprint of the error's cause
+func (x *Error) causeFingerprint() string {
+	if x.loops(x.reason) {
+		return cycleMarker
+	}
+
+	return Fingerprint(x.reason)
+}
+
 // Error returns the error message
 func (x *Error) Error() string {
 	return fmt.Sprintf("%v", x)
@@ -239,20 +631,38 @@ func (x *Error) Error() string {
 //	%d    error details
 //	%c    error code
 //	%r    error reason
+//	%j    error as json
 //	%v    code: %d message: %s details: %d reason: %w
 //
 // Format accepts flags that alter the printing of some verbs, as follows:
 //
 //	%+s   stack trace
+//	%+j   error as indented json
+//	%#j   error as json with the status and code text
 //	%+v   equivalent
+//	%#v   equivalent with the status text
+//
+// The width of %+v sets the minimal width of the title column, e.g. %+12v.
 func (x *Error) Format(state fmt.State, verb rune) {
 	switch verb {
+	case 'j':
+		data := x.payload()
+
+		if state.Flag('#') {
+			x.describe(data)
+		}
+
+		formatJSON(state, data)
 	case 'c':
 		fmt.Fprintf(state, "%d", x.code)
 	case 'm':
-		fmt.Fprintf(state, "%s", x.msg)
+		fmt.Fprintf(state, "%s", clip(x.message()))
 	case 'r':
-		fmt.Fprintf(state, "%v", x.reason)
+		if x.loops(x.reason) {
+			fmt.Fprint(state, cycleMarker)
+		} else {
+			fmt.Fprint(state, clip(fmt.Sprintf("%v", x.reason)))
+		}
 	case 'd':
 		x.details.Format(state, 'v')
 	case 's':
@@ -261,59 +671,188 @@ func (x *Error) Format(state fmt.State, verb rune) {
 		formatter := format.NewState(state)
 		defer formatter.Flush()
 
+		// the values are indented, so that multi-line values do not break
+		// the columns in verbose mode
+		value := formatter.Indent()
+
 		if x.code != 0 {
 			x.title(formatter, "code:")
-			x.Format(formatter, 'c')
+
+			if name, ok := x.codeName(); ok && names.Load() {
+				fmt.Fprintf(value, "%v (", name)
+				x.Format(value, 'c')
+				fmt.Fprint(value, ")")
+			} else {
+				x.Format(value, 'c')
+			}
+		}
+
+		if x.status != 0 && state.Flag('#') {
+			x.title(formatter, "status:")
+			fmt.Fprint(value, x.status)
+
+			if text := http.StatusText(x.status); text != "" {
+				fmt.Fprint(value, " ", text)
+			}
 		}
 
-		if x.msg != "" {
+		// a message builder joins the cause into the message line
+		joined := x.message() != "" && x.reason != nil && !state.Flag('+') && messageBuilderOf() != nil
+
+		switch {
+		case x.annotation && state.Flag('+'):
+			x.title(formatter, "message:")
+			x.narrate(value)
+		case joined:
+			x.title(formatter, "message:")
+			fmt.Fprint(value, x.text())
+		case x.message() != "":
 			x.title(formatter, "message:")
-			x.Format(formatter, 'm')
+			x.Format(value, 'm')
 		}
 
 		if x.details != nil {
 			x.title(formatter, "details:")
-			x.newline(formatter)
-			x.Format(formatter, 'd')
+			x.newline(value)
+			x.Format(value, 'd')
+		}
+
+		if x.args != nil && state.Flag('+') {
+			x.title(formatter, "args:")
+			x.newline(value)
+			x.args.Format(value, 'v')
 		}
 
-		if x.reason != nil {
+		if x.reason != nil && !joined && !(x.annotation && state.Flag('+')) {
 			x.title(formatter, "cause:")
-			x.Format(formatter, 'r')
+
+			if errs, ok := x.reason.(ErrorCollector); ok && state.Flag('+') && !x.loops(errs) {
+				x.newline(value)
+				errs.Format(value, 'v')
+			} else {
+				x.Format(value, 'r')
+			}
+		}
+
+		if x.reason != nil && state.Flag('+') && !x.loops(x.reason) {
+			// the stack of a third-party cause is not dropped, unless it's
+			// the stack of the error already
+			if stack := foreignStack(x.reason); stack != nil {
+				if !stack.Equal(x.stack) {
+					x.title(formatter, "cause stack:")
+					x.newline(value)
+					stack.Format(value, 'v')
+				}
+			} else if text := verboseTrace(x.reason); text != "" {
+				x.title(formatter, "cause stack:")
+				x.newline(value)
+				fmt.Fprint(value, text)
+			}
 		}
 
 		if x.stack != nil && state.Flag('+') {
 			x.title(formatter, "stack:")
-			x.newline(formatter)
-			x.Format(formatter, 's')
+			x.newline(value)
+			x.Format(value, 's')
+		}
+
+		if x.skipped && state.Flag('+') {
+			x.title(formatter, "stack:")
+			fmt.Fprint(value, "(skipped by sampling)")
+		}
+
+		if x.remoteStack != nil && state.Flag('+') {
+			x.title(formatter, "remote stack:")
+			x.newline(value)
+			x.remoteStack.Format(value, 'v')
 		}
 	}
 }
 
 // MarshalJSON marshals the error as json
 func (x *Error) MarshalJSON() ([]byte, error) {
-	data := x.data(keyStack)
+	data, err := json.Marshal(x.payload())
+	stats.bytes.Add(uint64(len(data)))
+	return data, err
+}
 
-	if x.reason != nil {
-		if _, ok := x.reason.(json.Marshaler); ok {
-			data[keyCause] = x.reason
-		}
+// UnmarshalJSON unmarshals the error from json. The cause is decoded as a flaw
+// error, a collection or a plain error depending on the way it was marshaled,
+// so that errors.As finds the same errors after the round-trip. The stack
+// trace is not restored.
+func (x *Error) UnmarshalJSON(data []byte) error {
+	m := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	errx := Error{
+		status:  500,
+		context: Map{},
 	}
 
-	return json.Marshal(data)
+	if err := errx.decode(m); err != nil {
+		return err
+	}
+
+	*x = errx
+	return nil
 }
 
 // MarshalXML marshals the error as xml
 func (x *Error) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
-	data := x.data(keyStack)
+	data := x.conceal(x.data(keyStack, keyRemoteStack)).render()
 
-	if x.reason != nil {
-		if _, ok := x.reason.(xml.Marshaler); ok {
-			data[keyCause] = x.reason
+	if cause := x.cause(); cause != nil {
+		if _, ok := cause.(xml.Marshaler); ok && !x.loops(cause) {
+			data[keyCause] = cause
+		}
+	}
+
+	if len(x.attachments) > 0 {
+		data[keyAttachments] = x.attachments
+	}
+
+	return data.nest().MarshalXML(encoder, start)
+}
+
+func (x *Error) payload() dictionary {
+	data := x.conceal(x.data(keyStack, keyRemoteStack)).render()
+
+	if cause := x.cause(); cause != nil {
+		if _, ok := cause.(json.Marshaler); ok && !x.loops(cause) {
+			data[keyCause] = cause
+
+			if !inferred(cause) {
+				data[keyCauseType] = causeType(cause)
+			}
 		}
 	}
 
-	return data.MarshalXML(encoder, start)
+	if len(x.attachments) > 0 {
+		data[keyAttachments] = x.attachments
+	}
+
+	if _, ok := data[keyCode]; ok && textCodes.Load() {
+		data[keyCode] = strconv.Itoa(x.code)
+	}
+
+	return data.nest()
+}
+
+func (x *Error) describe(data dictionary) {
+	if x.status != 0 {
+		data[keyStatus] = x.status
+
+		if text := http.StatusText(x.status); text != "" {
+			data[keyStatusText] = text
+		}
+	}
+
+	if name, ok := x.codeName(); ok {
+		data[keyCodeText] = name
+	}
 }
 
 func (x *Error) data(keys ...string) dictionary {
@@ -333,23 +872,111 @@ func (x *Error) data(keys ...string) dictionary {
 		set(keyCode, x.code)
 	}
 
-	if x.msg != "" {
-		set(keyMessage, x.msg)
+	if x.annotation {
+		items, _ := x.layers()
+		annotations := make([]string, len(items))
+
+		for index, item := range items {
+			annotations[index] = item.message()
+		}
+
+		set(keyAnnotations, annotations)
+	} else if x.message() != "" {
+		set(keyMessage, clip(x.message()))
+	}
+
+	if x.namespace != "" {
+		set(keyNamespace, x.namespace)
+	}
+
+	if x.user != "" {
+		set(keyUser, x.user)
+	}
+
+	if x.tenant != "" {
+		set(keyTenant, x.tenant)
+	}
+
+	if x.fallback != "" {
+		set(keyFallback, x.fallback)
+	}
+
+	if x.runbook != "" {
+		set(keyRunbook, x.runbook)
+	}
+
+	if x.incident != "" {
+		set(keyIncidentKey, x.incident)
+	}
+
+	if x.pointer != "" {
+		set(keyPointer, x.pointer)
+	}
+
+	if x.component != "" {
+		set(keyComponent, x.component)
+	}
+
+	if x.severity != 0 {
+		set(keySeverity, x.severity.String())
+	}
+
+	if len(x.escalations) > 0 {
+		set(keyEscalations, x.escalations)
+	}
+
+	if x.remote.Service != "" {
+		remote := Map{"service": x.remote.Service}
+
+		if x.remote.Endpoint != "" {
+			remote["endpoint"] = x.remote.Endpoint
+		}
+
+		set(keyRemote, remote)
 	}
 
 	if len(x.details) > 0 {
 		set(keyDetails, x.details)
 	}
 
-	if x.reason != nil {
-		set(keyCause, x.reason.Error())
+	if len(x.args) > 0 {
+		set(keyArgs, x.args)
+	}
+
+	if cause := x.cause(); cause != nil {
+		if errs, ok := cause.(ErrorCollector); ok && !x.loops(errs) {
+			set(keyCause, errs)
+		} else if x.loops(cause) {
+			set(keyCause, cycleMarker)
+		} else {
+			set(keyCause, clip(cause.Error()))
+		}
 	}
 
 	if x.stack != nil {
 		set(keyStack, x.stack)
 	}
 
+	if x.remoteStack != nil {
+		set(keyRemoteStack, x.remoteStack)
+	}
+
+	if x.annotation {
+		items, _ := x.layers()
+
+		// the context of the outer layers takes precedence
+		for index := len(items) - 1; index > 0; index-- {
+			for k, v := range items[index].context {
+				set(k, v)
+			}
+		}
+	}
+
 	for k, v := range x.context {
+		if x.loops(v) {
+			v = cycleMarker
+		}
+
 		set(k, v)
 	}
 
@@ -374,13 +1001,17 @@ func (x *Error) title(formatter *format.State, text string) {
 	fmt.Fprint(formatter, " ")
 }
 
-func (x *Error) newline(formatter *format.State) {
+func (x *Error) newline(formatter fmt.State) {
 	if formatter.Flag('+') {
 		fmt.Fprint(formatter, "\n")
 	}
 }
 
-var _ error = ErrorCollector{}
+var (
+	_ error          = ErrorCollector{}
+	_ json.Marshaler = ErrorCollector{}
+	_ xml.Marshaler  = ErrorCollector{}
+)
 
 // ErrorCollector is a slice of errors
 type ErrorCollector []error
@@ -392,6 +1023,14 @@ func (errs ErrorCollector) Error() string {
 
 // MarshalJSON marshals the error as json
 func (errs ErrorCollector) MarshalJSON() ([]byte, error) {
+	if positions.Load() {
+		return json.Marshal(errs.positions(""))
+	}
+
+	if errs.keyed() {
+		return json.Marshal(map[string]interface{}{keyItems: errs.items()})
+	}
+
 	input := make([]interface{}, len(errs))
 
 	for index, err := range errs {
@@ -405,9 +1044,49 @@ func (errs ErrorCollector) MarshalJSON() ([]byte, error) {
 	return json.Marshal(input)
 }
 
+// Encode writes the collector as JSON to w. The errors are written one by one
+// and the output is flushed periodically, so large collectors are not built
+// in memory. The collector is read only, so the errors appended to it while
+// it's being encoded are not written.
+func (errs ErrorCollector) Encode(w io.Writer) error {
+	return NewEncoder(w).Encode(errs)
+}
+
+// MarshalXML marshals the error as xml
+func (errs ErrorCollector) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	element := xml.StartElement{Name: xml.Name{Local: "Error"}}
+
+	for _, err := range errs {
+		element := element
+
+		if item, ok := err.(*keyed); ok {
+			element.Attr = []xml.Attr{{Name: xml.Name{Local: "Key"}, Value: item.key}}
+			err = item.err
+		}
+
+		var value interface{} = err.Error()
+
+		if _, ok := err.(xml.Marshaler); ok {
+			value = err
+		}
+
+		if err := encoder.EncodeElement(value, element); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
 // Format the error as string
 func (errs ErrorCollector) Format(state fmt.State, verb rune) {
 	switch verb {
+	case 'j':
+		formatJSON(state, errs)
 	case 's':
 		fallthrough
 	case 'v':
@@ -429,11 +1108,16 @@ func (errs ErrorCollector) Format(state fmt.State, verb rune) {
 }
 
 func (errs ErrorCollector) formatBullet(state fmt.State, verb rune) {
+	if positions.Load() {
+		errs.tree(state, "")
+		return
+	}
+
 	count := len(errs)
 
 	for index, err := range errs {
 		fmt.Fprint(state, " --- ")
-		fmt.Fprintf(state, "%v", err)
+		fmt.Fprint(state, format.Bullet(fmt.Sprintf("%v", err)))
 
 		if index < count-1 {
 			fmt.Fprint(state, "\n")
@@ -455,26 +1139,27 @@ func (errs ErrorCollector) formatSlice(state fmt.State, verb rune) {
 	fmt.Fprint(state, "]")
 }
 
-// Is reports whether any error in err's chain matches target.
-//
-// The chain consists of err itself followed by the sequence of errors obtained by
-// repeatedly calling Unwrap.
-//
-// An error is considered to match a target if it is equal to that target or if
-// it implements a method Is(error) bool such that Is(target) returns true.
+// Is reports whether the target is a collector with the same errors as
+// reported by EqualErrors. The other targets are matched against the errors
+// of the collector by errors.Is, which traverses them via Unwrap.
 func (errs ErrorCollector) Is(target error) bool {
-	items, ok := target.(ErrorCollector)
-
-	if !ok {
-		items = ErrorCollector{target}
+	if items, ok := target.(ErrorCollector); ok {
+		return EqualErrors(errs, items)
 	}
 
-	if len(errs) != len(items) {
+	return false
+}
+
+// EqualErrors reports whether the collectors have the same length and every
+// error of the first one matches the error at the same position in the second
+// one as reported by errors.Is.
+func EqualErrors(errs, target ErrorCollector) bool {
+	if len(errs) != len(target) {
 		return false
 	}
 
 	for index, child := range errs {
-		if !errors.Is(child, items[index]) {
+		if !errors.Is(child, target[index]) {
 			return false
 		}
 	}
@@ -482,27 +1167,49 @@ func (errs ErrorCollector) Is(target error) bool {
 	return true
 }
 
-// As finds the first error in err's chain that matches target, and if so, sets
-// target to that error value and returns true.
-//
-// The chain consists of err itself followed by the sequence of errors obtained by
-// repeatedly calling Unwrap.
-//
-// An error matches target if the error's concrete value is assignable to the value
-// pointed to by target, or if the error has a method As(interface{}) bool such that
-// As(target) returns true. In the latter case, the As method is responsible for
-// setting target.
-//
-// As will panic if target is not a non-nil pointer to either a type that implements
-// error, or to any interface type. As returns false if err is nil.
-func (errs ErrorCollector) As(err interface{}) bool {
-	for _, child := range errs {
-		if errors.As(child, err) {
-			return true
+// ApproxSize returns an estimation of the collector size in bytes once it's
+// serialized.
+func (errs ErrorCollector) ApproxSize() int {
+	size := 0
+
+	for _, err := range errs {
+		size += approxSize(err) + overhead
+	}
+
+	return size
+}
+
+// Dedup returns a copy of the collector without the errors that are the same
+// as a preceding error. The flaw errors are compared with SameAs, the other
+// errors by their message.
+func (errs ErrorCollector) Dedup() ErrorCollector {
+	type Identifier interface {
+		SameAs(error) bool
+	}
+
+	items := ErrorCollector{}
+
+	for _, err := range errs {
+		duplicate := false
+
+		for _, item := range items {
+			if identifier, ok := item.(Identifier); ok {
+				duplicate = identifier.SameAs(err)
+			} else {
+				duplicate = item.Error() == err.Error()
+			}
+
+			if duplicate {
+				break
+			}
+		}
+
+		if !duplicate {
+			items = append(items, err)
 		}
 	}
 
-	return false
+	return items
 }
 
 // Wrap appends an error to the slice
@@ -510,16 +1217,18 @@ func (errs *ErrorCollector) Wrap(err error) {
 	*errs = append(*errs, err)
 }
 
-// Unwrap unwraps the underlying error it's only one
-func (errs ErrorCollector) Unwrap() error {
-	count := len(errs)
+// Unwrap returns the errors of the collector, so errors.Is and errors.As
+// match any error in the collector's tree. The nil errors are skipped.
+func (errs ErrorCollector) Unwrap() []error {
+	var items []error
 
-	switch {
-	case count == 1:
-		return errs[0]
-	default:
-		return nil
+	for _, err := range errs {
+		if err != nil {
+			items = append(items, err)
+		}
 	}
+
+	return items
 }
 
 // Code returns the code from an error
@@ -587,6 +1296,89 @@ func Details(err error) []string {
 	return []string{}
 }
 
+// User returns the id of the user that the error occurred for
+func User(err error) string {
+	type Userer interface {
+		User() string
+	}
+
+	if userer, ok := err.(Userer); ok {
+		return userer.User()
+	}
+
+	return ""
+}
+
+// Tenant returns the id of the tenant that the error occurred for
+func Tenant(err error) string {
+	type Tenanter interface {
+		Tenant() string
+	}
+
+	if tenanter, ok := err.(Tenanter); ok {
+		return tenanter.Tenant()
+	}
+
+	return ""
+}
+
+// Runbook returns the url of the runbook for the error
+func Runbook(err error) string {
+	type Runbooker interface {
+		Runbook() string
+	}
+
+	if runbooker, ok := err.(Runbooker); ok {
+		return runbooker.Runbook()
+	}
+
+	return ""
+}
+
+// IncidentKey returns the key of the incident that the error belongs to
+func IncidentKey(err error) string {
+	type IncidentKeyer interface {
+		IncidentKey() string
+	}
+
+	if keyer, ok := err.(IncidentKeyer); ok {
+		return keyer.IncidentKey()
+	}
+
+	return ""
+}
+
+// FallbackUsed returns the name of the fallback that has served the request
+func FallbackUsed(err error) string {
+	type FallbackUser interface {
+		FallbackUsed() string
+	}
+
+	if user, ok := err.(FallbackUser); ok {
+		return user.FallbackUsed()
+	}
+
+	return ""
+}
+
+// Fingerprint returns the error's fingerprint. The fingerprint of an error
+// that does not have one is a hash of its type and message.
+func Fingerprint(err error) string {
+	type Fingerprinter interface {
+		Fingerprint() string
+	}
+
+	if fingerprinter, ok := err.(Fingerprinter); ok {
+		return fingerprinter.Fingerprint()
+	}
+
+	if err == nil {
+		return ""
+	}
+
+	return fingerprint(fmt.Sprintf("%T", err), err.Error())
+}
+
 // Context returns the error's context
 func Context(err error) Map {
 	type Contexter interface {
@@ -2,10 +2,15 @@ package flaw_test
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/format"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -23,6 +28,52 @@ var _ = Describe("Error", func() {
 		Expect(err.Unwrap()).To(MatchError("oh no"))
 	})
 
+	Describe("Wrap", func() {
+		It("returns the flaw error as it is", func() {
+			errx := flaw.Errorf("oh no")
+			Expect(flaw.Wrap(errx)).To(BeIdenticalTo(errx))
+		})
+
+		Context("when the chain contains a flaw error", func() {
+			It("creates a wrapper that inherits the code and the status", func() {
+				cause := flaw.Errorf("user not found").WithCode(5).WithStatus(404)
+
+				err := flaw.Wrap(fmt.Errorf("loading profile: %w", cause))
+				Expect(err).NotTo(BeIdenticalTo(cause))
+				Expect(err.Code()).To(Equal(5))
+				Expect(err.Status()).To(Equal(404))
+				Expect(err).To(MatchError("code: 5 cause: loading profile: code: 5 message: user not found"))
+				Expect(errors.Is(err, cause)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("WrapAll", func() {
+		It("returns nil when all errors are nil", func() {
+			Expect(flaw.WrapAll()).To(BeNil())
+			Expect(flaw.WrapAll(nil, nil)).To(BeNil())
+		})
+
+		It("wraps the single error", func() {
+			err := flaw.WrapAll(nil, fmt.Errorf("oh no"), nil)
+			Expect(err).To(BeAssignableToTypeOf(&flaw.Error{}))
+			Expect(err).To(MatchError("cause: oh no"))
+
+			errx, _ := err.(*flaw.Error)
+			Expect(errx.StackTrace()[0].File).To(HaveSuffix("error_test.go"))
+		})
+
+		It("returns the single flaw error", func() {
+			errx := flaw.Errorf("oh no")
+			Expect(flaw.WrapAll(errx, nil)).To(BeIdenticalTo(errx))
+		})
+
+		It("collects the errors", func() {
+			err := flaw.WrapAll(fmt.Errorf("oh no"), nil, fmt.Errorf("oh yes"))
+			Expect(err).To(Equal(flaw.ErrorCollector{fmt.Errorf("oh no"), fmt.Errorf("oh yes")}))
+		})
+	})
+
 	Describe("WithCode", func() {
 		It("creates an error successfully", func() {
 			err := flaw.Errorf("oh no").WithCode(200)
@@ -81,6 +132,18 @@ var _ = Describe("Error", func() {
 			Expect(flaw.Details(err)).To(ContainElement("some more details"))
 		})
 
+		It("skips the duplicated details", func() {
+			err := flaw.Errorf("oh no").
+				WithDetails("the user might be deleted", "the id is invalid", "the user might be deleted").
+				WithDetails("the id is invalid", "the user is locked")
+
+			Expect(err.Details()).To(Equal([]string{
+				"the user might be deleted",
+				"the id is invalid",
+				"the user is locked",
+			}))
+		})
+
 		Context("when the error does not have details", func() {
 			It("returns no details", func() {
 				Expect(flaw.Details(fmt.Errorf("oh no"))).To(BeEmpty())
@@ -116,6 +179,17 @@ var _ = Describe("Error", func() {
 			})
 		})
 
+		It("copies the context", func() {
+			context := flaw.Map{"user": "root"}
+			err := flaw.Errorf("oh no").WithContext(context)
+			context["user"] = "admin"
+
+			Expect(err.Context()).To(HaveKeyWithValue("user", "root"))
+
+			err.Context()["user"] = "admin"
+			Expect(err.Context()).To(HaveKeyWithValue("user", "root"))
+		})
+
 		Context("when the context is nil", func() {
 			It("creates an error successfully", func() {
 				err := flaw.Wrap(fmt.Errorf("oh no")).WithContext(nil)
@@ -124,6 +198,115 @@ var _ = Describe("Error", func() {
 		})
 	})
 
+	Describe("WithArgs", func() {
+		It("creates an error successfully", func() {
+			err := flaw.Errorf("oh no").WithArgs("root", 42, []int{1, 2})
+			Expect(flaw.Context(err)).To(HaveKeyWithValue("error_args", ConsistOf("root", "42", "[1 2]")))
+			Expect(fmt.Sprintf("%v", err)).To(Equal("message: oh no"))
+			Expect(fmt.Sprintf("%+v", err)).To(ContainSubstring("    args: \n           --- root\n           --- 42\n"))
+		})
+
+		It("truncates the large arguments", func() {
+			err := flaw.Errorf("oh no").WithArgs(strings.Repeat("a", 1000))
+
+			args, ok := flaw.Context(err)["error_args"].(format.StringSlice)
+			Expect(ok).To(BeTrue())
+			Expect(args[0]).To(HaveLen(flaw.MaxArgSize))
+			Expect(args[0]).To(HaveSuffix("..."))
+		})
+	})
+
+	Describe("WithUser", func() {
+		It("creates an error successfully", func() {
+			err := flaw.Errorf("oh no").WithUser("root")
+			Expect(flaw.User(err)).To(Equal("root"))
+			Expect(flaw.Context(err)).To(HaveKeyWithValue("error_user", "root"))
+		})
+
+		Context("when the error does not have user", func() {
+			It("returns an empty user", func() {
+				Expect(flaw.User(fmt.Errorf("oh no"))).To(BeEmpty())
+				Expect(flaw.Context(flaw.Errorf("oh no"))).NotTo(HaveKey("error_user"))
+			})
+		})
+	})
+
+	Describe("WithTenant", func() {
+		It("creates an error successfully", func() {
+			err := flaw.Errorf("oh no").WithTenant("acme")
+			Expect(flaw.Tenant(err)).To(Equal("acme"))
+			Expect(flaw.Context(err)).To(HaveKeyWithValue("error_tenant", "acme"))
+		})
+
+		Context("when the error does not have tenant", func() {
+			It("returns an empty tenant", func() {
+				Expect(flaw.Tenant(fmt.Errorf("oh no"))).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("WithRunbook", func() {
+		It("creates an error successfully", func() {
+			err := flaw.Errorf("oh no").WithRunbook("https://runbooks.example.com/db")
+			Expect(flaw.Runbook(err)).To(Equal("https://runbooks.example.com/db"))
+			Expect(flaw.Context(err)).To(HaveKeyWithValue("error_runbook", "https://runbooks.example.com/db"))
+		})
+
+		Context("when the error does not have runbook", func() {
+			It("returns an empty runbook", func() {
+				Expect(flaw.Runbook(fmt.Errorf("oh no"))).To(BeEmpty())
+				Expect(flaw.Context(flaw.Errorf("oh no"))).NotTo(HaveKey("error_runbook"))
+			})
+		})
+	})
+
+	Describe("WithIncidentKey", func() {
+		It("creates an error successfully", func() {
+			err := flaw.Errorf("oh no").WithIncidentKey("db-down")
+			Expect(flaw.IncidentKey(err)).To(Equal("db-down"))
+			Expect(flaw.Context(err)).To(HaveKeyWithValue("error_incident_key", "db-down"))
+		})
+
+		Context("when the error does not have incident key", func() {
+			It("returns an empty incident key", func() {
+				Expect(flaw.IncidentKey(fmt.Errorf("oh no"))).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ApproxSize", func() {
+		It("returns the size of the error", func() {
+			err := flaw.Errorf("failed")
+			Expect(err.ApproxSize()).To(BeNumerically(">", len("failed")))
+		})
+
+		It("includes the context, details and cause", func() {
+			err := flaw.Errorf("failed")
+			size := err.ApproxSize()
+
+			err = err.WithDetails("some more details").
+				WithContext(flaw.Map{"query": "SELECT * FROM users"}).
+				WithError(fmt.Errorf("oh no"))
+
+			Expect(err.ApproxSize()).To(BeNumerically(">", size+len("some more details")+len("SELECT * FROM users")))
+		})
+
+		It("includes the attachments", func() {
+			err := flaw.Errorf("failed")
+			size := err.ApproxSize()
+
+			err = err.WithAttachment("dump.txt", make([]byte, 3000), "text/plain")
+			Expect(err.ApproxSize()).To(BeNumerically(">=", size+4000+len("dump.txt")+len("text/plain")))
+		})
+
+		It("is close to the size of the payload", func() {
+			err := flaw.Errorf("failed").WithContext(flaw.Map{"query": "SELECT * FROM users"})
+
+			data, _ := json.Marshal(err)
+			Expect(err.ApproxSize()).To(BeNumerically(">=", len(data)/2))
+		})
+	})
+
 	Describe("Format", func() {
 		It("prints the error successfully", func() {
 			err := flaw.Errorf("failed").WithCode(404).WithError(fmt.Errorf("oh no"))
@@ -135,6 +318,106 @@ var _ = Describe("Error", func() {
 				err := flaw.Errorf("failed").WithCode(404).WithError(fmt.Errorf("oh no"))
 				Expect(fmt.Sprintf("%+v", err)).To(HavePrefix("    code: 404\n message: failed\n   cause: oh no\n   stack: \n"))
 			})
+
+			It("indents the multi-line values", func() {
+				err := flaw.Errorf("failed\nbadly").WithDetails("oh\tno", "oh\nyes")
+				Expect(fmt.Sprintf("%+v", err)).To(HavePrefix(" message: failed\n          badly\n details: \n           --- oh\\tno\n           --- oh\n               yes\n   stack: \n"))
+			})
+
+			It("uses the width as minimal title width", func() {
+				err := flaw.Errorf("failed").WithCode(404)
+				Expect(fmt.Sprintf("%+12v", err)).To(HavePrefix("       code: 404\n    message: failed\n"))
+			})
+		})
+
+		Context("when the status text printing is used", func() {
+			It("prints the error successfully", func() {
+				err := flaw.Errorf("failed").WithCode(5).WithStatus(404)
+				Expect(fmt.Sprintf("%#v", err)).To(Equal("code: 5 status: 404 Not Found message: failed"))
+			})
+		})
+
+		Context("when the json printing is used", func() {
+			It("prints the error successfully", func() {
+				err := flaw.Errorf("failed").WithCode(404).WithError(fmt.Errorf("oh no"))
+				Expect(fmt.Sprintf("%j", err)).To(Equal(`{"error_cause":"oh no","error_code":404,"error_message":"failed"}`))
+			})
+
+			It("prints the error with status text successfully", func() {
+				err := flaw.Errorf("failed").WithCode(5).WithStatus(404)
+				Expect(fmt.Sprintf("%#j", err)).To(Equal(`{"error_code":5,"error_code_text":"NotFound","error_message":"failed","error_status":404,"error_status_text":"Not Found"}`))
+			})
+
+			It("prints the indented error successfully", func() {
+				err := flaw.Errorf("failed").WithCode(404)
+				Expect(fmt.Sprintf("%+j", err)).To(Equal("{\n  \"error_code\": 404,\n  \"error_message\": \"failed\"\n}"))
+			})
+		})
+	})
+
+	Context("when the cause is a collector", func() {
+		var errx *flaw.Error
+
+		BeforeEach(func() {
+			errs := flaw.ErrorCollector{}
+			errs = append(errs, fmt.Errorf("oh no"))
+			errs = append(errs, flaw.Errorf("oh yes"))
+
+			errx = flaw.Errorf("failed").WithError(errs)
+		})
+
+		It("prints the error successfully", func() {
+			Expect(fmt.Sprintf("%+v", errx)).To(HavePrefix(" message: failed\n   cause: \n           --- oh no\n           --- message: oh yes\n   stack: \n"))
+		})
+
+		It("marshals the error as json successfully", func() {
+			data, err := json.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`{"error_cause":["oh no",{"error_message":"oh yes"}],"error_message":"failed"}`))
+		})
+
+		It("marshals the error as xml successfully", func() {
+			data, err := xml.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("<ErrorCause><Error>oh no</Error><Error><ErrorMessage>oh yes</ErrorMessage></Error></ErrorCause>"))
+		})
+
+		It("returns the context successfully", func() {
+			Expect(errx.Context()).To(HaveKeyWithValue("error_cause", HaveLen(2)))
+		})
+	})
+
+	Describe("MarshalXML", func() {
+		It("marshals the details as items", func() {
+			errx := flaw.Errorf("oh no").WithDetails("a", "b")
+
+			data, err := xml.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("<ErrorDetails><Item>a</Item><Item>b</Item></ErrorDetails>"))
+		})
+
+		It("passes the raw json through as text", func() {
+			errx := flaw.Errorf("oh no").WithContext(flaw.Map{"response": json.RawMessage(`{"id":42}`)})
+
+			data, err := xml.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("<Response>{&#34;id&#34;:42}</Response>"))
+		})
+
+		It("sanitizes the invalid element names", func() {
+			errx := flaw.Errorf("oh no").WithContext(flaw.Map{"1 invalid:name": "value"})
+
+			data, err := xml.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("<__Invalid_Name>value</__Invalid_Name>"))
+		})
+
+		It("orders the elements by their keys", func() {
+			errx := flaw.Errorf("oh no").WithCode(5).WithContext(flaw.Map{"user": "root", "id": 42, "db": flaw.Map{"b": 1, "a": 2}})
+
+			data, err := xml.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("<Error><Db><A>2</A><B>1</B></Db><ErrorCode>5</ErrorCode><ErrorMessage>oh no</ErrorMessage><Id>42</Id><User>root</User></Error>"))
 		})
 	})
 
@@ -160,6 +443,58 @@ var _ = Describe("Error", func() {
 			})
 		})
 	})
+
+	Describe("Fingerprint", func() {
+		const ErrNotFound = flaw.ErrorConstant("user %v not found")
+
+		It("returns the same fingerprint for the same errors", func() {
+			Expect(flaw.Fingerprint(ErrNotFound.With(1))).To(Equal(flaw.Fingerprint(ErrNotFound.With(2))))
+			Expect(flaw.Fingerprint(ErrNotFound.With(1))).NotTo(Equal(flaw.Fingerprint(ErrNotFound.With(1).WithCode(5))))
+			Expect(flaw.Fingerprint(flaw.Errorf("oh no"))).To(HaveLen(16))
+		})
+
+		It("returns the fingerprint of other errors", func() {
+			Expect(flaw.Fingerprint(fmt.Errorf("oh no"))).To(Equal(flaw.Fingerprint(fmt.Errorf("oh no"))))
+			Expect(flaw.Fingerprint(fmt.Errorf("oh no"))).NotTo(Equal(flaw.Fingerprint(fmt.Errorf("oh yes"))))
+			Expect(flaw.Fingerprint(nil)).To(BeEmpty())
+		})
+
+		It("includes the cause of the errors without a message", func() {
+			Expect(flaw.Fingerprint(flaw.Wrap(io.EOF))).To(Equal(flaw.Fingerprint(flaw.Wrap(io.EOF))))
+			Expect(flaw.Fingerprint(flaw.Wrap(io.EOF))).NotTo(Equal(flaw.Fingerprint(flaw.Wrap(io.ErrUnexpectedEOF))))
+		})
+	})
+
+	Describe("SameAs", func() {
+		const ErrNotFound = flaw.ErrorConstant("user %v not found")
+
+		It("matches the errors created from the same template", func() {
+			err := ErrNotFound.With(1).WithCode(5)
+			Expect(err.SameAs(ErrNotFound.With(2).WithCode(5))).To(BeTrue())
+			Expect(err.SameAs(ErrNotFound.With(1).WithCode(6))).To(BeFalse())
+		})
+
+		It("matches the errors with the same message", func() {
+			err := flaw.Errorf("oh no").WithContext(flaw.Map{"user": "root"})
+			Expect(err.SameAs(flaw.Errorf("oh no"))).To(BeTrue())
+			Expect(err.SameAs(flaw.Errorf("oh yes"))).To(BeFalse())
+		})
+
+		It("matches the errors without a message by their cause", func() {
+			Expect(flaw.Wrap(io.EOF).SameAs(flaw.Wrap(io.EOF))).To(BeTrue())
+			Expect(flaw.Wrap(io.EOF).SameAs(flaw.Wrap(io.ErrUnexpectedEOF))).To(BeFalse())
+		})
+
+		It("does not match the errors from different namespaces", func() {
+			err := flaw.NewNamespace("db").Errorf("oh no")
+			Expect(err.SameAs(flaw.NewNamespace("cache").Errorf("oh no"))).To(BeFalse())
+		})
+
+		It("does not match other errors", func() {
+			Expect(flaw.Errorf("oh no").SameAs(fmt.Errorf("oh no"))).To(BeFalse())
+			Expect(flaw.Errorf("oh no").SameAs(nil)).To(BeFalse())
+		})
+	})
 })
 
 var _ = Describe("ErrorCollection", func() {
@@ -188,6 +523,20 @@ var _ = Describe("ErrorCollection", func() {
 			Expect(errors.Is(errs, err)).To(BeTrue())
 		})
 
+		It("finds the wrapped error in the tree", func() {
+			err := fmt.Errorf("not found")
+
+			errs := flaw.ErrorCollector{
+				fmt.Errorf("oh no"),
+				flaw.ErrorCollector{
+					fmt.Errorf("oh yes"),
+					flaw.Errorf("cannot load").WithError(fmt.Errorf("repository: %w", err)),
+				},
+			}
+
+			Expect(errors.Is(errs, err)).To(BeTrue())
+		})
+
 		Context("when the target is collector", func() {
 			It("returns true", func() {
 				child := fmt.Errorf("oh no")
@@ -229,6 +578,19 @@ var _ = Describe("ErrorCollection", func() {
 		})
 	})
 
+	Describe("EqualErrors", func() {
+		It("compares the errors by their position", func() {
+			var (
+				a = fmt.Errorf("oh no")
+				b = fmt.Errorf("oh yes")
+			)
+
+			Expect(flaw.EqualErrors(flaw.ErrorCollector{a, b}, flaw.ErrorCollector{a, b})).To(BeTrue())
+			Expect(flaw.EqualErrors(flaw.ErrorCollector{a, b}, flaw.ErrorCollector{b, a})).To(BeFalse())
+			Expect(flaw.EqualErrors(flaw.ErrorCollector{a, b}, flaw.ErrorCollector{a})).To(BeFalse())
+		})
+	})
+
 	Describe("As", func() {
 		It("returns true", func() {
 			var err *flaw.Error
@@ -269,14 +631,14 @@ var _ = Describe("ErrorCollection", func() {
 	})
 
 	Describe("Unwrap", func() {
-		It("unwraps the first error", func() {
+		It("unwraps the error", func() {
 			errs := flaw.ErrorCollector{}
 			errs = append(errs, fmt.Errorf("oh no"))
-			Expect(errs.Unwrap()).To(MatchError("oh no"))
+			Expect(errs.Unwrap()).To(ConsistOf(MatchError("oh no")))
 		})
 
 		Context("when the collector is empty", func() {
-			It("unwraps the nil error", func() {
+			It("unwraps the nil errors", func() {
 				errs := flaw.ErrorCollector{}
 				Expect(errs.Unwrap()).To(BeNil())
 			})
@@ -284,14 +646,26 @@ var _ = Describe("ErrorCollection", func() {
 
 		Context("when the collector has more than one error", func() {
 			Describe("Unwrap", func() {
-				It("unwraps the errors as nil", func() {
+				It("unwraps all errors", func() {
 					errs := flaw.ErrorCollector{}
 					errs = append(errs, fmt.Errorf("oh no"))
+					errs = append(errs, nil)
 					errs = append(errs, fmt.Errorf("oh yes"))
-					Expect(errs.Unwrap()).To(BeNil())
+					Expect(errs.Unwrap()).To(ConsistOf(MatchError("oh no"), MatchError("oh yes")))
 				})
 			})
 		})
+
+		It("is traversed by errors.Is and errors.As", func() {
+			target := &os.PathError{Op: "open", Path: "/tmp", Err: os.ErrNotExist}
+			errs := flaw.ErrorCollector{nil, fmt.Errorf("oh no"), fmt.Errorf("failed: %w", target)}
+
+			var item *os.PathError
+			Expect(errors.As(errs, &item)).To(BeTrue())
+			Expect(item).To(BeIdenticalTo(target))
+			Expect(errors.Is(errs, os.ErrNotExist)).To(BeTrue())
+			Expect(errors.Is(errs, os.ErrExist)).To(BeFalse())
+		})
 	})
 
 	Describe("Format", func() {
@@ -328,6 +702,15 @@ var _ = Describe("ErrorCollection", func() {
 				Expect(fmt.Sprintf("%+v", errs)).To(Equal(" --- oh no\n --- oh yes"))
 			})
 		})
+
+		Context("when the %j format is used", func() {
+			It("prints the error successfully", func() {
+				errs := flaw.ErrorCollector{}
+				errs = append(errs, fmt.Errorf("oh no"))
+				errs = append(errs, flaw.Errorf("oh yes"))
+				Expect(fmt.Sprintf("%j", errs)).To(Equal(`["oh no",{"error_message":"oh yes"}]`))
+			})
+		})
 	})
 
 	Describe("MarshalJSON", func() {
@@ -351,11 +734,105 @@ var _ = Describe("ErrorCollection", func() {
 			})
 		})
 	})
+
+	Describe("Dedup", func() {
+		const ErrNotFound = flaw.ErrorConstant("user %v not found")
+
+		It("removes the duplicated errors", func() {
+			errs := flaw.ErrorCollector{
+				ErrNotFound.With(1),
+				fmt.Errorf("oh no"),
+				ErrNotFound.With(2),
+				fmt.Errorf("oh no"),
+				flaw.Errorf("oh yes"),
+			}
+
+			items := errs.Dedup()
+			Expect(items).To(HaveLen(3))
+			Expect(items[0]).To(BeIdenticalTo(errs[0]))
+			Expect(items[1]).To(MatchError("oh no"))
+			Expect(items[2]).To(MatchError("message: oh yes"))
+			Expect(errs).To(HaveLen(5))
+		})
+
+		It("keeps the wrapped errors of different causes", func() {
+			errs := flaw.ErrorCollector{flaw.Wrap(io.EOF), flaw.Wrap(io.ErrUnexpectedEOF), flaw.Wrap(io.EOF)}
+			Expect(errs.Dedup()).To(HaveLen(2))
+		})
+	})
 })
 
+// ErrUserNotFound is the constant of the generated constructor
+const ErrUserNotFound = flaw.ErrorConstant("user %q not found")
+
+// UserNotFound mimics a constructor generated by flawgen
+func UserNotFound(args ...interface{}) *flaw.Error {
+	return ErrUserNotFound.WithSkip(1, args...)
+}
+
 var _ = Describe("ErrorConstant", func() {
 	It("creates a error constant successfully", func() {
 		const err = flaw.ErrorConstant("EOF")
 		Expect(err).To(MatchError("EOF"))
 	})
+
+	Describe("With", func() {
+		const ErrNotFound = flaw.ErrorConstant("user %q not found")
+
+		It("creates an error successfully", func() {
+			err := ErrNotFound.With("root")
+			Expect(err).To(MatchError(`message: user "root" not found`))
+			Expect(err.StackTrace()).NotTo(BeEmpty())
+		})
+
+		It("matches the constant", func() {
+			err := ErrNotFound.With("root").WithCode(404)
+			Expect(errors.Is(err, ErrNotFound)).To(BeTrue())
+			Expect(errors.Is(flaw.Wrap(err), ErrNotFound)).To(BeTrue())
+		})
+
+		Context("when the constant is different", func() {
+			It("does not match the constant", func() {
+				const ErrExists = flaw.ErrorConstant("user %q exists")
+
+				err := ErrNotFound.With("root")
+				Expect(errors.Is(err, ErrExists)).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("WithSkip", func() {
+		It("skips the frames of the constructor", func() {
+			err := UserNotFound("root")
+			Expect(err).To(MatchError(`message: user "root" not found`))
+			Expect(errors.Is(err, ErrUserNotFound)).To(BeTrue())
+			Expect(err.StackTrace()[0].Function).NotTo(HaveSuffix("UserNotFound"))
+			Expect(err.StackTrace()[0].File).To(HaveSuffix("error_test.go"))
+		})
+	})
+
+	Describe("Is", func() {
+		const ErrNotFound = flaw.ErrorConstant("not found")
+
+		It("matches an error with the same message", func() {
+			Expect(errors.Is(ErrNotFound, fmt.Errorf("not found"))).To(BeTrue())
+			Expect(errors.Is(ErrNotFound, fmt.Errorf("exists"))).To(BeFalse())
+			Expect(errors.Is(ErrNotFound, nil)).To(BeFalse())
+		})
+
+		It("matches a flaw error with the same message", func() {
+			err := flaw.Errorf("not found").WithCode(404)
+			Expect(errors.Is(err, ErrNotFound)).To(BeTrue())
+			Expect(errors.Is(flaw.Wrap(err), ErrNotFound)).To(BeTrue())
+			Expect(errors.Is(flaw.Errorf("exists"), ErrNotFound)).To(BeFalse())
+		})
+
+		It("matches the wrapped constant", func() {
+			err := flaw.Wrap(ErrNotFound).WithMessage("user not found")
+			Expect(errors.Is(err, ErrNotFound)).To(BeTrue())
+
+			err = flaw.Errorf("failed").WithError(fmt.Errorf("query: %w", ErrNotFound))
+			Expect(errors.Is(err, ErrNotFound)).To(BeTrue())
+		})
+	})
 })
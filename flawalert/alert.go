@@ -0,0 +1,72 @@
+// Package flawalert builds the alerting payloads of PagerDuty and Opsgenie
+// from flaw errors.
+package flawalert
+
+import (
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/phogolabs/flaw"
+)
+
+// the severities of the alerts
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Severity returns the severity of the error. It's the severity set by
+// flaw.Escalate or WithSeverity if present. Otherwise it's based on the
+// status: the errors without a status and the 5xx errors are errors, the 4xx
+// errors are warnings and the rest is info.
+func Severity(err error) string {
+	if severity := flaw.SeverityOf(err); severity != 0 {
+		return severity.String()
+	}
+
+	switch status := flaw.Status(err); {
+	case status == 0 || status >= http.StatusInternalServerError:
+		return SeverityError
+	case status >= http.StatusBadRequest:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// DedupKey returns the key that groups the alerts of the same error. It's the
+// incident key of the error if present or its fingerprint otherwise.
+func DedupKey(err error) string {
+	if key := flaw.IncidentKey(err); key != "" {
+		return key
+	}
+
+	return flaw.Fingerprint(err)
+}
+
+// summary returns the one line summary of the error truncated to the given
+// size without splitting a rune
+func summary(err error, size int) string {
+	text := flaw.Summary(err)
+
+	if len(text) <= size {
+		return text
+	}
+
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+
+	return text[:size]
+}
+
+// namespace returns the namespace of a flaw error
+func namespace(err error) string {
+	if errx, ok := err.(*flaw.Error); ok {
+		return errx.Namespace()
+	}
+
+	return ""
+}
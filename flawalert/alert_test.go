@@ -0,0 +1,113 @@
+package flawalert_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawalert"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Severity", func() {
+	It("returns the severity by the status", func() {
+		Expect(flawalert.Severity(fmt.Errorf("oh no"))).To(Equal(flawalert.SeverityError))
+		Expect(flawalert.Severity(flaw.Errorf("oh no").WithStatus(503))).To(Equal(flawalert.SeverityError))
+		Expect(flawalert.Severity(flaw.Errorf("oh no").WithStatus(404))).To(Equal(flawalert.SeverityWarning))
+		Expect(flawalert.Severity(flaw.Errorf("oh no").WithStatus(302))).To(Equal(flawalert.SeverityInfo))
+	})
+
+	It("returns the severity of the error", func() {
+		err := flaw.Escalate(flaw.Errorf("oh no").WithStatus(404), flaw.SeverityCritical, "retries exhausted")
+		Expect(flawalert.Severity(err)).To(Equal(flawalert.SeverityCritical))
+	})
+})
+
+var _ = Describe("DedupKey", func() {
+	It("returns the fingerprint", func() {
+		err := flaw.Errorf("oh no").WithCode(5)
+		Expect(flawalert.DedupKey(err)).To(Equal(err.Fingerprint()))
+	})
+
+	It("returns the incident key", func() {
+		err := flaw.Errorf("oh no").WithIncidentKey("db-down")
+		Expect(flawalert.DedupKey(err)).To(Equal("db-down"))
+	})
+})
+
+var _ = Describe("PagerDuty", func() {
+	var adapter *flawalert.PagerDuty
+
+	BeforeEach(func() {
+		adapter = &flawalert.PagerDuty{
+			RoutingKey: "key",
+			Source:     "users-api",
+		}
+	})
+
+	It("creates the event", func() {
+		err := flaw.NewNamespace("db").Errorf("connection lost").
+			WithRunbook("https://runbooks.example.com/db").
+			WithContext(flaw.Map{"host": "db-1"}).
+			WithError(fmt.Errorf("i/o timeout"))
+
+		event := adapter.Event(err)
+		Expect(event.RoutingKey).To(Equal("key"))
+		Expect(event.EventAction).To(Equal("trigger"))
+		Expect(event.DedupKey).To(Equal(err.Fingerprint()))
+		Expect(event.Payload.Summary).To(Equal("connection lost: i/o timeout"))
+		Expect(event.Payload.Source).To(Equal("users-api"))
+		Expect(event.Payload.Severity).To(Equal("error"))
+		Expect(event.Payload.Component).To(Equal("db"))
+		Expect(event.Payload.CustomDetails).To(HaveKeyWithValue("host", "db-1"))
+		Expect(event.Links).To(ConsistOf(flawalert.PagerDutyLink{Href: "https://runbooks.example.com/db", Text: "Runbook"}))
+	})
+
+	It("uses the severity function", func() {
+		adapter.Severity = func(err error) string {
+			return flawalert.SeverityCritical
+		}
+
+		Expect(adapter.Event(fmt.Errorf("oh no")).Payload.Severity).To(Equal("critical"))
+	})
+
+	It("truncates the summary", func() {
+		event := adapter.Event(fmt.Errorf("%s", strings.Repeat("é", 1024)))
+		Expect(event.Payload.Summary).To(HaveLen(1024))
+	})
+})
+
+var _ = Describe("Opsgenie", func() {
+	var adapter *flawalert.Opsgenie
+
+	BeforeEach(func() {
+		adapter = &flawalert.Opsgenie{
+			Source: "users-api",
+			Tags:   []string{"users"},
+		}
+	})
+
+	It("creates the alert", func() {
+		err := flaw.Errorf("user not found").
+			WithStatus(404).
+			WithRunbook("https://runbooks.example.com/users").
+			WithContext(flaw.Map{"user_id": 42})
+
+		alert := adapter.Alert(err)
+		Expect(alert.Message).To(Equal("user not found"))
+		Expect(alert.Alias).To(Equal(err.Fingerprint()))
+		Expect(alert.Description).To(ContainSubstring("user not found"))
+		Expect(alert.Source).To(Equal("users-api"))
+		Expect(alert.Priority).To(Equal("P3"))
+		Expect(alert.Tags).To(ConsistOf("users"))
+		Expect(alert.Details).To(HaveKeyWithValue("user_id", "42"))
+		Expect(alert.Details).To(HaveKeyWithValue("runbook", "https://runbooks.example.com/users"))
+	})
+
+	It("truncates the message", func() {
+		alert := adapter.Alert(fmt.Errorf("%s", strings.Repeat("a", 200)))
+		Expect(alert.Message).To(HaveLen(130))
+	})
+})
@@ -0,0 +1,71 @@
+package flawalert
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+)
+
+const keyRunbook = "runbook"
+
+// maxMessageSize is the maximum size of the alert message
+const maxMessageSize = 130
+
+// priorities maps the severities to the Opsgenie priorities
+var priorities = map[string]string{
+	SeverityCritical: "P1",
+	SeverityError:    "P2",
+	SeverityWarning:  "P3",
+	SeverityInfo:     "P5",
+}
+
+// OpsgenieAlert is an alert of the Opsgenie Alert API
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// Opsgenie builds the Opsgenie alerts of the errors
+type Opsgenie struct {
+	// Source is the host or the service where the errors occur
+	Source string
+	// Tags are the tags of the alerts
+	Tags []string
+	// Severity returns the severity of the error. If nil, Severity is used.
+	Severity func(err error) string
+}
+
+// Alert returns the alert of the error. The error's context is put in the
+// details, its fingerprint is the alias and its runbook is in the "runbook"
+// detail.
+func (o *Opsgenie) Alert(err error) *OpsgenieAlert {
+	severity := o.Severity
+	if severity == nil {
+		severity = Severity
+	}
+
+	alert := &OpsgenieAlert{
+		Message:     summary(err, maxMessageSize),
+		Alias:       DedupKey(err),
+		Description: fmt.Sprintf("%+v", err),
+		Source:      o.Source,
+		Priority:    priorities[severity(err)],
+		Tags:        o.Tags,
+		Details:     map[string]string{},
+	}
+
+	for key, value := range flaw.Context(err) {
+		alert.Details[key] = fmt.Sprint(value)
+	}
+
+	if runbook := flaw.Runbook(err); runbook != "" {
+		alert.Details[keyRunbook] = runbook
+	}
+
+	return alert
+}
@@ -0,0 +1,69 @@
+package flawalert
+
+import "github.com/phogolabs/flaw"
+
+// maxSummarySize is the maximum size of the event summary
+const maxSummarySize = 1024
+
+// PagerDutyEvent is an event of the PagerDuty Events API v2
+type PagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key,omitempty"`
+	Payload     PagerDutyPayload `json:"payload"`
+	Links       []PagerDutyLink  `json:"links,omitempty"`
+}
+
+// PagerDutyPayload is the payload of a PagerDuty event
+type PagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Component     string                 `json:"component,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// PagerDutyLink is a link attached to a PagerDuty event
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
+
+// PagerDuty builds the PagerDuty events of the errors
+type PagerDuty struct {
+	// RoutingKey is the integration key of the PagerDuty service
+	RoutingKey string
+	// Source is the host or the service where the errors occur
+	Source string
+	// Severity returns the severity of the error. If nil, Severity is used.
+	Severity func(err error) string
+}
+
+// Event returns the trigger event of the error. The error's context is put in
+// the custom details, its fingerprint is the dedup key and its runbook is
+// linked.
+func (p *PagerDuty) Event(err error) *PagerDutyEvent {
+	severity := p.Severity
+	if severity == nil {
+		severity = Severity
+	}
+
+	event := &PagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    DedupKey(err),
+		Payload: PagerDutyPayload{
+			Summary:       summary(err, maxSummarySize),
+			Source:        p.Source,
+			Severity:      severity(err),
+			Component:     namespace(err),
+			CustomDetails: flaw.Context(err),
+		},
+	}
+
+	if runbook := flaw.Runbook(err); runbook != "" {
+		event.Links = append(event.Links, PagerDutyLink{Href: runbook, Text: "Runbook"})
+	}
+
+	return event
+}
@@ -0,0 +1,13 @@
+package flawalert_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlawalert(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flawalert Suite")
+}
@@ -0,0 +1,158 @@
+//go:build !flaw_nogrpc
+
+// Package flawconnect integrates flaw with connect-go.
+package flawconnect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"connectrpc.com/connect"
+	"github.com/phogolabs/flaw"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// HeaderStatus is the metadata key that carries the error status
+const HeaderStatus = "Flaw-Status"
+
+// statuses maps the connect codes to http statuses
+var statuses = map[connect.Code]int{
+	connect.CodeCanceled:           499,
+	connect.CodeUnknown:            http.StatusInternalServerError,
+	connect.CodeInvalidArgument:    http.StatusBadRequest,
+	connect.CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+	connect.CodeNotFound:           http.StatusNotFound,
+	connect.CodeAlreadyExists:      http.StatusConflict,
+	connect.CodePermissionDenied:   http.StatusForbidden,
+	connect.CodeResourceExhausted:  http.StatusTooManyRequests,
+	connect.CodeFailedPrecondition: http.StatusBadRequest,
+	connect.CodeAborted:            http.StatusConflict,
+	connect.CodeOutOfRange:         http.StatusBadRequest,
+	connect.CodeUnimplemented:      http.StatusNotImplemented,
+	connect.CodeInternal:           http.StatusInternalServerError,
+	connect.CodeUnavailable:        http.StatusServiceUnavailable,
+	connect.CodeDataLoss:           http.StatusInternalServerError,
+	connect.CodeUnauthenticated:    http.StatusUnauthorized,
+}
+
+// ToConnectError converts the error to connect error. The details are
+// converted to connect error details and the context to a struct detail.
+func ToConnectError(err error) *connect.Error {
+	var errc *connect.Error
+
+	if errors.As(err, &errc) {
+		return errc
+	}
+
+	var errx *flaw.Error
+
+	if !errors.As(err, &errx) {
+		return connect.NewError(connect.CodeUnknown, err)
+	}
+
+	state := errx.GRPCStatus()
+
+	errc = connect.NewError(connect.Code(state.Code()), errors.New(state.Message()))
+
+	for _, item := range state.Proto().GetDetails() {
+		message, err := item.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+
+		if detail, err := connect.NewErrorDetail(message); err == nil {
+			errc.AddDetail(detail)
+		}
+	}
+
+	if status := errx.Status(); status > 0 {
+		errc.Meta().Set(HeaderStatus, strconv.Itoa(status))
+	}
+
+	return errc
+}
+
+// FromConnectError converts a connect error to flaw error. The details are
+// converted as by flaw.FromGRPCStatus.
+func FromConnectError(err *connect.Error) *flaw.Error {
+	state := &spb.Status{
+		Code:    int32(err.Code()),
+		Message: err.Message(),
+	}
+
+	for _, detail := range err.Details() {
+		state.Details = append(state.Details, &anypb.Any{
+			TypeUrl: "type.googleapis.com/" + detail.Type(),
+			Value:   detail.Bytes(),
+		})
+	}
+
+	status, ok := statuses[err.Code()]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	if value, err := strconv.Atoi(err.Meta().Get(HeaderStatus)); err == nil {
+		status = value
+	}
+
+	return flaw.FromGRPCStatus(grpcstatus.FromProto(state)).WithStatus(status)
+}
+
+var _ connect.Interceptor = &Interceptor{}
+
+// Interceptor converts the flaw errors returned by the handlers to connect
+// errors, and the connect errors received by the clients to flaw errors.
+type Interceptor struct{}
+
+// NewInterceptor creates a new interceptor
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// WrapUnary implements connect.Interceptor
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		response, err := next(ctx, request)
+
+		if err != nil {
+			if request.Spec().IsClient {
+				return response, i.client(err)
+			}
+
+			return response, ToConnectError(err)
+		}
+
+		return response, nil
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := next(ctx, conn); err != nil {
+			return ToConnectError(err)
+		}
+
+		return nil
+	}
+}
+
+func (i *Interceptor) client(err error) error {
+	var errc *connect.Error
+
+	if errors.As(err, &errc) {
+		return FromConnectError(errc)
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+//go:build !flaw_nogrpc
+
+package flawconnect_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawconnect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ToConnectError", func() {
+	It("converts the error successfully", func() {
+		errx := flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithDetails("the user might be deleted").
+			WithContext(flaw.Map{"user": "root"})
+
+		errc := flawconnect.ToConnectError(errx)
+		Expect(errc.Code()).To(Equal(connect.CodeNotFound))
+		Expect(errc.Message()).To(Equal("user not found"))
+		Expect(errc.Details()).To(HaveLen(2))
+		Expect(errc.Meta().Get(flawconnect.HeaderStatus)).To(Equal("404"))
+	})
+
+	Context("when the error is a connect error", func() {
+		It("returns the error", func() {
+			errc := connect.NewError(connect.CodeAborted, fmt.Errorf("oh no"))
+			Expect(flawconnect.ToConnectError(fmt.Errorf("failed: %w", errc))).To(BeIdenticalTo(errc))
+		})
+	})
+
+	Context("when the error is not a flaw error", func() {
+		It("converts the error as unknown", func() {
+			errc := flawconnect.ToConnectError(fmt.Errorf("oh no"))
+			Expect(errc.Code()).To(Equal(connect.CodeUnknown))
+			Expect(errc.Message()).To(Equal("oh no"))
+		})
+	})
+})
+
+var _ = Describe("FromConnectError", func() {
+	It("converts the error successfully", func() {
+		errx := flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(410).
+			WithDetails("the user might be deleted").
+			WithContext(flaw.Map{"user": "root"})
+
+		errx = flawconnect.FromConnectError(flawconnect.ToConnectError(errx))
+		Expect(errx.Code()).To(Equal(5))
+		Expect(errx.Status()).To(Equal(410))
+		Expect(errx.Message()).To(Equal("user not found"))
+		Expect(errx.Details()).To(Equal([]string{"the user might be deleted"}))
+		Expect(flaw.Context(errx)).To(HaveKeyWithValue("user", "root"))
+	})
+
+	It("converts the component successfully", func() {
+		errx := flaw.Errorf("card declined").WithComponent("payment-service/worker")
+
+		errx = flawconnect.FromConnectError(flawconnect.ToConnectError(errx))
+		Expect(errx.Component()).To(Equal("payment-service/worker"))
+	})
+
+	It("converts the pointer successfully", func() {
+		errx := flaw.Errorf("price is negative").WithCode(3).WithPointer("/items/3/price")
+
+		errx = flawconnect.FromConnectError(flawconnect.ToConnectError(errx))
+		Expect(errx.Pointer()).To(Equal("/items/3/price"))
+	})
+
+	Context("when the status is not present", func() {
+		It("maps the code to status", func() {
+			errx := flawconnect.FromConnectError(connect.NewError(connect.CodeUnavailable, fmt.Errorf("oh no")))
+			Expect(errx.Status()).To(Equal(503))
+		})
+	})
+})
+
+var _ = Describe("Interceptor", func() {
+	It("converts the handler errors", func() {
+		next := func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			return nil, flaw.Errorf("oh no").WithCode(3)
+		}
+
+		unary := flawconnect.NewInterceptor().WrapUnary(next)
+
+		_, err := unary(context.Background(), connect.NewRequest(&struct{}{}))
+
+		var errc *connect.Error
+		Expect(errors.As(err, &errc)).To(BeTrue())
+		Expect(errc.Code()).To(Equal(connect.CodeInvalidArgument))
+	})
+
+	It("converts the streaming handler errors", func() {
+		next := func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+			return flaw.Errorf("oh no").WithCode(5)
+		}
+
+		stream := flawconnect.NewInterceptor().WrapStreamingHandler(next)
+
+		var errc *connect.Error
+		Expect(errors.As(stream(context.Background(), nil), &errc)).To(BeTrue())
+		Expect(errc.Code()).To(Equal(connect.CodeNotFound))
+	})
+})
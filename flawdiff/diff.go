@@ -0,0 +1,177 @@
+// Package flawdiff compares two error catalogs, e.g. the catalogs of the old
+// and the new deploy of a service, and reports the added, the removed and the
+// changed errors. It's used to check the error contract of a service in the
+// release pipelines.
+package flawdiff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/phogolabs/flaw/catalog"
+)
+
+// Kind is the kind of a change
+type Kind string
+
+const (
+	// Added is the kind of an error that is added
+	Added Kind = "added"
+	// Removed is the kind of an error that is removed
+	Removed Kind = "removed"
+	// Changed is the kind of an error which field is changed
+	Changed Kind = "changed"
+)
+
+// Change represents a single difference between two catalogs
+type Change struct {
+	// Kind is the kind of the change
+	Kind Kind `json:"kind"`
+	// Name is the name of the error
+	Name string `json:"name"`
+	// Field is the name of the changed field, e.g. "code"
+	Field string `json:"field,omitempty"`
+	// Old is the old value of the field
+	Old interface{} `json:"old,omitempty"`
+	// New is the new value of the field
+	New interface{} `json:"new,omitempty"`
+}
+
+// Breaking reports whether the change breaks the clients that rely on the old
+// catalog. The removed errors and the changed codes and statuses are breaking.
+func (c Change) Breaking() bool {
+	switch c.Kind {
+	case Removed:
+		return true
+	case Changed:
+		return c.Field == "code" || c.Field == "status"
+	default:
+		return false
+	}
+}
+
+// String returns the change as text, e.g. "changed UserNotFound status: 404 -> 410"
+func (c Change) String() string {
+	if c.Kind == Changed {
+		return fmt.Sprintf("%s %s %s: %v -> %v", c.Kind, c.Name, c.Field, c.Old, c.New)
+	}
+
+	return fmt.Sprintf("%s %s", c.Kind, c.Name)
+}
+
+// Report represents the differences between two catalogs
+type Report struct {
+	// Changes are the changes ordered by the error name
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns the breaking changes
+func (r *Report) Breaking() []Change {
+	changes := []Change{}
+
+	for _, change := range r.Changes {
+		if change.Breaking() {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// Empty reports whether the catalogs are equal
+func (r *Report) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+// WriteTo writes the changes as text, one change per line. The breaking
+// changes are marked with "!".
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+
+	for _, change := range r.Changes {
+		mark := " "
+
+		if change.Breaking() {
+			mark = "!"
+		}
+
+		n, err := fmt.Fprintf(w, "%s %s\n", mark, change)
+		total += int64(n)
+
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// Compare compares the old and the new catalog. The codes, the statuses, the
+// messages and the documentation URLs of the errors are compared.
+func Compare(old, new *catalog.Catalog) *Report {
+	var (
+		report = &Report{Changes: []Change{}}
+		names  = make(map[string]struct{})
+	)
+
+	for _, entry := range old.Errors {
+		names[entry.Name] = struct{}{}
+	}
+
+	for _, entry := range new.Errors {
+		names[entry.Name] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(names))
+
+	for name := range names {
+		keys = append(keys, name)
+	}
+
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		prev, hasPrev := old.Lookup(name)
+		next, hasNext := new.Lookup(name)
+
+		switch {
+		case !hasPrev:
+			report.Changes = append(report.Changes, Change{Kind: Added, Name: name})
+		case !hasNext:
+			report.Changes = append(report.Changes, Change{Kind: Removed, Name: name})
+		default:
+			report.Changes = append(report.Changes, compare(prev, next)...)
+		}
+	}
+
+	return report
+}
+
+func compare(prev, next *catalog.Entry) []Change {
+	changes := []Change{}
+
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"code", prev.Code, next.Code},
+		{"status", prev.Status, next.Status},
+		{"message", prev.Message, next.Message},
+		{"docs_url", prev.DocsURL, next.DocsURL},
+	}
+
+	for _, field := range fields {
+		if field.old != field.new {
+			changes = append(changes, Change{
+				Kind:  Changed,
+				Name:  prev.Name,
+				Field: field.name,
+				Old:   field.old,
+				New:   field.new,
+			})
+		}
+	}
+
+	return changes
+}
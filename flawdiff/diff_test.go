@@ -0,0 +1,90 @@
+package flawdiff_test
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/phogolabs/flaw/catalog"
+	"github.com/phogolabs/flaw/flawdiff"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compare", func() {
+	load := func(text string) *catalog.Catalog {
+		errs, err := catalog.Load(strings.NewReader(text))
+		Expect(err).NotTo(HaveOccurred())
+		return errs
+	}
+
+	var report *flawdiff.Report
+
+	BeforeEach(func() {
+		prev := load(`
+errors:
+  - name: Conflict
+    code: 6
+    status: 409
+    message: conflict
+  - name: Expired
+    message: expired
+  - name: UserNotFound
+    code: 5
+    status: 404
+    message: user not found
+`)
+
+		next := load(`
+errors:
+  - name: Conflict
+    code: 6
+    status: 409
+    message: conflict
+  - name: Throttled
+    status: 429
+    message: throttled
+  - name: UserNotFound
+    code: 5
+    status: 410
+    message: user %q not found
+`)
+
+		report = flawdiff.Compare(prev, next)
+	})
+
+	It("reports the changes ordered by name", func() {
+		Expect(report.Empty()).To(BeFalse())
+		Expect(report.Changes).To(Equal([]flawdiff.Change{
+			{Kind: flawdiff.Removed, Name: "Expired"},
+			{Kind: flawdiff.Added, Name: "Throttled"},
+			{Kind: flawdiff.Changed, Name: "UserNotFound", Field: "status", Old: 404, New: 410},
+			{Kind: flawdiff.Changed, Name: "UserNotFound", Field: "message", Old: "user not found", New: "user %q not found"},
+		}))
+	})
+
+	It("returns the breaking changes", func() {
+		Expect(report.Breaking()).To(HaveLen(2))
+		Expect(report.Breaking()[0].Name).To(Equal("Expired"))
+		Expect(report.Breaking()[1].Field).To(Equal("status"))
+	})
+
+	It("writes the report", func() {
+		buffer := &bytes.Buffer{}
+
+		_, err := report.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`! removed Expired
+  added Throttled
+! changed UserNotFound status: 404 -> 410
+  changed UserNotFound message: user not found -> user %q not found
+`))
+	})
+
+	Context("when the catalogs are equal", func() {
+		It("returns an empty report", func() {
+			errs := load(`{"errors": [{"name": "Conflict", "message": "conflict"}]}`)
+			Expect(flawdiff.Compare(errs, errs).Empty()).To(BeTrue())
+		})
+	})
+})
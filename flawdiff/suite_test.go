@@ -0,0 +1,13 @@
+package flawdiff_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlawdiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flawdiff Suite")
+}
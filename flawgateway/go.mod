@@ -0,0 +1,26 @@
+module github.com/phogolabs/flaw/flawgateway
+
+go 1.21
+
+require (
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.16.0
+	github.com/onsi/ginkgo/v2 v2.7.0
+	github.com/onsi/gomega v1.24.2
+	github.com/phogolabs/flaw v0.0.0
+	google.golang.org/grpc v1.55.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	golang.org/x/net v0.30.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20230530153820-e85fd2cbaebc // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230530153820-e85fd2cbaebc // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/phogolabs/flaw => ../
@@ -0,0 +1,62 @@
+//go:build !flaw_nogrpc
+
+// Package flawgateway integrates flaw with grpc-gateway.
+package flawgateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/phogolabs/flaw"
+	"google.golang.org/grpc/status"
+)
+
+var _ runtime.ErrorHandlerFunc = ErrorHandler
+
+// ErrorHandler is a grpc-gateway error handler that renders the gRPC status
+// errors as flaw JSON payload. The status details and context are preserved.
+//
+//	mux := runtime.NewServeMux(runtime.WithErrorHandler(flawgateway.ErrorHandler))
+func ErrorHandler(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		errx   = Convert(err)
+		header = w.Header()
+	)
+
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			for _, value := range values {
+				header.Add(fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), value)
+			}
+		}
+	}
+
+	data, err := json.Marshal(errx)
+	if err != nil {
+		data = []byte(`{"error_message":"failed to marshal error message"}`)
+	}
+
+	header.Del("Trailer")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Type", "application/json")
+
+	w.WriteHeader(errx.Status())
+	w.Write(data)
+}
+
+// Convert converts a gRPC status error to flaw error as by
+// flaw.FromGRPCStatus. The status of the error is mapped from its code.
+func Convert(err error) *flaw.Error {
+	state := status.Convert(err)
+
+	errx := flaw.FromGRPCStatus(state)
+	if errx == nil {
+		// the status of a nil error is OK
+		errx = flaw.Errorf("%s", state.Message())
+	}
+
+	return errx.WithStatus(runtime.HTTPStatusFromCode(state.Code()))
+}
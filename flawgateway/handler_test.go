@@ -0,0 +1,90 @@
+//go:build !flaw_nogrpc
+
+package flawgateway_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawgateway"
+	"google.golang.org/grpc/metadata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorHandler", func() {
+	var (
+		ctx      context.Context
+		recorder *httptest.ResponseRecorder
+		request  *http.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/users/root", nil)
+	})
+
+	It("renders the error as flaw payload", func() {
+		err := flaw.Errorf("user not found").
+			WithCode(5).
+			WithDetails("the user might be deleted").
+			WithContext(flaw.Map{"user": "root"})
+
+		flawgateway.ErrorHandler(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, recorder, request, err)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"error_code": 5,
+			"error_message": "user not found",
+			"error_details": ["the user might be deleted"],
+			"user": "root"
+		}`))
+	})
+
+	Context("when the server metadata is present", func() {
+		BeforeEach(func() {
+			ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{
+				HeaderMD: metadata.Pairs("request-id", "42"),
+			})
+		})
+
+		It("forwards the metadata", func() {
+			flawgateway.ErrorHandler(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, recorder, request, flaw.Errorf("oh no"))
+			Expect(recorder.Header().Get("Grpc-Metadata-Request-Id")).To(Equal("42"))
+		})
+	})
+})
+
+var _ = Describe("Convert", func() {
+	It("converts the error successfully", func() {
+		errx := flawgateway.Convert(flaw.Errorf("oh no").WithCode(3))
+		Expect(errx.Code()).To(Equal(3))
+		Expect(errx.Status()).To(Equal(http.StatusBadRequest))
+		Expect(errx.Message()).To(Equal("oh no"))
+	})
+
+	It("converts the pointer successfully", func() {
+		errx := flawgateway.Convert(flaw.Errorf("price is negative").WithCode(3).WithPointer("/items/3/price"))
+		Expect(errx.Pointer()).To(Equal("/items/3/price"))
+	})
+
+	It("converts the component successfully", func() {
+		errx := flawgateway.Convert(flaw.Errorf("card declined").WithComponent("payment-service/worker"))
+		Expect(errx.Component()).To(Equal("payment-service/worker"))
+	})
+
+	Context("when the error is not a status", func() {
+		It("converts the error as unknown", func() {
+			errx := flawgateway.Convert(context.Canceled)
+			Expect(errx.Code()).To(Equal(2))
+			Expect(errx.Status()).To(Equal(http.StatusInternalServerError))
+			Expect(errx.Message()).To(Equal("context canceled"))
+		})
+	})
+})
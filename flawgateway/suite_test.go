@@ -0,0 +1,15 @@
+//go:build !flaw_nogrpc
+
+package flawgateway_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlawgateway(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flawgateway Suite")
+}
@@ -0,0 +1,46 @@
+package flawhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/phogolabs/flaw"
+)
+
+// body is a response body which beginning has been peeked
+type body struct {
+	io.Reader
+	io.Closer
+}
+
+// peek reads the first bytes of the response body up to the limit and puts
+// them back, so that the body is still readable as a whole. The limit is
+// capped to flaw.MaxAttachmentSize.
+func peek(response *http.Response, limit int) (flaw.Attachment, bool) {
+	if response.Body == nil || response.Body == http.NoBody {
+		return flaw.Attachment{}, false
+	}
+
+	if limit == 0 || limit > flaw.MaxAttachmentSize {
+		limit = flaw.MaxAttachmentSize
+	}
+
+	// the extra byte marks the attachment as truncated
+	data, _ := io.ReadAll(io.LimitReader(response.Body, int64(limit)+1))
+
+	response.Body = &body{
+		Reader: io.MultiReader(bytes.NewReader(data), response.Body),
+		Closer: response.Body,
+	}
+
+	truncated := len(data) > limit
+	if truncated {
+		data = data[:limit]
+	}
+
+	attachment := flaw.NewAttachment("response", data, response.Header.Get("Content-Type"))
+	attachment.Truncated = truncated
+
+	return attachment, true
+}
@@ -0,0 +1,72 @@
+package flawhttp_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawhttp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FromResponse", func() {
+	var response *http.Response
+
+	BeforeEach(func() {
+		request, err := http.NewRequest("POST", "http://example.com/users", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		response = &http.Response{
+			Status:     "409 Conflict",
+			StatusCode: http.StatusConflict,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"reason":"exists"}`)),
+			Request:    request,
+		}
+	})
+
+	It("creates an error from the response", func() {
+		errx := flawhttp.FromResponse(response)
+
+		Expect(errx.Status()).To(Equal(http.StatusConflict))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_method", "POST"))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_url", "http://example.com/users"))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_status", http.StatusConflict))
+		Expect(errx.Context()).To(HaveKeyWithValue("retryable", false))
+		Expect(errx.Attachments()).To(ConsistOf(flaw.NewAttachment("response", []byte(`{"reason":"exists"}`), "application/json")))
+	})
+
+	It("keeps the body readable", func() {
+		flawhttp.FromResponse(response)
+
+		data, err := io.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"reason":"exists"}`))
+		Expect(response.Body.Close()).To(Succeed())
+	})
+
+	It("reads the first bytes of a large body only", func() {
+		text := strings.Repeat("a", 2*flaw.MaxAttachmentSize)
+		reader := strings.NewReader(text)
+		response.Body = io.NopCloser(reader)
+
+		errx := flawhttp.FromResponse(response)
+		Expect(reader.Len()).To(Equal(flaw.MaxAttachmentSize - 1))
+
+		attachment := errx.Attachments()[0]
+		Expect(attachment.Data).To(HaveLen(flaw.MaxAttachmentSize))
+		Expect(attachment.Truncated).To(BeTrue())
+
+		data, err := io.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(text))
+	})
+
+	It("does not attach an empty body", func() {
+		response.Body = http.NoBody
+		Expect(flawhttp.FromResponse(response).Attachments()).To(BeEmpty())
+	})
+})
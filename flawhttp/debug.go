@@ -0,0 +1,192 @@
+package flawhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+)
+
+// DebugPage renders the errors as HTML pages for the development, with a
+// collapsible stack trace, a highlighted JSON of the error and links to the
+// source lines
+type DebugPage struct {
+	// Development enables the HTML pages. When disabled the errors are
+	// written as JSON by Writer, so the internals of the errors do not reach
+	// the clients in production.
+	Development bool
+	// SourceURL returns the link to the source line, e.g. an editor or a
+	// repository URL. The links are file:// URLs by default.
+	SourceURL func(file string, line int) string
+	// Writer writes the errors when the development is disabled
+	Writer Writer
+}
+
+// Write writes the error as an HTML page with the error status
+func (p *DebugPage) Write(rw http.ResponseWriter, r *http.Request, err error) {
+	if !p.Development {
+		p.Writer.Write(rw, r, err)
+		return
+	}
+
+	errx, ok := err.(*flaw.Error)
+
+	if !ok {
+		errx = flaw.Wrap(err)
+	}
+
+	code := errx.Status()
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(code)
+
+	page.Execute(rw, p.view(errx, code))
+}
+
+// Recover recovers the panics of the next handler and writes them as errors
+func (p *DebugPage) Recover(next http.Handler) http.Handler {
+	fn := func(rw http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if reason := recover(); reason != nil {
+				if reason == http.ErrAbortHandler {
+					panic(reason)
+				}
+
+				p.Write(rw, r, flaw.Errorf("panic: %v", reason))
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+type debugView struct {
+	Status  int
+	Title   string
+	Code    int
+	Message string
+	Details []string
+	Cause   string
+	JSON    template.HTML
+	Stack   []debugFrame
+}
+
+type debugFrame struct {
+	Function string
+	File     string
+	Line     int
+	URL      template.URL
+}
+
+func (p *DebugPage) view(errx *flaw.Error, code int) *debugView {
+	view := &debugView{
+		Status:  code,
+		Title:   http.StatusText(code),
+		Code:    errx.Code(),
+		Message: errx.Message(),
+		Details: errx.Details(),
+	}
+
+	if cause := errx.Cause(); cause != nil {
+		view.Cause = cause.Error()
+	}
+
+	if data, err := json.MarshalIndent(errx, "", "  "); err == nil {
+		view.JSON = highlight(string(data))
+	}
+
+	for _, frame := range errx.StackTrace() {
+		// the links are configured by the developer, so they are trusted
+		link := template.URL(p.source(frame.File, frame.Line))
+
+		view.Stack = append(view.Stack, debugFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+			URL:      link,
+		})
+	}
+
+	return view
+}
+
+func (p *DebugPage) source(file string, line int) string {
+	if p.SourceURL != nil {
+		return p.SourceURL(file, line)
+	}
+
+	return fmt.Sprintf("file://%s#L%d", file, line)
+}
+
+// token matches the keys, the strings and the literals of an indented JSON
+var token = regexp.MustCompile(`("(?:[^"\\]|\\.)*")(\s*:)?|\b(true|false|null)\b|-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?`)
+
+// highlight wraps the tokens of the JSON in spans with the token classes
+func highlight(data string) template.HTML {
+	var (
+		buffer = &strings.Builder{}
+		offset = 0
+	)
+
+	for _, match := range token.FindAllStringSubmatchIndex(data, -1) {
+		buffer.WriteString(template.HTMLEscapeString(data[offset:match[0]]))
+
+		class := "literal"
+
+		switch {
+		case match[4] >= 0:
+			class = "key"
+		case match[2] >= 0:
+			class = "string"
+		}
+
+		end := match[1]
+		if class == "key" {
+			end = match[3]
+		}
+
+		fmt.Fprintf(buffer, `<span class="%s">%s</span>`, class, template.HTMLEscapeString(data[match[0]:end]))
+		offset = end
+	}
+
+	buffer.WriteString(template.HTMLEscapeString(data[offset:]))
+
+	return template.HTML(buffer.String())
+}
+
+var page = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 small { color: #888; font-weight: normal; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+.key { color: #005cc5; }
+.string { color: #032f62; }
+.literal { color: #d73a49; }
+.frame { font-family: monospace; margin: 0.25em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Message}} <small>{{.Status}} {{.Title}}{{if .Code}}, code {{.Code}}{{end}}</small></h1>
+{{if .Details}}<ul class="details">{{range .Details}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Cause}}<p class="cause">caused by: {{.Cause}}</p>{{end}}
+<pre class="json">{{.JSON}}</pre>
+{{if .Stack}}<details class="stack" open>
+<summary>Stack trace</summary>
+{{range .Stack}}<div class="frame">{{.Function}}<br><a href="{{.URL}}">{{.File}}:{{.Line}}</a></div>
+{{end}}</details>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,80 @@
+package flawhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawhttp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DebugPage", func() {
+	var (
+		recorder *httptest.ResponseRecorder
+		request  *http.Request
+		page     *flawhttp.DebugPage
+		errx     *flaw.Error
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/users/root", nil)
+		page = &flawhttp.DebugPage{Development: true}
+		errx = flaw.Errorf("user <root> not found").
+			WithCode(5).
+			WithStatus(http.StatusNotFound).
+			WithDetails("the user might be deleted").
+			WithContext(flaw.Map{"user": "root"}).
+			WithError(fmt.Errorf("sql: no rows"))
+	})
+
+	It("writes the error as html", func() {
+		page.Write(recorder, request, errx)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+
+		body := recorder.Body.String()
+		Expect(body).To(ContainSubstring("<h1>user &lt;root&gt; not found <small>404 Not Found, code 5</small></h1>"))
+		Expect(body).To(ContainSubstring("<li>the user might be deleted</li>"))
+		Expect(body).To(ContainSubstring("caused by: sql: no rows"))
+		Expect(body).To(ContainSubstring(`<span class="key">&#34;user&#34;</span>: <span class="string">&#34;root&#34;</span>`))
+		Expect(body).To(ContainSubstring(`<span class="key">&#34;error_code&#34;</span>: <span class="literal">5</span>`))
+		Expect(body).To(ContainSubstring(`<details class="stack" open>`))
+		Expect(body).To(MatchRegexp(`<a href="file://[^"]+debug_test.go#L\d+">`))
+	})
+
+	It("links the source lines", func() {
+		page.SourceURL = func(file string, line int) string {
+			return fmt.Sprintf("https://example.com/blob/%d", line)
+		}
+
+		page.Write(recorder, request, errx)
+		Expect(recorder.Body.String()).To(MatchRegexp(`<a href="https://example.com/blob/\d+">`))
+	})
+
+	It("recovers the panics", func() {
+		handler := page.Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("oh no")
+		}))
+
+		handler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).To(ContainSubstring("<h1>panic: oh no"))
+	})
+
+	Context("when the development is disabled", func() {
+		It("writes the error as json", func() {
+			page.Development = false
+			page.Write(recorder, request, errx)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package flawhttp
+
+import (
+	"net/http"
+)
+
+// ErrorWriter writes the errors as HTTP responses, e.g. Writer or DebugPage
+type ErrorWriter interface {
+	Write(rw http.ResponseWriter, r *http.Request, err error)
+}
+
+var (
+	_ ErrorWriter = &Writer{}
+	_ ErrorWriter = &DebugPage{}
+)
+
+// HandlerFunc is an HTTP handler that returns an error instead of writing it.
+// The error is written by the Writer with its default settings, e.g.
+//
+//	http.Handle("/users", flawhttp.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+//		return flaw.Errorf("user not found").WithStatus(http.StatusNotFound)
+//	}))
+type HandlerFunc func(rw http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls the handler and writes the returned error
+func (fn HandlerFunc) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	handler := &ErrorHandler{}
+	handler.Handle(fn).ServeHTTP(rw, r)
+}
+
+// ErrorHandler writes the errors returned by the handlers, so the services do
+// not repeat the rendering of the errors in every handler
+type ErrorHandler struct {
+	// Writer writes the errors. The errors are written by a Writer with the
+	// default settings if nil.
+	Writer ErrorWriter
+}
+
+// Handle returns an http.Handler that calls the handler and writes the
+// returned error with its status. The error is not written if the handler
+// returns nil, so the handler should not return an error after it has written
+// the response.
+func (h *ErrorHandler) Handle(fn HandlerFunc) http.Handler {
+	writer := h.Writer
+
+	if writer == nil {
+		writer = &Writer{}
+	}
+
+	handler := func(rw http.ResponseWriter, r *http.Request) {
+		if err := fn(rw, r); err != nil {
+			writer.Write(rw, r, err)
+		}
+	}
+
+	return http.HandlerFunc(handler)
+}
@@ -0,0 +1,95 @@
+package flawhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawhttp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorHandler", func() {
+	var (
+		recorder *httptest.ResponseRecorder
+		request  *http.Request
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/users/root", nil)
+	})
+
+	It("writes the returned error", func() {
+		handler := flawhttp.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) error {
+			return flaw.Errorf("user not found").WithCode(5).WithStatus(http.StatusNotFound)
+		})
+
+		handler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error_code":5,"error_message":"user not found"}`))
+	})
+
+	It("does not write anything when the handler succeeds", func() {
+		handler := flawhttp.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) error {
+			rw.WriteHeader(http.StatusNoContent)
+			return nil
+		})
+
+		handler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(recorder.Body.Len()).To(BeZero())
+	})
+
+	It("writes the error by the writer", func() {
+		handler := &flawhttp.ErrorHandler{
+			Writer: &flawhttp.Writer{Envelope: "error"},
+		}
+
+		handler.Handle(func(rw http.ResponseWriter, r *http.Request) error {
+			return fmt.Errorf("oh no")
+		}).ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"error_cause":"oh no"}}`))
+	})
+
+	Context("when the client prefers xml", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "application/json;q=0.5, application/xml")
+		})
+
+		It("writes the error as xml", func() {
+			handler := flawhttp.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) error {
+				return flaw.Errorf("user not found").WithCode(5).WithStatus(http.StatusNotFound)
+			})
+
+			handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/xml"))
+			Expect(recorder.Body.String()).To(Equal("<Error><ErrorCode>5</ErrorCode><ErrorMessage>user not found</ErrorMessage></Error>"))
+		})
+	})
+
+	Context("when the client accepts both json and xml", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "application/xml, application/json")
+		})
+
+		It("writes the error as json", func() {
+			handler := flawhttp.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) error {
+				return flaw.Errorf("user not found")
+			})
+
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		})
+	})
+})
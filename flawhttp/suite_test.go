@@ -0,0 +1,13 @@
+package flawhttp_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlawhttp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flawhttp Suite")
+}
@@ -0,0 +1,140 @@
+package flawhttp
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/phogolabs/flaw"
+)
+
+const (
+	keyMethod    = "http_method"
+	keyURL       = "http_url"
+	keyStatus    = "http_status"
+	keyRetryable = "retryable"
+)
+
+// Transport converts the transport failures and the unsuccessful responses of
+// the outbound requests into flaw errors. The errors have the method, the URL,
+// the status, the retryability and the elapsed time in their context.
+type Transport struct {
+	// Next is the transport that sends the requests. If nil,
+	// http.DefaultTransport is used.
+	Next http.RoundTripper
+	// Accept reports whether the response status is successful. If nil, the
+	// 2xx statuses are accepted.
+	Accept func(status int) bool
+	// RetryPolicy decides the retryability of the errors. If nil, the default
+	// retry policy is used.
+	RetryPolicy *flaw.RetryPolicy
+	// MaxBodySize is the maximum size of the response body that is attached
+	// to the errors. If zero, flaw.MaxAttachmentSize is used. The body is not
+	// attached if negative.
+	MaxBodySize int
+}
+
+// RoundTrip sends the request. It returns a flaw error if the request fails
+// or the response status is not accepted. The beginning of the body of the
+// rejected response is attached to the error as "response". The rejected
+// response is returned along with the error and its body is still readable as
+// a whole, so the caller must close it. Note that http.Client discards the
+// response of a failed round trip, so the callers that need the body use the
+// transport directly.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	response, err := t.next().RoundTrip(r)
+	if err != nil {
+		var errx *flaw.Error
+
+		// the error of a nested transport already has the context
+		if errors.As(err, &errx) {
+			return nil, err
+		}
+
+		context := describe(r)
+		context[flaw.KeyElapsed] = time.Since(start)
+
+		return nil, retryable(flaw.Wrap(err), t.RetryPolicy, context)
+	}
+
+	if t.accept(response.StatusCode) {
+		return response, nil
+	}
+
+	context := describe(r)
+	context[flaw.KeyElapsed] = time.Since(start)
+
+	return response, reject(response, t.MaxBodySize, t.RetryPolicy, context)
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+
+	return http.DefaultTransport
+}
+
+func (t *Transport) accept(status int) bool {
+	if t.Accept != nil {
+		return t.Accept(status)
+	}
+
+	return status >= 200 && status < 300
+}
+
+// RoundTripper returns a round tripper that converts the transport failures
+// and the non-2xx responses into flaw errors
+func RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &Transport{Next: next}
+}
+
+// FromResponse creates an error from the unsuccessful response. The first
+// flaw.MaxAttachmentSize bytes of the body are attached to the error as
+// "response". The body is not consumed, so it's still readable as a whole by
+// the caller.
+func FromResponse(response *http.Response) *flaw.Error {
+	context := flaw.Map{}
+
+	if response.Request != nil {
+		context = describe(response.Request)
+	}
+
+	return reject(response, 0, nil, context)
+}
+
+// reject creates the error of a rejected response
+func reject(response *http.Response, limit int, policy *flaw.RetryPolicy, context flaw.Map) *flaw.Error {
+	context[keyStatus] = response.StatusCode
+
+	errx := flaw.Errorf("unexpected response status %s", response.Status).
+		WithStatus(response.StatusCode)
+
+	if limit >= 0 {
+		if attachment, ok := peek(response, limit); ok {
+			errx = errx.WithAttachments(attachment)
+		}
+	}
+
+	return retryable(errx, policy, context)
+}
+
+func describe(r *http.Request) flaw.Map {
+	return flaw.Map{
+		keyMethod: r.Method,
+		keyURL:    r.URL.Redacted(),
+	}
+}
+
+func retryable(errx *flaw.Error, policy *flaw.RetryPolicy, context flaw.Map) *flaw.Error {
+	if policy == nil {
+		policy = flaw.NewRetryPolicy()
+	}
+
+	retry, _ := policy.Retry(errx)
+	context[keyRetryable] = retry
+
+	return errx.WithContext(context)
+}
@@ -0,0 +1,154 @@
+package flawhttp_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawhttp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type RoundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (fn RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+var _ = Describe("Transport", func() {
+	var (
+		server *httptest.Server
+		status int
+		client *http.Client
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Type", "text/plain")
+			rw.WriteHeader(status)
+			fmt.Fprint(rw, "oh no")
+		}))
+
+		client = &http.Client{Transport: flawhttp.RoundTripper(nil)}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the successful response", func() {
+		response, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(response.Body.Close()).To(Succeed())
+	})
+
+	It("converts the unsuccessful response", func() {
+		status = http.StatusServiceUnavailable
+
+		_, err := client.Get(server.URL + "/users?id=42")
+		Expect(err).To(HaveOccurred())
+
+		var errx *flaw.Error
+		Expect(errors.As(err, &errx)).To(BeTrue())
+		Expect(errx.Status()).To(Equal(http.StatusServiceUnavailable))
+		Expect(errx.Message()).To(Equal("unexpected response status 503 Service Unavailable"))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_method", "GET"))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_url", server.URL+"/users?id=42"))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_status", http.StatusServiceUnavailable))
+		Expect(errx.Context()).To(HaveKeyWithValue("retryable", true))
+		Expect(errx.Context()).To(HaveKey(flaw.KeyElapsed))
+		Expect(errx.Attachments()).To(ConsistOf(flaw.NewAttachment("response", []byte("oh no"), "text/plain")))
+	})
+
+	It("marks the client errors as not retryable", func() {
+		status = http.StatusBadRequest
+
+		_, err := client.Get(server.URL)
+
+		var errx *flaw.Error
+		Expect(errors.As(err, &errx)).To(BeTrue())
+		Expect(errx.Context()).To(HaveKeyWithValue("retryable", false))
+	})
+
+	It("accepts the configured statuses", func() {
+		status = http.StatusNotFound
+
+		client.Transport = &flawhttp.Transport{
+			Accept: func(code int) bool {
+				return code < 500
+			},
+		}
+
+		response, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(response.Body.Close()).To(Succeed())
+	})
+
+	It("converts the transport failures", func() {
+		failure := fmt.Errorf("connection refused")
+
+		client.Transport = flawhttp.RoundTripper(RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, failure
+		}))
+
+		_, err := client.Get(server.URL)
+
+		var errx *flaw.Error
+		Expect(errors.As(err, &errx)).To(BeTrue())
+		Expect(errx).To(MatchError(failure))
+		Expect(errx.Context()).To(HaveKeyWithValue("http_method", "GET"))
+		Expect(errx.Context()).NotTo(HaveKey("http_status"))
+		Expect(errx.Context()).To(HaveKeyWithValue("retryable", false))
+	})
+
+	It("limits the size of the attached body", func() {
+		transport := &flawhttp.Transport{
+			MaxBodySize: 2,
+			Next: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Status:     "500 Internal Server Error",
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(strings.NewReader("oh no")),
+				}, nil
+			}),
+		}
+
+		request := httptest.NewRequest("GET", "/users", nil)
+
+		_, err := transport.RoundTrip(request)
+
+		var errx *flaw.Error
+		Expect(errors.As(err, &errx)).To(BeTrue())
+		Expect(errx.Attachments()).To(HaveLen(1))
+		Expect(errx.Attachments()[0].Data).To(Equal([]byte("oh")))
+		Expect(errx.Attachments()[0].Truncated).To(BeTrue())
+	})
+
+	It("keeps the body of the rejected response readable", func() {
+		transport := &flawhttp.Transport{MaxBodySize: 2}
+		request := httptest.NewRequest("GET", server.URL, nil)
+		request.RequestURI = ""
+
+		status = http.StatusServiceUnavailable
+
+		response, err := transport.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(response).NotTo(BeNil())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+
+		data, rerr := io.ReadAll(response.Body)
+		Expect(rerr).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("oh no"))
+		Expect(response.Body.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,231 @@
+// Package flawhttp writes flaw errors as HTTP responses and converts the failed
+// outbound requests into flaw errors.
+package flawhttp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+)
+
+const (
+	// HeaderErrorCode is the header that contains the error code
+	HeaderErrorCode = "X-Error-Code"
+	// HeaderErrorFingerprint is the header that contains the error fingerprint
+	HeaderErrorFingerprint = "X-Error-Fingerprint"
+	// HeaderRequestID is the header that contains the request id. It's copied
+	// from the request.
+	HeaderRequestID = "X-Request-Id"
+)
+
+// Writer writes the errors as JSON responses
+type Writer struct {
+	// Headers is the allowlist of the headers that are emitted with the
+	// response, e.g. HeaderErrorCode. No headers are emitted by default.
+	Headers []string
+	// Envelope is the key of the object that wraps the error in the response,
+	// e.g. "error". The error is not wrapped by default.
+	Envelope string
+	// DisableHTMLEscaping disables the escaping of &, < and > in the JSON
+	// strings of the response
+	DisableHTMLEscaping bool
+	// Suppress is the list of the statuses, which responses contain the status
+	// text and the error fingerprint only, e.g. 500. The full error is logged
+	// to ErrorLog, so it can be correlated by the fingerprint.
+	Suppress []int
+	// ErrorLog logs the suppressed errors. If nil, the logging is done via
+	// the log package's standard logger.
+	ErrorLog *log.Logger
+	// Problem writes the errors as RFC 9457 problem details, which instance
+	// is the path of the request. The envelope is not applied.
+	Problem bool
+	// Store keeps the full errors, so the responses contain the code, the
+	// message and the fingerprint of the errors only. The errors can be
+	// looked up by StoreHandler.
+	Store *flaw.Store
+}
+
+// Write writes the error as a JSON response with the error status. The status
+// is 500 if the error does not have one. The error is written as XML if the
+// Accept header of the request prefers XML over JSON.
+func (w *Writer) Write(rw http.ResponseWriter, r *http.Request, err error) {
+	errx, ok := err.(*flaw.Error)
+
+	if !ok {
+		errx = flaw.Wrap(err)
+	}
+
+	// the fingerprint is the one of the full error, which is the key of the
+	// error in the store
+	fingerprint := errx.Fingerprint()
+	full := errx
+
+	if w.Store != nil {
+		errx = w.Store.Intern(errx)
+	}
+
+	header := rw.Header()
+
+	for _, name := range w.Headers {
+		if value := w.header(r, errx, fingerprint, name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	markup := !w.Problem && prefersXML(r)
+
+	switch {
+	case w.Problem:
+		header.Set("Content-Type", flaw.ContentTypeProblem)
+	case markup:
+		header.Set("Content-Type", "application/xml")
+	default:
+		header.Set("Content-Type", "application/json")
+	}
+
+	code := errx.Status()
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+
+	rw.WriteHeader(code)
+
+	if w.suppress(code) {
+		w.logf("flawhttp: %s: %v", fingerprint, full)
+
+		// the internals of the error never reach the client
+		errx = flaw.Errorf("%s", http.StatusText(code)).
+			WithContext(flaw.Map{flaw.KeyFingerprint: fingerprint})
+	}
+
+	if w.Problem {
+		problem := errx.WithStatus(code).Problem()
+
+		if r != nil {
+			problem.Instance = r.URL.Path
+		}
+
+		encoder := json.NewEncoder(rw)
+		encoder.SetEscapeHTML(!w.DisableHTMLEscaping)
+		encoder.Encode(problem)
+		return
+	}
+
+	if markup {
+		xml.NewEncoder(rw).Encode(errx)
+		return
+	}
+
+	// the error is streamed, so large collections are not built in memory
+	encoder := flaw.NewEncoder(rw)
+	encoder.SetEscapeHTML(!w.DisableHTMLEscaping)
+	encoder.SetEnvelope(w.Envelope)
+	encoder.Encode(errx)
+}
+
+func (w *Writer) suppress(code int) bool {
+	for _, status := range w.Suppress {
+		if status == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *Writer) logf(format string, args ...interface{}) {
+	if w.ErrorLog != nil {
+		w.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (w *Writer) header(r *http.Request, errx *flaw.Error, fingerprint, name string) string {
+	switch http.CanonicalHeaderKey(name) {
+	case HeaderErrorCode:
+		if code := errx.Code(); code != 0 {
+			return strconv.Itoa(code)
+		}
+	case HeaderErrorFingerprint:
+		return fingerprint
+	case HeaderRequestID:
+		if r != nil {
+			return r.Header.Get(HeaderRequestID)
+		}
+	}
+
+	return ""
+}
+
+// prefersXML reports whether the Accept header of the request prefers XML over
+// JSON. JSON is preferred when both have the same quality.
+func prefersXML(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+
+	var markup, data float64
+
+	for _, item := range strings.Split(r.Header.Get("Accept"), ",") {
+		kind, params, err := mime.ParseMediaType(item)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if value, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = value
+		}
+
+		switch kind {
+		case "application/xml", "text/xml":
+			if quality > markup {
+				markup = quality
+			}
+		case "application/json", "application/*", "*/*":
+			if quality > data {
+				data = quality
+			}
+		}
+	}
+
+	return markup > data
+}
+
+// Write writes the error as a JSON response without any headers
+func Write(rw http.ResponseWriter, r *http.Request, err error) {
+	writer := &Writer{}
+	writer.Write(rw, r, err)
+}
+
+// StoreHandler returns a handler that writes the error of the store, which
+// fingerprint is the fingerprint query parameter, as JSON with its stack
+// trace. It's meant for the debug endpoints, which are not exposed to the
+// clients.
+func StoreHandler(store *flaw.Store) http.Handler {
+	fn := func(rw http.ResponseWriter, r *http.Request) {
+		fingerprint := r.URL.Query().Get("fingerprint")
+
+		errx, ok := store.Lookup(fingerprint)
+		if !ok {
+			Write(rw, r, flaw.Errorf("error %q not found", fingerprint).WithStatus(http.StatusNotFound))
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		encoder := flaw.NewEncoder(rw)
+		encoder.SetStackTrace(true)
+		encoder.Encode(errx)
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,221 @@
+package flawhttp_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawhttp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		recorder *httptest.ResponseRecorder
+		request  *http.Request
+		writer   *flawhttp.Writer
+		errx     *flaw.Error
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/users/root", nil)
+		request.Header.Set("X-Request-Id", "42")
+		writer = &flawhttp.Writer{}
+		errx = flaw.Errorf("user not found").WithCode(5).WithStatus(http.StatusNotFound)
+	})
+
+	It("writes the error as json", func() {
+		writer.Write(recorder, request, errx)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Header()).NotTo(HaveKey("X-Error-Code"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error_code":5,"error_message":"user not found"}`))
+	})
+
+	Context("when the headers are allowed", func() {
+		BeforeEach(func() {
+			writer.Headers = []string{
+				flawhttp.HeaderErrorCode,
+				flawhttp.HeaderErrorFingerprint,
+				flawhttp.HeaderRequestID,
+			}
+		})
+
+		It("writes the headers", func() {
+			writer.Write(recorder, request, errx)
+
+			Expect(recorder.Header().Get("X-Error-Code")).To(Equal("5"))
+			Expect(recorder.Header().Get("X-Error-Fingerprint")).To(Equal(errx.Fingerprint()))
+			Expect(recorder.Header().Get("X-Request-Id")).To(Equal("42"))
+		})
+
+		It("skips the empty headers", func() {
+			request.Header.Del("X-Request-Id")
+			writer.Write(recorder, request, flaw.Errorf("oh no"))
+
+			Expect(recorder.Header()).NotTo(HaveKey("X-Error-Code"))
+			Expect(recorder.Header()).NotTo(HaveKey("X-Request-Id"))
+			Expect(recorder.Header()).To(HaveKey("X-Error-Fingerprint"))
+		})
+	})
+
+	Context("when the envelope is set", func() {
+		BeforeEach(func() {
+			writer.Envelope = "error"
+		})
+
+		It("wraps the error in the envelope", func() {
+			writer.Write(recorder, request, errx)
+			Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"error_code":5,"error_message":"user not found"}}`))
+		})
+	})
+
+	Context("when the html escaping is disabled", func() {
+		BeforeEach(func() {
+			writer.DisableHTMLEscaping = true
+		})
+
+		It("does not escape the html characters", func() {
+			writer.Write(recorder, request, flaw.Errorf("<user> not found"))
+			Expect(recorder.Body.String()).To(Equal(`{"error_message":"<user> not found"}` + "\n"))
+		})
+	})
+
+	Context("when the status is suppressed", func() {
+		var output *bytes.Buffer
+
+		BeforeEach(func() {
+			output = &bytes.Buffer{}
+			writer.Suppress = []int{http.StatusInternalServerError}
+			writer.ErrorLog = log.New(output, "", 0)
+		})
+
+		It("writes the status text and the fingerprint", func() {
+			err := flaw.Errorf("query failed").WithError(fmt.Errorf("password is wrong"))
+			writer.Write(recorder, request, err)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(MatchJSON(fmt.Sprintf(`{
+				"error_message": "Internal Server Error",
+				"error_fingerprint": %q
+			}`, err.Fingerprint())))
+
+			Expect(output.String()).To(Equal(fmt.Sprintf("flawhttp: %s: message: query failed cause: password is wrong\n", err.Fingerprint())))
+		})
+
+		It("does not suppress the other statuses", func() {
+			writer.Write(recorder, request, errx)
+			Expect(recorder.Body.String()).To(MatchJSON(`{"error_code":5,"error_message":"user not found"}`))
+			Expect(output.Len()).To(BeZero())
+		})
+	})
+
+	Context("when the problem details are enabled", func() {
+		BeforeEach(func() {
+			writer.Problem = true
+			writer.Envelope = "error"
+		})
+
+		It("writes the error as problem details", func() {
+			writer.Write(recorder, request, errx)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"type": "about:blank",
+				"title": "user not found",
+				"status": 404,
+				"instance": "/users/root",
+				"error_code": 5
+			}`))
+		})
+	})
+
+	Context("when the store is set", func() {
+		var store *flaw.Store
+
+		BeforeEach(func() {
+			store = flaw.NewStore(10)
+			writer.Store = store
+			errx = errx.WithContext(flaw.Map{"user": "root"})
+		})
+
+		It("writes the light error", func() {
+			writer.Write(recorder, request, errx)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(MatchJSON(fmt.Sprintf(`{
+				"error_code": 5,
+				"error_message": "user not found",
+				"error_fingerprint": %q
+			}`, errx.Fingerprint())))
+		})
+
+		It("looks up the error by the fingerprint header", func() {
+			writer.Headers = []string{flawhttp.HeaderErrorFingerprint}
+			writer.Suppress = []int{http.StatusInternalServerError}
+			output := &bytes.Buffer{}
+			writer.ErrorLog = log.New(output, "", 0)
+
+			err := flaw.Wrap(fmt.Errorf("password is wrong"))
+			writer.Write(recorder, request, err)
+
+			fingerprint := recorder.Header().Get(flawhttp.HeaderErrorFingerprint)
+			Expect(fingerprint).To(Equal(err.Fingerprint()))
+			Expect(recorder.Body.String()).To(ContainSubstring(fingerprint))
+
+			item, ok := store.Lookup(fingerprint)
+			Expect(ok).To(BeTrue())
+			Expect(item).To(MatchError(ContainSubstring("password is wrong")))
+			Expect(output.String()).To(ContainSubstring("password is wrong"))
+		})
+
+		It("looks up the template error by the fingerprint header", func() {
+			writer.Headers = []string{flawhttp.HeaderErrorFingerprint}
+
+			err := flaw.ErrorConstant("user %s not found").With("root").WithStatus(http.StatusNotFound)
+			writer.Write(recorder, request, err)
+
+			fingerprint := recorder.Header().Get(flawhttp.HeaderErrorFingerprint)
+			Expect(fingerprint).To(Equal(err.Fingerprint()))
+
+			_, ok := store.Lookup(fingerprint)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("looks up the full error", func() {
+			writer.Write(recorder, request, errx)
+
+			recorder = httptest.NewRecorder()
+			request = httptest.NewRequest("GET", "/debug/errors?fingerprint="+errx.Fingerprint(), nil)
+			flawhttp.StoreHandler(store).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"user":"root"`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"error_stack":[{`))
+		})
+
+		It("does not find an unknown error", func() {
+			request = httptest.NewRequest("GET", "/debug/errors?fingerprint=unknown", nil)
+			flawhttp.StoreHandler(store).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("when the error is not a flaw error", func() {
+		It("writes the error with internal server error status", func() {
+			flawhttp.Write(recorder, request, fmt.Errorf("oh no"))
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"error_cause":"oh no"}`))
+		})
+	})
+})
@@ -0,0 +1,11 @@
+// Command flawlint reports common misuses of the flaw package.
+package main
+
+import (
+	"github.com/phogolabs/flaw/flawlint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(flawlint.Analyzer)
+}
@@ -0,0 +1,251 @@
+// Package flawlint defines an analyzer that reports common misuses of the
+// flaw package.
+package flawlint
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const pkgPath = "github.com/phogolabs/flaw"
+
+// Analyzer reports common misuses of the flaw package:
+//
+//   - dropping the result of the With* methods and Freeze, which return a copy
+//   - wrapping a nil error
+//   - calling WithContext with a package level map, which is shared
+//   - formatting an error with %s, which prints the stack trace
+var Analyzer = &analysis.Analyzer{
+	Name:     "flawlint",
+	Doc:      "reports common misuses of the github.com/phogolabs/flaw package",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// printers are the functions that accept a format and the index of it
+var printers = map[string]int{
+	"fmt.Errorf":  0,
+	"fmt.Printf":  0,
+	"fmt.Sprintf": 0,
+	"fmt.Fprintf": 1,
+	"log.Printf":  0,
+	"log.Fatalf":  0,
+	"log.Panicf":  0,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	filter := []ast.Node{
+		(*ast.ExprStmt)(nil),
+		(*ast.CallExpr)(nil),
+	}
+
+	inspect.Preorder(filter, func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.ExprStmt:
+			checkUnused(pass, node)
+		case *ast.CallExpr:
+			checkWrap(pass, node)
+			checkContext(pass, node)
+			checkFormat(pass, node)
+		}
+	})
+
+	return nil, nil
+}
+
+func checkUnused(pass *analysis.Pass, stmt *ast.ExprStmt) {
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	method := methodOf(pass, call)
+	if method == nil {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(method.Name(), "With"):
+		pass.Reportf(call.Pos(), "result of (*flaw.Error).%s is not used; the With* methods return a copy of the error", method.Name())
+	case method.Name() == "Freeze":
+		pass.Reportf(call.Pos(), "result of (*flaw.Error).Freeze is not used; Freeze returns a frozen copy of the error")
+	}
+}
+
+func checkWrap(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := funcOf(pass, call)
+	if fn == nil || fn.Name() != "Wrap" || len(call.Args) == 0 {
+		return
+	}
+
+	if isNil(pass, call.Args[0]) {
+		pass.Reportf(call.Args[0].Pos(), "flaw.Wrap is called with a nil error")
+	}
+}
+
+func checkContext(pass *analysis.Pass, call *ast.CallExpr) {
+	method := methodOf(pass, call)
+	if method == nil || method.Name() != "WithContext" || len(call.Args) != 1 {
+		return
+	}
+
+	var ident *ast.Ident
+
+	switch arg := call.Args[0].(type) {
+	case *ast.Ident:
+		ident = arg
+	case *ast.SelectorExpr:
+		ident = arg.Sel
+	default:
+		return
+	}
+
+	variable, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok || variable.IsField() || variable.Pkg() == nil {
+		return
+	}
+
+	if variable.Parent() == variable.Pkg().Scope() {
+		pass.Reportf(call.Args[0].Pos(), "(*flaw.Error).WithContext is called with the package level map %s, which is shared between the errors", ident.Name)
+	}
+}
+
+func checkFormat(pass *analysis.Pass, call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+
+	name := fn.Pkg().Path() + "." + fn.Name()
+
+	index, ok := printers[name]
+	if !ok || len(call.Args) <= index {
+		return
+	}
+
+	value := pass.TypesInfo.Types[call.Args[index]].Value
+	if value == nil || value.Kind() != constant.String {
+		return
+	}
+
+	verbs, ok := parse(constant.StringVal(value))
+	if !ok {
+		return
+	}
+
+	args := call.Args[index+1:]
+
+	for position, verb := range verbs {
+		if verb != 's' || position >= len(args) {
+			continue
+		}
+
+		if isError(pass.TypesInfo.TypeOf(args[position])) {
+			pass.Reportf(args[position].Pos(), "%%s formats the stack trace of *flaw.Error; use %%v to format the error message")
+		}
+	}
+}
+
+// parse returns the verbs of the format in the order of the arguments that
+// they consume. The width and precision arguments are reported as '*'.
+func parse(format string) ([]rune, bool) {
+	verbs := []rune{}
+	runes := []rune(format)
+
+	for index := 0; index < len(runes); index++ {
+		if runes[index] != '%' {
+			continue
+		}
+
+		index++
+
+		// flags
+		for index < len(runes) && strings.ContainsRune("+-# 0", runes[index]) {
+			index++
+		}
+
+		// width and precision
+		for index < len(runes) && strings.ContainsRune("0123456789.*[]", runes[index]) {
+			switch runes[index] {
+			case '[':
+				// explicit argument indexes are not supported
+				return nil, false
+			case '*':
+				verbs = append(verbs, '*')
+			}
+
+			index++
+		}
+
+		if index >= len(runes) {
+			break
+		}
+
+		if runes[index] != '%' {
+			verbs = append(verbs, runes[index])
+		}
+	}
+
+	return verbs, true
+}
+
+// funcOf returns the flaw package function that is called
+func funcOf(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return nil
+	}
+
+	if fn.Type().(*types.Signature).Recv() != nil {
+		return nil
+	}
+
+	return fn
+}
+
+// methodOf returns the flaw.Error method that is called
+func methodOf(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return nil
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil || !isError(recv.Type()) {
+		return nil
+	}
+
+	return fn
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	value, ok := pass.TypesInfo.Types[expr]
+	return ok && value.IsNil()
+}
+
+func isError(kind types.Type) bool {
+	if kind == nil {
+		return false
+	}
+
+	if pointer, ok := kind.(*types.Pointer); ok {
+		kind = pointer.Elem()
+	}
+
+	named, ok := kind.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	object := named.Obj()
+	return object.Pkg() != nil && object.Pkg().Path() == pkgPath && object.Name() == "Error"
+}
@@ -0,0 +1,12 @@
+package flawlint_test
+
+import (
+	"testing"
+
+	"github.com/phogolabs/flaw/flawlint"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), flawlint.Analyzer, "a")
+}
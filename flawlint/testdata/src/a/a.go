@@ -0,0 +1,38 @@
+package a
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+)
+
+var shared = flaw.Map{"user": "root"}
+
+func unused() error {
+	err := flaw.Errorf("oh no")
+	err.WithCode(404) // want `result of \(\*flaw.Error\).WithCode is not used`
+	err.Freeze()      // want `result of \(\*flaw.Error\).Freeze is not used`
+	return err.WithMessage("failed")
+}
+
+func wrap() error {
+	return flaw.Wrap(nil) // want `flaw.Wrap is called with a nil error`
+}
+
+func wrapErr(err error) error {
+	return flaw.Wrap(err)
+}
+
+func context() error {
+	local := flaw.Map{"user": "root"}
+
+	_ = flaw.Errorf("oh no").WithContext(local)
+	return flaw.Errorf("oh no").WithContext(shared) // want `WithContext is called with the package level map shared`
+}
+
+func format() string {
+	err := flaw.Errorf("oh no")
+	fmt.Printf("%v %d\n", err, 1)
+	fmt.Printf("%5.*f %s\n", 2, 3.14, "text")
+	return fmt.Sprintf("failed: %s", err) // want `%s formats the stack trace of \*flaw.Error`
+}
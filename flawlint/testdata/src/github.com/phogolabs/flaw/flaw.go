@@ -0,0 +1,19 @@
+package flaw
+
+type Map = map[string]interface{}
+
+type Error struct{}
+
+func (x *Error) Error() string { return "" }
+
+func (x Error) WithCode(code int) *Error { return &x }
+
+func (x Error) WithMessage(msg string) *Error { return &x }
+
+func (x Error) WithContext(ctx Map) *Error { return &x }
+
+func (x Error) Freeze() *Error { return &x }
+
+func Errorf(msg string, data ...interface{}) *Error { return &Error{} }
+
+func Wrap(err error) *Error { return &Error{} }
@@ -0,0 +1,142 @@
+// Package flawslog decorates the slog handlers, so the logged flaw errors are
+// expanded into structured groups, e.g. slog.Error("x", "err", err) logs the
+// code, the message, the context and the stack trace of the error.
+package flawslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/phogolabs/flaw"
+)
+
+const (
+	keyStack       = "error_stack"
+	keyRemoteStack = "error_remote_stack"
+)
+
+var _ slog.Handler = &Handler{}
+
+// Handler expands the flaw errors of the records into groups and passes the
+// records to the next handler. The stack traces are logged only for the
+// records with level Error or above.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a handler that passes the records to the next handler
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled reports whether the next handler handles the records of the level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle expands the flaw errors of the record and passes it to the next
+// handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	stack := record.Level >= slog.LevelError
+	expanded := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(expand(attr, stack))
+		return true
+	})
+
+	return h.next.Handle(ctx, expanded)
+}
+
+// WithAttrs returns a handler with the given attributes. The flaw errors of
+// the attributes are expanded without their stack traces, because the level
+// of the records is not known yet.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	items := make([]slog.Attr, len(attrs))
+
+	for index, attr := range attrs {
+		items[index] = expand(attr, false)
+	}
+
+	return &Handler{next: h.next.WithAttrs(items)}
+}
+
+// WithGroup returns a handler with the given group
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// expand expands the attribute into a group if it's a flaw error
+func expand(attr slog.Attr, stack bool) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		items := attr.Value.Group()
+		attrs := make([]slog.Attr, len(items))
+
+		for index, item := range items {
+			attrs[index] = expand(item, stack)
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindAny, slog.KindLogValuer:
+		// the flaw errors are slog.LogValuer, which are resolved by the next
+		// handler otherwise
+		err, ok := attr.Value.Any().(error)
+		if !ok {
+			return attr
+		}
+
+		var errx *flaw.Error
+
+		if !errors.As(err, &errx) {
+			return attr
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(group(errx, stack)...)}
+	default:
+		return attr
+	}
+}
+
+// group returns the fields of the error ordered by their key. The fields are
+// the ones of the log value of the error, so the context entries that are not
+// serialized, e.g. the ones marked by WithPrivate, are omitted.
+func group(errx *flaw.Error, stack bool) []slog.Attr {
+	items := errx.LogValue().Group()
+	attrs := make([]slog.Attr, 0, len(items)+2)
+
+	for _, item := range items {
+		if item.Key != keyStack {
+			attrs = append(attrs, item)
+		}
+	}
+
+	if trace := errx.StackTrace(); stack && len(trace) > 0 {
+		attrs = append(attrs, slog.Any(keyStack, frames(trace)))
+	}
+
+	if trace := errx.RemoteStack(); len(trace) > 0 {
+		attrs = append(attrs, slog.Any(keyRemoteStack, frames(trace)))
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+
+	return attrs
+}
+
+// frames returns the frames of the stack trace as text
+func frames(trace flaw.StackTrace) []string {
+	items := make([]string, 0, len(trace))
+
+	for _, frame := range trace {
+		if text, err := frame.MarshalText(); err == nil {
+			items = append(items, string(text))
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,107 @@
+package flawslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawslog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		buffer *bytes.Buffer
+		logger *slog.Logger
+		errx   *flaw.Error
+	)
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		logger = slog.New(flawslog.NewHandler(slog.NewJSONHandler(buffer, nil)))
+
+		errx = flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithContext(flaw.Map{"user_id": "root"})
+	})
+
+	entry := func() map[string]interface{} {
+		m := map[string]interface{}{}
+		Expect(json.Unmarshal(buffer.Bytes(), &m)).To(Succeed())
+		return m
+	}
+
+	It("expands the flaw error into a group", func() {
+		logger.Error("cannot load the user", "err", errx)
+
+		group, ok := entry()["err"].(map[string]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(group).To(HaveKeyWithValue("error_code", float64(5)))
+		Expect(group).To(HaveKeyWithValue("error_message", "user not found"))
+		Expect(group).To(HaveKeyWithValue("user_id", "root"))
+		Expect(group).To(HaveKey("error_stack"))
+	})
+
+	It("drops the stack trace for the levels below error", func() {
+		logger.Warn("cannot load the user", "err", errx)
+
+		group, ok := entry()["err"].(map[string]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(group).To(HaveKeyWithValue("error_code", float64(5)))
+		Expect(group).NotTo(HaveKey("error_stack"))
+	})
+
+	It("expands the flaw error in the chain", func() {
+		logger.Error("cannot load the user", "err", fmt.Errorf("handler: %w", errx))
+
+		group, ok := entry()["err"].(map[string]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(group).To(HaveKeyWithValue("error_message", "user not found"))
+	})
+
+	It("expands the flaw errors of the groups and the attributes", func() {
+		logger.With("cause", errx).Error("cannot load the user", slog.Group("request", "err", errx))
+
+		m := entry()
+		Expect(m["cause"]).To(HaveKeyWithValue("error_message", "user not found"))
+		Expect(m["cause"]).NotTo(HaveKey("error_stack"))
+		Expect(m["request"]).To(HaveKeyWithValue("err", HaveKeyWithValue("error_message", "user not found")))
+	})
+
+	It("omits the private context entries", func() {
+		logger.Error("cannot load the user", "err", errx.WithContext(flaw.Map{"user_id": "root", "password": "secret"}).WithPrivate("password"))
+
+		group, ok := entry()["err"].(map[string]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(group).To(HaveKeyWithValue("user_id", "root"))
+		Expect(group).NotTo(HaveKey("password"))
+	})
+
+	Context("when the serialized context entries are restricted", func() {
+		AfterEach(func() {
+			flaw.SerializeOnly()
+		})
+
+		It("omits the other context entries", func() {
+			flaw.SerializeOnly("request_id")
+
+			logger.Error("cannot load the user", "err", errx.WithContext(flaw.Map{"request_id": "42"}))
+
+			group, ok := entry()["err"].(map[string]interface{})
+			Expect(ok).To(BeTrue())
+			Expect(group).To(HaveKeyWithValue("request_id", "42"))
+			Expect(group).To(HaveKeyWithValue("error_message", "user not found"))
+			Expect(group).NotTo(HaveKey("user_id"))
+		})
+	})
+
+	It("logs the other errors as they are", func() {
+		logger.Error("cannot load the user", "err", fmt.Errorf("oh no"))
+		Expect(entry()).To(HaveKeyWithValue("err", "oh no"))
+	})
+})
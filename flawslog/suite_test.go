@@ -0,0 +1,13 @@
+package flawslog_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlawslog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flawslog Suite")
+}
@@ -0,0 +1,87 @@
+// Package flawtest provides helpers for testing code that returns flaw errors.
+package flawtest
+
+import (
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/phogolabs/flaw"
+)
+
+// Snapshot represents the comparable part of an error
+type Snapshot struct {
+	Nil     bool
+	Code    int
+	Status  int
+	Message string
+	Details []string
+	Context flaw.Map
+	Cause   string
+	Errors  []Snapshot
+}
+
+// CompareOptions returns the go-cmp options that compare errors by their
+// code, status, message, details, context and cause message. The stack
+// traces are ignored.
+func CompareOptions() cmp.Options {
+	return cmp.Options{
+		cmp.Transformer("flaw.Error", Snap),
+		cmpopts.EquateEmpty(),
+	}
+}
+
+// Snap creates a snapshot of the given error
+func Snap(err error) Snapshot {
+	if isNil(err) {
+		return Snapshot{Nil: true}
+	}
+
+	if errs, ok := err.(flaw.ErrorCollector); ok {
+		snapshot := Snapshot{}
+
+		for _, child := range errs {
+			snapshot.Errors = append(snapshot.Errors, Snap(child))
+		}
+
+		return snapshot
+	}
+
+	snapshot := Snapshot{
+		Code:    flaw.Code(err),
+		Status:  flaw.Status(err),
+		Message: flaw.Message(err),
+		Details: flaw.Details(err),
+		Context: flaw.Context(err),
+	}
+
+	// the fields below are already part of the snapshot
+	for _, key := range []string{"error_code", "error_message", "error_details", "error_cause", "error_stack"} {
+		delete(snapshot.Context, key)
+	}
+
+	if cause := flaw.Cause(err); cause != err && !isNil(cause) {
+		snapshot.Cause = cause.Error()
+	}
+
+	if _, ok := err.(*flaw.Error); !ok {
+		snapshot.Message = err.Error()
+	}
+
+	return snapshot
+}
+
+func isNil(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(err)
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
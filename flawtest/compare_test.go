@@ -0,0 +1,58 @@
+package flawtest_test
+
+import (
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/flawtest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompareOptions", func() {
+	type Result struct {
+		Name string
+		Err  error
+	}
+
+	It("ignores the stack trace", func() {
+		x := Result{Name: "root", Err: flaw.Errorf("oh no").WithCode(404)}
+		y := Result{Name: "root", Err: flaw.Errorf("oh no").WithCode(404)}
+
+		Expect(cmp.Diff(x, y, flawtest.CompareOptions())).To(BeEmpty())
+	})
+
+	It("compares the causes by message", func() {
+		x := flaw.Wrap(fmt.Errorf("oh no")).WithContext(flaw.Map{"user": "root"})
+		y := flaw.Wrap(fmt.Errorf("oh no")).WithContext(flaw.Map{"user": "root"})
+
+		Expect(cmp.Diff(x, y, flawtest.CompareOptions())).To(BeEmpty())
+	})
+
+	It("compares the collectors", func() {
+		x := flaw.ErrorCollector{flaw.Errorf("oh no"), fmt.Errorf("oh yes")}
+		y := flaw.ErrorCollector{flaw.Errorf("oh no"), fmt.Errorf("oh yes")}
+
+		Expect(cmp.Diff(x, y, flawtest.CompareOptions())).To(BeEmpty())
+	})
+
+	Context("when the errors are different", func() {
+		It("returns the difference", func() {
+			x := Result{Name: "root", Err: flaw.Errorf("oh no").WithCode(404)}
+			y := Result{Name: "root", Err: flaw.Errorf("oh no").WithCode(500)}
+
+			Expect(cmp.Diff(x, y, flawtest.CompareOptions())).To(ContainSubstring("Code"))
+		})
+	})
+
+	Context("when one of the errors is nil", func() {
+		It("returns the difference", func() {
+			x := Result{Name: "root"}
+			y := Result{Name: "root", Err: flaw.Errorf("oh no")}
+
+			Expect(cmp.Diff(x, y, flawtest.CompareOptions())).NotTo(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package flawtest_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlawtest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flawtest Suite")
+}
@@ -3,7 +3,7 @@ package format
 import (
 	"fmt"
 	"io"
-	"text/tabwriter"
+	"strings"
 )
 
 //go:generate counterfeiter -fake-name StateFlusher -o ./fake/flusher.go . StateFlusher
@@ -39,7 +39,9 @@ func NewState(state fmt.State) *State {
 	}
 
 	if state != nil && state.Flag('+') {
-		wstate.writer = tabwriter.NewWriter(state, 0, 0, 1, ' ', tabwriter.AlignRight)
+		// the width sets the minimal width of the title column
+		width, _ := state.Width()
+		wstate.writer = newWriter(state, width)
 	}
 
 	return wstate
@@ -89,6 +91,34 @@ func (w *State) Size() int {
 	return w.size
 }
 
+// Indent returns a state that escapes the tabs and indents the new lines of
+// the written values, so they stay in the value column of the tabwriter. The
+// state itself is returned when the + flag is not set.
+func (w *State) Indent() fmt.State {
+	if w.state != nil && w.state.Flag('+') {
+		return &indent{State: w}
+	}
+
+	return w
+}
+
+type indent struct {
+	*State
+}
+
+// Write is the function to call to emit formatted output to be printed.
+func (w *indent) Write(data []byte) (int, error) {
+	text := string(data)
+	text = strings.ReplaceAll(text, "\t", `\t`)
+	text = strings.ReplaceAll(text, "\n", "\n\t ")
+
+	if _, err := w.State.Write([]byte(text)); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
 // StringSlice represents a slice of string
 type StringSlice []string
 
@@ -109,12 +139,23 @@ func (d StringSlice) Format(state fmt.State, verb rune) {
 	}
 }
 
+// Contains reports whether the slice contains the text
+func (d StringSlice) Contains(text string) bool {
+	for _, item := range d {
+		if item == text {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (d StringSlice) formatBullet(state fmt.State, verb rune) {
 	count := len(d)
 
 	for index, line := range d {
 		fmt.Fprint(state, " --- ")
-		fmt.Fprint(state, line)
+		fmt.Fprint(state, Bullet(line))
 
 		if index < count-1 {
 			fmt.Fprint(state, "\n")
@@ -135,3 +176,9 @@ func (d StringSlice) formatSlice(state fmt.State, verb rune) {
 
 	fmt.Fprint(state, "]")
 }
+
+// Bullet indents the continuation lines of a multi-line bullet text, so they
+// are aligned with its first line.
+func Bullet(text string) string {
+	return strings.ReplaceAll(text, "\n", "\n     ")
+}
@@ -116,6 +116,11 @@ var _ = Describe("StringSlice", func() {
 		Expect(fmt.Sprintf("%v", slice)).To(Equal("[hello, world]"))
 	})
 
+	It("reports whether the slice contains the text", func() {
+		Expect(slice.Contains("world")).To(BeTrue())
+		Expect(slice.Contains("hell")).To(BeFalse())
+	})
+
 	Context("when the format %s is used", func() {
 		It("formats the slice successfully", func() {
 			Expect(fmt.Sprintf("%s", slice)).To(Equal("[hello, world]"))
@@ -126,6 +131,11 @@ var _ = Describe("StringSlice", func() {
 		It("formats the slice successfully", func() {
 			Expect(fmt.Sprintf("%+v", slice)).To(Equal(" --- hello\n --- world"))
 		})
+
+		It("indents the multi-line items", func() {
+			slice := format.StringSlice{"hello\nworld", "bye"}
+			Expect(fmt.Sprintf("%+v", slice)).To(Equal(" --- hello\n     world\n --- bye"))
+		})
 	})
 
 	Context("when the format %#v is used", func() {
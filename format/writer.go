@@ -0,0 +1,14 @@
+//go:build !js && !tinygo && !flaw_lite
+
+package format
+
+import (
+	"io"
+	"text/tabwriter"
+)
+
+// newWriter returns a writer that aligns the titles to the right of a column
+// with the given minimal width
+func newWriter(w io.Writer, width int) io.Writer {
+	return tabwriter.NewWriter(w, width, 0, 1, ' ', tabwriter.AlignRight)
+}
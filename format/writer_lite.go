@@ -0,0 +1,78 @@
+//go:build js || tinygo || flaw_lite
+
+package format
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// newWriter returns a writer that aligns the titles to the right of a column
+// with the given minimal width. It's a lighter replacement of the tabwriter
+// for the WASM builds.
+func newWriter(w io.Writer, width int) io.Writer {
+	return &column{writer: w, width: width}
+}
+
+// column aligns the text before the first tab of every line to the right. The
+// consecutive lines that contain a tab form a block with the same width, as
+// the tabwriter does.
+type column struct {
+	writer io.Writer
+	width  int
+	buffer bytes.Buffer
+}
+
+// Write buffers the data until Flush is called
+func (w *column) Write(data []byte) (int, error) {
+	return w.buffer.Write(data)
+}
+
+// Flush aligns the buffered text and writes it to the underlying writer
+func (w *column) Flush() error {
+	lines := strings.Split(w.buffer.String(), "\n")
+	w.buffer.Reset()
+
+	for start := 0; start < len(lines); {
+		end := start
+
+		for end < len(lines) && strings.Contains(lines[end], "\t") {
+			end++
+		}
+
+		if end == start {
+			// a line without a tab does not have a cell
+			end++
+		} else {
+			w.align(lines[start:end])
+		}
+
+		start = end
+	}
+
+	_, err := io.WriteString(w.writer, strings.Join(lines, "\n"))
+	return err
+}
+
+func (w *column) align(lines []string) {
+	// the cells are separated by a single space
+	width := w.width - 1
+
+	for _, line := range lines {
+		cell := line[:strings.Index(line, "\t")]
+
+		if n := utf8.RuneCountInString(cell); n > width {
+			width = n
+		}
+	}
+
+	for index, line := range lines {
+		position := strings.Index(line, "\t")
+		cell := line[:position]
+		padding := width + 1 - utf8.RuneCountInString(cell)
+
+		lines[index] = strings.Repeat(" ", padding) + cell + line[position+1:]
+	}
+}
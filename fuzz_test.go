@@ -0,0 +1,139 @@
+package flaw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/phogolabs/flaw"
+)
+
+func FuzzMarshalJSON(f *testing.F) {
+	f.Add("user", "root", "oh no")
+	f.Add("db.query", "SELECT * FROM users", "<script>")
+	f.Add("", "\x00\x01\x1f", "\xff")
+
+	f.Fuzz(func(t *testing.T, key, value, msg string) {
+		errx := flaw.Errorf("%s", msg).
+			WithDetails(value).
+			WithContext(flaw.Map{key: value})
+
+		data, err := json.Marshal(errx)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+
+		if !json.Valid(data) {
+			t.Fatalf("invalid json: %s", data)
+		}
+
+		payload := map[string]interface{}{}
+
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+
+		if !utf8.ValidString(key) || !utf8.ValidString(value) || strings.HasPrefix(key, "error_") {
+			return
+		}
+
+		if payload[key] != value {
+			t.Fatalf("context value %q mismatch: %q != %q", key, payload[key], value)
+		}
+	})
+}
+
+func FuzzMarshalXML(f *testing.F) {
+	f.Add("user", "root", "oh no")
+	f.Add("db.query", "SELECT * FROM users", "<script>")
+	f.Add("1 invalid name", "\x00\x01\x1f", "\xff")
+	f.Add("xml:lang", "]]>", "&amp;")
+
+	f.Fuzz(func(t *testing.T, key, value, msg string) {
+		errx := flaw.Errorf("%s", msg).
+			WithDetails(value).
+			WithContext(flaw.Map{key: value})
+
+		data, err := xml.Marshal(errx)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+
+		for {
+			_, err := decoder.Token()
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Fatalf("invalid xml %q: %v", data, err)
+			}
+		}
+	})
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	data, _ := flaw.Errorf("user not found").
+		WithCode(5).
+		WithDetails("id is invalid").
+		WithContext(flaw.Map{"user_id": 42}).
+		WithError(flaw.ErrorCollector{io.EOF, flaw.Errorf("oh no")}).
+		MarshalBinary()
+
+	f.Add(data)
+	f.Add([]byte{1, 0})
+	f.Add([]byte{1, 0, 13, 1, 9})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		errx := &flaw.Error{}
+
+		if err := errx.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		if _, err := errx.MarshalBinary(); err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	data, _ := json.Marshal(flaw.Errorf("user not found").
+		WithCode(5).
+		WithDetails("id is invalid").
+		WithContext(flaw.Map{"user_id": 42}).
+		WithError(flaw.ErrorCollector{io.EOF, flaw.Errorf("oh no")}))
+
+	f.Add(data)
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"error_message":"failed","error_cause":null}`))
+	f.Add([]byte(`{"error_message":"failed","error_cause":[null,"oh no"]}`))
+	f.Add([]byte(`{"error_cause":[["oh no",[{"error_cause":["oh yes"]}]]]}`))
+	f.Add([]byte(`{"error_cause":{"items":{"42":null}},"error_cause_type":"collection"}`))
+	f.Add([]byte(`{"error_code":"5","error_cause_type":"json","error_cause":{"reason":"oh no"}}`))
+	f.Add([]byte(`{"":1,"error_stack":"oh no","error_annotations":[],"__proto__":{},"error_args":{}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		errx, err := flaw.Parse(data)
+		if err != nil {
+			return
+		}
+
+		if _, err := json.Marshal(errx); err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+
+		if _, err := errx.MarshalBinary(); err != nil {
+			t.Fatalf("marshal binary: %v", err)
+		}
+
+		_ = errx.Error()
+	})
+}
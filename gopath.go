@@ -0,0 +1,10 @@
+//go:build !js && !tinygo && !flaw_lite
+
+package flaw
+
+import "go/build"
+
+// gopath returns the GOPATH that is trimmed from the stack frame paths
+func gopath() string {
+	return build.Default.GOPATH
+}
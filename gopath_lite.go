@@ -0,0 +1,12 @@
+//go:build js || tinygo || flaw_lite
+
+package flaw
+
+import "os"
+
+// gopath returns the GOPATH that is trimmed from the stack frame paths. The
+// go/build package is not used, because it bloats the WASM builds, so the
+// default GOPATH is not detected.
+func gopath() string {
+	return os.Getenv("GOPATH")
+}
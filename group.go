@@ -0,0 +1,53 @@
+package flaw
+
+import "strconv"
+
+// GroupBy groups the errors by the keys returned by the key function, e.g. to
+// summarize the failures of a batch job. The nil errors are skipped.
+func GroupBy(errs []error, key func(error) string) map[string]ErrorCollector {
+	groups := make(map[string]ErrorCollector)
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		name := key(err)
+		groups[name] = append(groups[name], err)
+	}
+
+	return groups
+}
+
+// ByCode returns the name of the error's gRPC code, e.g. "AlreadyExists". The
+// codes out of the gRPC range are returned as numbers and the errors without
+// a code have an empty key.
+func ByCode(err error) string {
+	code := Code(err)
+
+	if name, ok := codeName(code); ok {
+		return name
+	}
+
+	if code == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(code)
+}
+
+// ByNamespace returns the namespace of the error, which is the kind of the
+// errors created by a Namespace
+func ByNamespace(err error) string {
+	if errx, ok := err.(*Error); ok {
+		return errx.namespace
+	}
+
+	return ""
+}
+
+// ByFingerprint returns the fingerprint of the error, so the errors that are
+// the same are grouped together
+func ByFingerprint(err error) string {
+	return Fingerprint(err)
+}
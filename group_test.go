@@ -0,0 +1,50 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GroupBy", func() {
+	var (
+		billing = flaw.NewNamespace("billing")
+		errs    []error
+	)
+
+	BeforeEach(func() {
+		errs = []error{
+			flaw.Errorf("user exists").WithCode(6).WithContext(flaw.Map{"user": "root"}),
+			flaw.Errorf("user exists").WithCode(6).WithContext(flaw.Map{"user": "admin"}),
+			billing.Errorf("internal").WithCode(13),
+			flaw.Errorf("teapot").WithCode(418),
+			fmt.Errorf("oh no"),
+			nil,
+		}
+	})
+
+	It("groups the errors by code", func() {
+		groups := flaw.GroupBy(errs, flaw.ByCode)
+		Expect(groups).To(HaveLen(4))
+		Expect(groups["AlreadyExists"]).To(HaveLen(2))
+		Expect(groups["Internal"]).To(HaveLen(1))
+		Expect(groups["418"]).To(HaveLen(1))
+		Expect(groups[""]).To(ConsistOf(errs[4]))
+	})
+
+	It("groups the errors by namespace", func() {
+		groups := flaw.GroupBy(errs, flaw.ByNamespace)
+		Expect(groups).To(HaveLen(2))
+		Expect(groups["billing"]).To(ConsistOf(errs[2]))
+		Expect(groups[""]).To(HaveLen(4))
+	})
+
+	It("groups the errors by fingerprint", func() {
+		groups := flaw.GroupBy(errs, flaw.ByFingerprint)
+		Expect(groups).To(HaveLen(4))
+		Expect(groups[flaw.Fingerprint(errs[0])]).To(HaveLen(2))
+	})
+})
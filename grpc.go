@@ -0,0 +1,131 @@
+//go:build !flaw_nogrpc
+
+package flaw
+
+import (
+	"encoding/json"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// GRPCStatus returns the grpc status of this error
+func (x *Error) GRPCStatus() *status.Status {
+	// the cache belongs to the frozen error only, the copies created by the
+	// With* methods share the pointer, but compute their own status
+	if cache := x.cache; cache != nil && cache.owner == x {
+		cache.once.Do(func() {
+			cache.status = x.grpcStatus()
+		})
+
+		return cache.status.(*status.Status)
+	}
+
+	return x.grpcStatus()
+}
+
+func (x *Error) grpcStatus() *status.Status {
+	type Provider interface {
+		GRPCStatus() *status.Status
+	}
+
+	if provider, ok := x.reason.(Provider); ok && !x.loops(x.reason) {
+		return provider.GRPCStatus()
+	}
+
+	code := codes.Internal
+
+	if x.code > 0 {
+		code = codes.Code(x.code)
+	}
+
+	payload := status.New(code, x.text())
+
+	// prepare the details
+	for _, item := range x.details {
+		// append the details
+		payload, _ = payload.WithDetails(&wrapperspb.StringValue{
+			Value: item,
+		})
+	}
+
+	if x.pointer != "" {
+		// the pointer locates the invalid field of the request
+		payload, _ = payload.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: x.pointer, Description: x.message()},
+			},
+		})
+	}
+
+	if context := x.plain(); len(context) > 0 {
+		// prepare the context
+		if details, err := structpb.NewStruct(context); err == nil {
+			// add the error as details
+			payload, _ = payload.WithDetails(details)
+		}
+	}
+
+	return payload
+}
+
+// plain returns the context with the pre-encoded json values decoded, the
+// durations and the times formatted and the errors as their messages, so they
+// can be converted to protobuf values
+func (x *Error) plain() map[string]interface{} {
+	m := make(map[string]interface{}, len(x.context))
+
+	for key, value := range x.context {
+		if !x.exported(key) {
+			continue
+		}
+
+		if raw, ok := value.(json.RawMessage); ok {
+			var item interface{}
+
+			if err := json.Unmarshal(raw, &item); err == nil {
+				value = item
+			}
+		}
+
+		if x.loops(value) {
+			value = cycleMarker
+		}
+
+		m[key] = protoValue(render(value))
+	}
+
+	if x.component != "" {
+		// the component is propagated to the receivers of the status
+		m[keyComponent] = x.component
+	}
+
+	return m
+}
+
+// protoValue returns the errors in the context as their messages, since
+// protobuf cannot represent them, e.g. the secondary errors of CloseWith
+func protoValue(value interface{}) interface{} {
+	switch item := value.(type) {
+	case ErrorCollector:
+		items := make([]interface{}, len(item))
+
+		for index, err := range item {
+			items[index] = protoValue(err)
+		}
+
+		return items
+	case error:
+		return clip(item.Error())
+	default:
+		return value
+	}
+}
+
+// rpcStatus returns the representation of the error for the gRPC transport
+func (x *Error) rpcStatus() interface{} {
+	return x.GRPCStatus()
+}
@@ -0,0 +1,10 @@
+//go:build flaw_nogrpc
+
+package flaw
+
+// rpcStatus returns nil, since the gRPC support is excluded by the flaw_nogrpc
+// build tag. A renderer of the gRPC transport can be registered by
+// RegisterTransport.
+func (x *Error) rpcStatus() interface{} {
+	return nil
+}
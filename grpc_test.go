@@ -0,0 +1,188 @@
+//go:build !flaw_nogrpc
+
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/phogolabs/flaw"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GRPCStatus", func() {
+	It("decodes the raw json context values", func() {
+		err := flaw.Errorf("failed").WithContext(flaw.Map{
+			"response": json.RawMessage(`{"id":42}`),
+		})
+
+		details := err.GRPCStatus().Details()
+		Expect(details).To(HaveLen(1))
+
+		context, ok := details[0].(*structpb.Struct)
+		Expect(ok).To(BeTrue())
+		Expect(context.AsMap()).To(HaveKeyWithValue("response", HaveKeyWithValue("id", BeNumerically("==", 42))))
+	})
+
+	It("adds the component", func() {
+		errx := flaw.Errorf("card declined").WithComponent("payment-service/worker")
+
+		details := errx.GRPCStatus().Details()
+		Expect(details).To(HaveLen(1))
+
+		context, ok := details[0].(*structpb.Struct)
+		Expect(ok).To(BeTrue())
+		Expect(context.AsMap()).To(HaveKeyWithValue("error_component", "payment-service/worker"))
+	})
+
+	It("adds a field violation for the pointer", func() {
+		errx := flaw.Errorf("price is negative").WithCode(3).WithPointer("/items/3/price")
+
+		details := errx.GRPCStatus().Details()
+		Expect(details).To(HaveLen(1))
+
+		request, ok := details[0].(*errdetails.BadRequest)
+		Expect(ok).To(BeTrue())
+		Expect(request.GetFieldViolations()).To(HaveLen(1))
+		Expect(request.GetFieldViolations()[0].GetField()).To(Equal("/items/3/price"))
+		Expect(request.GetFieldViolations()[0].GetDescription()).To(Equal("price is negative"))
+	})
+
+	It("omits the private keys", func() {
+		errx := flaw.Errorf("oh no").WithCode(13).WithContext(flaw.Map{
+			"request_id": "42",
+			"query":      "SELECT 1",
+		})
+
+		details := errx.WithPrivate("query").GRPCStatus().Details()
+		Expect(details).To(HaveLen(1))
+
+		context := details[0].(*structpb.Struct).AsMap()
+		Expect(context).To(HaveKey("request_id"))
+		Expect(context).NotTo(HaveKey("query"))
+	})
+
+	It("converts the time values", func() {
+		errx := flaw.Errorf("failed").WithContext(flaw.Map{
+			"elapsed": 1500 * time.Millisecond,
+			"at":      time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+		})
+
+		details := errx.GRPCStatus().Details()
+		Expect(details).To(HaveLen(1))
+
+		context, ok := details[0].(*structpb.Struct)
+		Expect(ok).To(BeTrue())
+		Expect(context.Fields["elapsed"].GetStringValue()).To(Equal("1.5s"))
+		Expect(context.Fields["at"].GetStringValue()).To(Equal("2024-05-01T10:30:00Z"))
+	})
+
+	It("adds the secondary errors of CloseWith as their messages", func() {
+		closer := &Closer{Err: fmt.Errorf("broken pipe")}
+
+		var err error = flaw.Errorf("primary").WithContext(flaw.Map{"user": "root"})
+		flaw.CloseWith(&err, closer, "closing body")
+
+		details := err.(*flaw.Error).GRPCStatus().Details()
+		Expect(details).To(HaveLen(1))
+
+		context, ok := details[0].(*structpb.Struct)
+		Expect(ok).To(BeTrue())
+		Expect(context.AsMap()).To(HaveKeyWithValue("user", "root"))
+		Expect(context.AsMap()).To(HaveKeyWithValue("error_secondary", ConsistOf("message: closing body cause: broken pipe")))
+	})
+
+	It("marks the error that causes itself", func() {
+		errx := flaw.Errorf("oh no")
+		errx.Wrap(errx)
+
+		Expect(errx.GRPCStatus().Message()).To(Equal("oh no: …(cycle)"))
+	})
+
+	It("does not format the cause", func() {
+		err := flaw.Errorf("upload failed").WithError(fmt.Errorf("disk is 100%s full", "%"))
+		Expect(err.GRPCStatus().Message()).To(Equal("upload failed: disk is 100% full"))
+	})
+
+	It("is returned as the gRPC representation", func() {
+		data := flaw.Errorf("invalid user").WithCode(3).Transport(flaw.TransportGRPC)
+		Expect(data).To(BeAssignableToTypeOf(&status.Status{}))
+		Expect(data.(*status.Status).Code()).To(Equal(codes.InvalidArgument))
+	})
+
+	Context("when the truncator is set", func() {
+		AfterEach(func() {
+			flaw.SetTruncator(nil)
+		})
+
+		It("truncates the message", func() {
+			flaw.SetTruncator(flaw.Ellipsis(12))
+
+			query := "SELECT " + strings.Repeat("id, ", 1000) + "name FROM users"
+			errx := flaw.Errorf("cannot run query %s", query).WithError(fmt.Errorf("syntax error in %s", query))
+
+			Expect(errx.GRPCStatus().Message()).To(Equal("cannot…users: syntax…users"))
+		})
+	})
+
+	Context("when the message builder is set", func() {
+		var err = flaw.Errorf("user not found").WithError(fmt.Errorf("sql: no rows"))
+
+		AfterEach(func() {
+			flaw.SetMessageBuilder(nil)
+		})
+
+		It("joins the message and the cause with a colon by default", func() {
+			Expect(err.GRPCStatus().Message()).To(Equal("user not found: sql: no rows"))
+		})
+
+		It("joins the message and the cause with the separator", func() {
+			flaw.SetMessageBuilder(flaw.JoinMessage(" <- "))
+			Expect(err.GRPCStatus().Message()).To(Equal("user not found <- sql: no rows"))
+		})
+
+		It("omits the cause", func() {
+			flaw.SetMessageBuilder(flaw.MessageOnly)
+			Expect(err.GRPCStatus().Message()).To(Equal("user not found"))
+		})
+
+		It("uses the cause if the error does not have a message", func() {
+			flaw.SetMessageBuilder(flaw.MessageOnly)
+			Expect(flaw.Wrap(fmt.Errorf("sql: no rows")).GRPCStatus().Message()).To(Equal("sql: no rows"))
+		})
+	})
+
+	Context("when the error is frozen", func() {
+		var errx *flaw.Error
+
+		BeforeEach(func() {
+			errx = flaw.Errorf("failed").WithCode(404).WithError(fmt.Errorf("oh no")).Freeze()
+		})
+
+		It("caches the grpc status", func() {
+			Expect(errx.GRPCStatus()).To(BeIdenticalTo(errx.GRPCStatus()))
+			Expect(errx.GRPCStatus().Message()).To(Equal("failed: oh no"))
+		})
+
+		It("does not share the grpc status with the copies", func() {
+			erry := errx.WithCode(5)
+			Expect(erry.GRPCStatus()).NotTo(BeIdenticalTo(errx.GRPCStatus()))
+			Expect(erry.GRPCStatus().Code()).To(BeEquivalentTo(5))
+		})
+	})
+
+	Context("when the error is not frozen", func() {
+		It("does not cache the grpc status", func() {
+			errx := flaw.Errorf("oh no")
+			Expect(errx.GRPCStatus()).NotTo(BeIdenticalTo(errx.GRPCStatus()))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package flaw
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const keyItems = "items"
+
+// keyed is an error of a collector that is identified by a key, e.g. the id
+// of the item in the request payload
+type keyed struct {
+	key string
+	err error
+}
+
+// Error returns the error message prefixed with the key
+func (x *keyed) Error() string {
+	return x.key + ": " + x.err.Error()
+}
+
+// Key returns the key of the error
+func (x *keyed) Key() string {
+	return x.key
+}
+
+// Unwrap returns the keyed error
+func (x *keyed) Unwrap() error {
+	return x.err
+}
+
+// WrapKey appends an error identified by given key to the slice. A collector
+// that contains keyed errors only is marshaled as {"items": {"key": ...}}, so
+// the clients do not need to correlate the positions of the errors with their
+// request. The keys are expected to be unique.
+func (errs *ErrorCollector) WrapKey(key string, err error) {
+	*errs = append(*errs, &keyed{key: key, err: err})
+}
+
+// Key returns the key of an error added to a collector by WrapKey
+func Key(err error) string {
+	type Keyer interface {
+		Key() string
+	}
+
+	if keyer, ok := err.(Keyer); ok {
+		return keyer.Key()
+	}
+
+	return ""
+}
+
+// keyed reports whether all errors are keyed
+func (errs ErrorCollector) keyed() bool {
+	for _, err := range errs {
+		if _, ok := err.(*keyed); !ok {
+			return false
+		}
+	}
+
+	return len(errs) > 0
+}
+
+// items returns the keyed errors by their keys. The errors that are not json
+// marshalers are replaced by their message.
+func (errs ErrorCollector) items() map[string]interface{} {
+	items := make(map[string]interface{}, len(errs))
+
+	for _, err := range errs {
+		item := err.(*keyed)
+
+		if _, ok := item.err.(json.Marshaler); ok {
+			items[item.key] = item.err
+		} else {
+			items[item.key] = item.err.Error()
+		}
+	}
+
+	return items
+}
+
+// decodeKeyed decodes the keyed errors of a collector
+func decodeKeyed(data []byte) (ErrorCollector, error) {
+	var payload struct {
+		Items map[string]json.RawMessage `json:"items"`
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(payload.Items))
+
+	for key := range payload.Items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	errs := make(ErrorCollector, 0, len(keys))
+
+	for _, key := range keys {
+		cause, err := decodeCause(payload.Items[key], "")
+		if err != nil {
+			return nil, err
+		}
+
+		if cause == nil {
+			return nil, fmt.Errorf("missing error of key %q", key)
+		}
+
+		errs.WrapKey(key, cause)
+	}
+
+	return errs, nil
+}
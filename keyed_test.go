@@ -0,0 +1,96 @@
+package flaw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WrapKey", func() {
+	var errs flaw.ErrorCollector
+
+	BeforeEach(func() {
+		errs = flaw.ErrorCollector{}
+		errs.WrapKey("42", flaw.Errorf("out of stock").WithCode(9))
+		errs.WrapKey("sku-9", fmt.Errorf("unknown sku"))
+	})
+
+	It("returns the key of the error", func() {
+		Expect(flaw.Key(errs[0])).To(Equal("42"))
+		Expect(flaw.Key(fmt.Errorf("oh no"))).To(BeEmpty())
+	})
+
+	It("keeps the keyed error in the chain", func() {
+		var errx *flaw.Error
+		Expect(errors.As(errs[0], &errx)).To(BeTrue())
+		Expect(errx.Code()).To(Equal(9))
+	})
+
+	It("prefixes the message with the key", func() {
+		Expect(errs[1].Error()).To(Equal("sku-9: unknown sku"))
+	})
+
+	It("marshals the errors by their keys", func() {
+		data, err := json.Marshal(errs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"items":{"42":{"error_code":9,"error_message":"out of stock"},"sku-9":"unknown sku"}}`))
+	})
+
+	It("encodes the errors by their keys", func() {
+		buffer := &bytes.Buffer{}
+		Expect(flaw.NewEncoder(buffer).Encode(errs)).To(Succeed())
+		Expect(buffer.String()).To(Equal(`{"items":{"42":{"error_code":9,"error_message":"out of stock"},"sku-9":"unknown sku"}}` + "\n"))
+	})
+
+	It("marshals the keys as xml attributes", func() {
+		data, err := xml.Marshal(flaw.Errorf("failed").WithError(errs))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`<Error Key="sku-9">unknown sku</Error>`))
+	})
+
+	It("decodes the keyed errors", func() {
+		data, err := json.Marshal(flaw.Errorf("failed").WithError(errs))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_cause_type":"collection"`))
+
+		errx := &flaw.Error{}
+		Expect(json.Unmarshal(data, errx)).To(Succeed())
+
+		items, ok := errx.Cause().(flaw.ErrorCollector)
+		Expect(ok).To(BeTrue())
+		Expect(items).To(HaveLen(2))
+		Expect(flaw.Key(items[0])).To(Equal("42"))
+		Expect(flaw.Key(items[1])).To(Equal("sku-9"))
+		Expect(items[1]).To(MatchError("sku-9: unknown sku"))
+	})
+
+	It("decodes the binary keyed errors", func() {
+		data, err := flaw.Errorf("failed").WithError(errs).MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		errx := &flaw.Error{}
+		Expect(errx.UnmarshalBinary(data)).To(Succeed())
+
+		items, ok := errx.Cause().(flaw.ErrorCollector)
+		Expect(ok).To(BeTrue())
+		Expect(flaw.Key(items[0])).To(Equal("42"))
+		Expect(flaw.Code(errors.Unwrap(items[0]))).To(Equal(9))
+	})
+
+	Context("when not all errors are keyed", func() {
+		It("marshals the errors as an array", func() {
+			errs = append(errs, fmt.Errorf("oh no"))
+
+			data, err := json.Marshal(errs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`["42: code: 9 message: out of stock","sku-9: unknown sku","oh no"]`))
+		})
+	})
+})
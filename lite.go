@@ -0,0 +1,51 @@
+package flaw
+
+import "fmt"
+
+// ErrorfLite creates a new error without a stack trace for the hot paths, e.g.
+// the validation loops that create thousands of errors per second. The
+// context map is not allocated until the context is set and the message is
+// not formatted until it's read, so the construction allocates the error
+// only. The arguments must not be modified after the call.
+func ErrorfLite(msg string, data ...interface{}) *Error {
+	if data == nil {
+		// the message is formatted as by Errorf even without arguments
+		data = []interface{}{}
+	}
+
+	stats.errors.Add(1)
+
+	return &Error{
+		status: 500,
+		msg:    msg,
+		values: data,
+	}
+}
+
+// WrapLite wraps an error without a stack trace for the hot paths. A flaw
+// error is returned as it is. The code and the status are inherited as by
+// Wrap, but the context map is not allocated until the context is set.
+func WrapLite(err error) *Error {
+	if errx, ok := err.(*Error); ok {
+		return errx
+	}
+
+	stats.errors.Add(1)
+
+	errx := &Error{
+		status: 500,
+		reason: err,
+	}
+
+	return errx.inherit()
+}
+
+// message returns the message of the error, which is formatted on read if the
+// error has been created by ErrorfLite
+func (x *Error) message() string {
+	if x.values != nil {
+		return fmt.Sprintf(x.msg, x.values...)
+	}
+
+	return x.msg
+}
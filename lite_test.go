@@ -0,0 +1,79 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorfLite", func() {
+	It("creates an error without a stack trace", func() {
+		err := flaw.ErrorfLite("user %v not found", 42)
+		Expect(err.Error()).To(Equal("message: user 42 not found"))
+		Expect(err.Message()).To(Equal("user 42 not found"))
+		Expect(err.Status()).To(Equal(500))
+		Expect(err.StackTrace()).To(BeEmpty())
+	})
+
+	It("formats the message without arguments as Errorf", func() {
+		err := flaw.ErrorfLite("100%% done")
+		Expect(err.Message()).To(Equal(flaw.Errorf("100%% done").Message()))
+	})
+
+	It("allocates the context on first use", func() {
+		err := flaw.ErrorfLite("user %v not found", 42).
+			WithContext(flaw.Map{"user_id": 42})
+
+		Expect(err.Context()).To(HaveKeyWithValue("user_id", 42))
+	})
+
+	It("marshals the formatted message", func() {
+		data, err := json.Marshal(flaw.ErrorfLite("user %v not found", 42))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_message":"user 42 not found"`))
+	})
+
+	It("keeps the message of WithMessage", func() {
+		err := flaw.ErrorfLite("user %v not found", 42).WithMessage("100%")
+		Expect(err.Message()).To(Equal("100%"))
+	})
+
+	It("allocates less than Errorf", func() {
+		lite := testing.AllocsPerRun(100, func() {
+			_ = flaw.ErrorfLite("user %v not found", "john")
+		})
+
+		full := testing.AllocsPerRun(100, func() {
+			_ = flaw.Errorf("user %v not found", "john")
+		})
+
+		Expect(lite).To(BeNumerically("<", full))
+	})
+})
+
+var _ = Describe("WrapLite", func() {
+	It("wraps an error without a stack trace", func() {
+		err := flaw.WrapLite(fmt.Errorf("oh no"))
+		Expect(err.Error()).To(Equal("cause: oh no"))
+		Expect(err.StackTrace()).To(BeEmpty())
+	})
+
+	It("returns a flaw error as it is", func() {
+		errx := flaw.Errorf("oh no")
+		Expect(flaw.WrapLite(errx)).To(BeIdenticalTo(errx))
+	})
+
+	It("classifies the wrapped error", func() {
+		_, cause := os.Open("/does/not/exist")
+
+		err := flaw.WrapLite(cause)
+		Expect(err.Status()).To(Equal(404))
+		Expect(err.Context()).To(HaveKeyWithValue("error_path", "/does/not/exist"))
+	})
+})
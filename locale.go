@@ -0,0 +1,146 @@
+package flaw
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ValueFormatter formats a value for the end users of the given locale, e.g.
+// a date or an amount of money. It returns false if it does not handle the
+// value. The details of the error are passed as strings, so a formatter can
+// translate them too.
+type ValueFormatter func(locale string, value interface{}) (string, bool)
+
+var (
+	formatters atomic.Value
+	formatting sync.Mutex
+)
+
+// RegisterFormatter registers the formatter of the values for the given locale,
+// e.g. "de-CH". The formatters of the locale are tried in the order of their
+// registration, then the formatters of the language, e.g. "de", and finally
+// the formatters registered for the empty locale.
+func RegisterFormatter(locale string, formatter ValueFormatter) {
+	formatting.Lock()
+	defer formatting.Unlock()
+
+	current, _ := formatters.Load().(map[string][]ValueFormatter)
+	// the map is copied, so the readers do not need a lock
+	next := make(map[string][]ValueFormatter, len(current)+1)
+
+	for key, items := range current {
+		next[key] = items
+	}
+
+	key := strings.ToLower(locale)
+	next[key] = append(next[key][:len(next[key]):len(next[key])], formatter)
+
+	formatters.Store(next)
+}
+
+// ResetFormatters removes all registered formatters
+func ResetFormatters() {
+	formatting.Lock()
+	defer formatting.Unlock()
+
+	formatters.Store(map[string][]ValueFormatter{})
+}
+
+// localize returns the value formatted for the locale. The values that none
+// of the formatters handles are rendered as in the other encoders.
+func localize(locale string, value interface{}) interface{} {
+	registry, _ := formatters.Load().(map[string][]ValueFormatter)
+
+	for _, key := range fallbacks(locale) {
+		for _, formatter := range registry[key] {
+			if text, ok := formatter(locale, value); ok {
+				return text
+			}
+		}
+	}
+
+	return render(value)
+}
+
+// fallbacks returns the locale, its language and the empty locale
+func fallbacks(locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	keys := []string{}
+
+	for locale != "" {
+		keys = append(keys, locale)
+
+		index := strings.LastIndex(locale, "-")
+		if index < 0 {
+			break
+		}
+
+		locale = locale[:index]
+	}
+
+	return append(keys, "")
+}
+
+var newest atomic.Bool
+
+// SetNewestDetailsFirst enables or disables the reverse order of the details
+// in the public representation returned by Localize, so the end users see the
+// most specific detail first. The details are ordered oldest first by default.
+func SetNewestDetailsFirst(enabled bool) {
+	newest.Store(enabled)
+}
+
+// Localize returns the public representation of the error for the end users
+// of the given locale. It contains the code, the message, the details and the
+// selected context values only, so the internals of the error are not exposed.
+// The details and the context values are formatted by the formatters
+// registered for the locale.
+func (x *Error) Localize(locale string, keys ...string) Map {
+	data := Map{
+		keyMessage: x.message(),
+	}
+
+	if x.code != 0 {
+		data[keyCode] = x.code
+	}
+
+	if len(x.details) > 0 {
+		details := make([]interface{}, len(x.details))
+
+		for index, detail := range x.details {
+			if newest.Load() {
+				index = len(x.details) - index - 1
+			}
+
+			details[index] = localize(locale, detail)
+		}
+
+		data[keyDetails] = details
+	}
+
+	for _, key := range keys {
+		if value, ok := x.context[key]; ok {
+			data[key] = localize(locale, value)
+		}
+	}
+
+	return data
+}
+
+// Localize returns the public representation of the error for the end users
+// of the given locale. The errors that are not flaw errors are wrapped first.
+func Localize(err error, locale string, keys ...string) Map {
+	if err == nil {
+		return nil
+	}
+
+	errx, ok := err.(*Error)
+	if !ok {
+		// the message of a foreign error is not exposed
+		errx = wrap(err)
+	}
+
+	return errx.Localize(locale, keys...)
+}
@@ -0,0 +1,99 @@
+package flaw_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type Money struct {
+	Amount   float64
+	Currency string
+}
+
+var _ = Describe("Localize", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("payment failed").
+			WithCode(402).
+			WithDetails("card declined").
+			WithContext(flaw.Map{
+				"amount":   Money{Amount: 12.5, Currency: "EUR"},
+				"deadline": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				"query":    "SELECT * FROM cards",
+			})
+
+		flaw.RegisterFormatter("de", func(locale string, value interface{}) (string, bool) {
+			switch item := value.(type) {
+			case Money:
+				return fmt.Sprintf("%.2f %s", item.Amount, item.Currency), true
+			case time.Time:
+				return item.Format("02.01.2006"), true
+			case string:
+				if item == "card declined" {
+					return "Karte abgelehnt", true
+				}
+			}
+
+			return "", false
+		})
+
+		flaw.RegisterFormatter("", func(locale string, value interface{}) (string, bool) {
+			if item, ok := value.(Money); ok {
+				return fmt.Sprintf("%s %.2f", item.Currency, item.Amount), true
+			}
+
+			return "", false
+		})
+	})
+
+	AfterEach(func() {
+		flaw.ResetFormatters()
+	})
+
+	It("formats the details and the selected context values", func() {
+		data := errx.Localize("de-CH", "amount", "deadline")
+		Expect(data).To(Equal(flaw.Map{
+			"error_code":    402,
+			"error_message": "payment failed",
+			"error_details": []interface{}{"Karte abgelehnt"},
+			"amount":        "12.50 EUR",
+			"deadline":      "01.05.2024",
+		}))
+	})
+
+	It("falls back to the formatters of the empty locale", func() {
+		data := errx.Localize("en_US", "amount", "deadline")
+		Expect(data).To(HaveKeyWithValue("amount", "EUR 12.50"))
+		Expect(data).To(HaveKeyWithValue("deadline", "2024-05-01T00:00:00Z"))
+		Expect(data).To(HaveKeyWithValue("error_details", []interface{}{"card declined"}))
+	})
+
+	It("orders the newest details first", func() {
+		flaw.SetNewestDetailsFirst(true)
+		defer flaw.SetNewestDetailsFirst(false)
+
+		data := errx.WithDetails("limit exceeded").Localize("de")
+		Expect(data).To(HaveKeyWithValue("error_details", []interface{}{"limit exceeded", "Karte abgelehnt"}))
+	})
+
+	It("does not expose the other context values", func() {
+		Expect(errx.Localize("de")).NotTo(HaveKey("query"))
+	})
+
+	Context("when the error is not a flaw error", func() {
+		It("does not expose the message", func() {
+			data := flaw.Localize(fmt.Errorf("sql: no rows"), "de")
+			Expect(data).To(Equal(flaw.Map{"error_message": ""}))
+		})
+
+		It("returns nil for a nil error", func() {
+			Expect(flaw.Localize(nil, "de")).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package flaw
+
+import "errors"
+
+// Mapper translates errors to error templates. It's usually used at the
+// service boundaries to translate the library errors.
+type Mapper struct {
+	rules []*MapperRule
+}
+
+// NewMapper creates a new mapper
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// On registers a rule for the given target error. The rule matches the
+// errors that satisfy errors.Is(err, target).
+func (m *Mapper) On(target error) *MapperRule {
+	rule := &MapperRule{
+		mapper: m,
+		target: target,
+	}
+
+	m.rules = append(m.rules, rule)
+	return rule
+}
+
+// Map maps the error to the template of the first matching rule. The mapped
+// error wraps the original one. If there is not a matching rule the error is
+// returned as it is.
+func (m *Mapper) Map(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, rule := range m.rules {
+		if rule.template == nil {
+			continue
+		}
+
+		if errors.Is(err, rule.target) {
+			errx := *rule.template
+			errx.reason = err
+			return errx.capture(1)
+		}
+	}
+
+	return err
+}
+
+// MapperRule represents a mapping between a target error and a template
+type MapperRule struct {
+	mapper   *Mapper
+	target   error
+	template *Error
+}
+
+// To sets the template of the rule
+func (r *MapperRule) To(template *Error) *Mapper {
+	r.template = template
+	return r.mapper
+}
@@ -0,0 +1,48 @@
+package flaw_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mapper", func() {
+	var mapper *flaw.Mapper
+
+	BeforeEach(func() {
+		mapper = flaw.NewMapper().
+			On(io.EOF).To(flaw.Errorf("stream ended").WithCode(5).WithStatus(404)).
+			On(io.ErrUnexpectedEOF).To(flaw.Errorf("stream interrupted").WithStatus(502))
+	})
+
+	It("maps the error successfully", func() {
+		err := mapper.Map(fmt.Errorf("read: %w", io.EOF))
+		Expect(err).To(MatchError("code: 5 message: stream ended cause: read: EOF"))
+		Expect(errors.Is(err, io.EOF)).To(BeTrue())
+		Expect(flaw.Status(err)).To(Equal(404))
+	})
+
+	It("maps the error by the first matching rule", func() {
+		err := mapper.Map(io.ErrUnexpectedEOF)
+		Expect(err).To(MatchError("message: stream interrupted cause: unexpected EOF"))
+		Expect(flaw.Status(err)).To(Equal(502))
+	})
+
+	Context("when the error does not match", func() {
+		It("returns the error", func() {
+			err := fmt.Errorf("oh no")
+			Expect(mapper.Map(err)).To(BeIdenticalTo(err))
+		})
+	})
+
+	Context("when the error is nil", func() {
+		It("returns nil", func() {
+			Expect(mapper.Map(nil)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,77 @@
+package flaw
+
+import "sync/atomic"
+
+// MessageBuilder builds the one line text of an error from its message and
+// the text of its cause, e.g. "user not found: sql: no rows". The builder is
+// called only when both are present, otherwise the one that is present is
+// used as it is.
+type MessageBuilder func(msg, cause string) string
+
+type messageBuilder struct {
+	build MessageBuilder
+}
+
+var builders atomic.Value
+
+// SetMessageBuilder sets the builder of the one line text of the errors, which
+// is used by the gRPC status message, by Summary and by the errors linked by
+// Chain. The message and the cause are joined by ": " by default. When a
+// builder is set, Error() and %v print the built text on the message line
+// instead of a separate cause line. The verbose %+v format is not affected.
+// Pass nil to restore the default.
+func SetMessageBuilder(builder MessageBuilder) {
+	builders.Store(messageBuilder{build: builder})
+}
+
+// JoinMessage returns a builder that joins the message and the cause with the
+// given separator
+func JoinMessage(separator string) MessageBuilder {
+	return func(msg, cause string) string {
+		return msg + separator + cause
+	}
+}
+
+// MessageOnly is a builder that omits the cause, so the internals of the
+// cause do not reach the clients. The cause is used if the error does not
+// have a message.
+func MessageOnly(msg, cause string) string {
+	return msg
+}
+
+// buildMessage returns the one line text of the given message and cause
+func buildMessage(msg, cause string) string {
+	switch {
+	case cause == "":
+		return msg
+	case msg == "":
+		return cause
+	}
+
+	if build := messageBuilderOf(); build != nil {
+		return build(msg, cause)
+	}
+
+	return msg + ": " + cause
+}
+
+// messageBuilderOf returns the builder set by SetMessageBuilder or nil
+func messageBuilderOf() MessageBuilder {
+	item, _ := builders.Load().(messageBuilder)
+	return item.build
+}
+
+// text returns the one line text of the error's message and cause
+func (x *Error) text() string {
+	var cause string
+
+	if x.reason != nil {
+		if x.loops(x.reason) {
+			cause = cycleMarker
+		} else {
+			cause = clip(x.reason.Error())
+		}
+	}
+
+	return buildMessage(clip(x.message()), cause)
+}
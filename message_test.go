@@ -0,0 +1,54 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetMessageBuilder", func() {
+	var (
+		cause = fmt.Errorf("sql: no rows")
+		err   = flaw.Errorf("user not found").WithError(cause)
+	)
+
+	AfterEach(func() {
+		flaw.SetMessageBuilder(nil)
+	})
+
+	It("joins the message and the cause with a colon by default", func() {
+		Expect(flaw.Summary(err)).To(Equal("user not found: sql: no rows"))
+		Expect(err.Error()).To(Equal("message: user not found cause: sql: no rows"))
+	})
+
+	It("joins the message and the cause with the separator", func() {
+		flaw.SetMessageBuilder(flaw.JoinMessage(" <- "))
+
+		Expect(flaw.Summary(err)).To(Equal("user not found <- sql: no rows"))
+		Expect(err.Error()).To(Equal("message: user not found <- sql: no rows"))
+		Expect(flaw.Chain(fmt.Errorf("query failed"), cause).Unwrap().Error()).To(Equal("query failed <- sql: no rows"))
+	})
+
+	It("omits the cause", func() {
+		flaw.SetMessageBuilder(flaw.MessageOnly)
+
+		Expect(flaw.Summary(err)).To(Equal("user not found"))
+		Expect(err.Error()).To(Equal("message: user not found"))
+		Expect(fmt.Sprintf("%v", err.WithCode(5))).To(Equal("code: 5 message: user not found"))
+	})
+
+	It("does not change the verbose format", func() {
+		flaw.SetMessageBuilder(flaw.MessageOnly)
+
+		Expect(fmt.Sprintf("%+v", err)).To(ContainSubstring("sql: no rows"))
+	})
+
+	It("prints the cause of the errors without a message", func() {
+		flaw.SetMessageBuilder(flaw.MessageOnly)
+
+		Expect(flaw.Wrap(cause).Error()).To(Equal("cause: sql: no rows"))
+	})
+})
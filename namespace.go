@@ -0,0 +1,108 @@
+package flaw
+
+import "fmt"
+
+const (
+	keyDomain   = "error_domain"
+	keyReporter = "error_reporter"
+)
+
+// Namespace creates errors that are tagged with the namespace name and share
+// the namespace defaults. The names of the codes of the errors are prefixed
+// with the namespace name, e.g. "billing.NotFound".
+type Namespace struct {
+	name     string
+	domain   string
+	reporter string
+	code     int
+	status   int
+}
+
+// NewNamespace creates a new namespace
+func NewNamespace(name string) *Namespace {
+	return &Namespace{
+		name:   name,
+		status: 500,
+	}
+}
+
+// WithCode creates a namespace copy with given default code
+func (n Namespace) WithCode(code int) *Namespace {
+	n.code = code
+	return &n
+}
+
+// WithStatus creates a namespace copy with given default status
+func (n Namespace) WithStatus(status int) *Namespace {
+	n.status = status
+	return &n
+}
+
+// WithDomain creates a namespace copy with given default domain, e.g.
+// "payments". The domain is put into the context of the errors.
+func (n Namespace) WithDomain(domain string) *Namespace {
+	n.domain = domain
+	return &n
+}
+
+// WithReporter creates a namespace copy with given default reporter, e.g. the
+// team that owns the errors. The reporter is put into the context of the
+// errors.
+func (n Namespace) WithReporter(reporter string) *Namespace {
+	n.reporter = reporter
+	return &n
+}
+
+// Name returns the namespace name
+func (n *Namespace) Name() string {
+	return n.name
+}
+
+// Errorf creates a new error in the namespace
+func (n *Namespace) Errorf(msg string, data ...interface{}) *Error {
+	errx := &Error{
+		msg: fmt.Sprintf(msg, data...),
+	}
+
+	return n.apply(errx.capture(1))
+}
+
+// Wrap wraps an error in the namespace. A flaw error is returned as it is. An
+// error which chain contains a flaw error gets a new wrapper, which inherits
+// the code and the status of the flaw error as by flaw.Wrap.
+func (n *Namespace) Wrap(err error) *Error {
+	if errx, ok := err.(*Error); ok {
+		return errx
+	}
+
+	errx := n.apply(&Error{reason: err}).inherit()
+	return errx.capture(1)
+}
+
+func (n *Namespace) apply(err *Error) *Error {
+	err.namespace = n.name
+	err.code = n.code
+	err.status = n.status
+
+	if n.domain != "" {
+		err.put(keyDomain, n.domain)
+	}
+
+	if n.reporter != "" {
+		err.put(keyReporter, n.reporter)
+	}
+
+	return err
+}
+
+// codeName returns the name of the gRPC code of the error, which is prefixed
+// with the namespace of the error, e.g. "billing.NotFound"
+func (x *Error) codeName() (string, bool) {
+	name, ok := codeName(x.code)
+
+	if ok && x.namespace != "" {
+		name = x.namespace + "." + name
+	}
+
+	return name, ok
+}
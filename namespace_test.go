@@ -0,0 +1,99 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace", func() {
+	var namespace *flaw.Namespace
+
+	BeforeEach(func() {
+		namespace = flaw.NewNamespace("billing").WithStatus(400).WithCode(3)
+	})
+
+	It("returns the name", func() {
+		Expect(namespace.Name()).To(Equal("billing"))
+	})
+
+	Describe("Errorf", func() {
+		It("creates an error successfully", func() {
+			err := namespace.Errorf("card %v declined", 42)
+			Expect(err).To(MatchError("code: 3 message: card 42 declined"))
+			Expect(err.Namespace()).To(Equal("billing"))
+			Expect(err.Status()).To(Equal(400))
+			Expect(err.StackTrace()).NotTo(BeEmpty())
+			Expect(err.Context()).To(HaveKeyWithValue("error_namespace", "billing"))
+		})
+	})
+
+	Describe("WithDomain", func() {
+		It("puts the domain into the context", func() {
+			err := namespace.WithDomain("payments").WithReporter("team-billing").Errorf("card declined")
+			Expect(err.Context()).To(HaveKeyWithValue("error_domain", "payments"))
+			Expect(err.Context()).To(HaveKeyWithValue("error_reporter", "team-billing"))
+		})
+
+		It("does not change the namespace", func() {
+			namespace.WithDomain("payments")
+
+			err := namespace.Errorf("card declined")
+			Expect(err.Context()).NotTo(HaveKey("error_domain"))
+		})
+	})
+
+	Describe("code names", func() {
+		AfterEach(func() {
+			flaw.SetCodeNames(false)
+		})
+
+		It("prefixes the code names with the namespace", func() {
+			err := namespace.Errorf("card declined")
+
+			Expect(fmt.Sprintf("%#j", err)).To(ContainSubstring(`"error_code_text":"billing.InvalidArgument"`))
+
+			flaw.SetCodeNames(true)
+			Expect(err).To(MatchError("code: billing.InvalidArgument (3) message: card declined"))
+		})
+	})
+
+	Describe("Wrap", func() {
+		It("wraps an error successfully", func() {
+			err := namespace.Wrap(fmt.Errorf("oh no"))
+			Expect(err).To(MatchError("code: 3 cause: oh no"))
+			Expect(err.Namespace()).To(Equal("billing"))
+			Expect(err.Status()).To(Equal(400))
+		})
+
+		It("puts the domain and the reporter into the context", func() {
+			err := namespace.WithDomain("payments").WithReporter("team-billing").Wrap(fmt.Errorf("oh no"))
+			Expect(err.Context()).To(HaveKeyWithValue("error_domain", "payments"))
+			Expect(err.Context()).To(HaveKeyWithValue("error_reporter", "team-billing"))
+		})
+
+		It("wraps an error which chain contains a flaw error", func() {
+			errx := flaw.Errorf("card declined").WithCode(9).WithStatus(402)
+
+			err := namespace.Wrap(fmt.Errorf("loading invoice: %w", errx))
+			Expect(err).NotTo(BeIdenticalTo(errx))
+			Expect(err.Cause()).To(MatchError(ContainSubstring("loading invoice")))
+			Expect(err.Namespace()).To(Equal("billing"))
+			Expect(err.Code()).To(Equal(9))
+			Expect(err.Status()).To(Equal(402))
+		})
+
+		Context("when the error is already wrapped", func() {
+			It("returns the error", func() {
+				errx := flaw.Errorf("oh no")
+
+				err := namespace.Wrap(errx)
+				Expect(err).To(BeIdenticalTo(errx))
+				Expect(err.Namespace()).To(BeEmpty())
+			})
+		})
+	})
+})
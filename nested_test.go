@@ -0,0 +1,75 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetNestedKeys", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		flaw.SetNestedKeys(true)
+
+		errx = flaw.Errorf("failed").WithContext(flaw.Map{
+			"db.query":       "SELECT 1",
+			"db.duration_ms": 10,
+			"db.pool.size":   4,
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SetNestedKeys(false)
+	})
+
+	It("marshals the dotted keys as nested json objects", func() {
+		data, err := json.Marshal(errx)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(`{"db":{"duration_ms":10,"pool":{"size":4},"query":"SELECT 1"},"error_message":"failed"}`))
+	})
+
+	It("marshals the dotted keys as nested xml elements", func() {
+		data, err := xml.Marshal(errx)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring("<Db>"))
+		Expect(string(data)).To(ContainSubstring("<Query>SELECT 1</Query>"))
+		Expect(string(data)).To(ContainSubstring("<Pool><Size>4</Size></Pool>"))
+	})
+
+	It("keeps the context flat", func() {
+		Expect(errx.Context()).To(HaveKeyWithValue("db.query", "SELECT 1"))
+	})
+
+	Context("when the keys conflict", func() {
+		BeforeEach(func() {
+			errx = flaw.Errorf("failed").WithContext(flaw.Map{
+				"db":       "postgres",
+				"db.query": "SELECT 1",
+				"cache.":   "redis",
+			})
+		})
+
+		It("keeps the conflicting keys flat", func() {
+			data, err := json.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`{"cache.":"redis","db":"postgres","db.query":"SELECT 1","error_message":"failed"}`))
+		})
+	})
+
+	Context("when the grouping is disabled", func() {
+		BeforeEach(func() {
+			flaw.SetNestedKeys(false)
+		})
+
+		It("marshals the dotted keys flat", func() {
+			data, err := json.Marshal(errx)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring(`"db.query":"SELECT 1"`))
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package flaw
+
+import "strings"
+
+const keyPointer = "error_pointer"
+
+// WithPointer creates an error copy with given JSON pointer (RFC 6901) of the
+// input element that has caused the error, e.g. "/items/3/price". The pointer
+// is marshaled under the "error_pointer" key and is added to the gRPC status
+// as the field of a BadRequest field violation.
+func (x Error) WithPointer(pointer string) *Error {
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		warnf("flaw: pointer %q does not start with /", pointer)
+	}
+
+	x.pointer = pointer
+	return &x
+}
+
+// Pointer returns the JSON pointer of the input element that has caused the
+// error
+func (x *Error) Pointer() string {
+	return x.pointer
+}
+
+// Pointer returns the JSON pointer of the input element that has caused the
+// error
+func Pointer(err error) string {
+	type Pointerer interface {
+		Pointer() string
+	}
+
+	if pointerer, ok := err.(Pointerer); ok {
+		return pointerer.Pointer()
+	}
+
+	return ""
+}
@@ -0,0 +1,55 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithPointer", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("price is negative").WithCode(3).WithPointer("/items/3/price")
+	})
+
+	It("returns the pointer", func() {
+		Expect(errx.Pointer()).To(Equal("/items/3/price"))
+		Expect(flaw.Pointer(errx)).To(Equal("/items/3/price"))
+		Expect(flaw.Pointer(fmt.Errorf("oh no"))).To(BeEmpty())
+	})
+
+	It("marshals the pointer", func() {
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_pointer":"/items/3/price"`))
+
+		decoded := &flaw.Error{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded.Pointer()).To(Equal("/items/3/price"))
+
+		data, err = errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded = &flaw.Error{}
+		Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+		Expect(decoded.Pointer()).To(Equal("/items/3/price"))
+	})
+
+	It("warns about an invalid pointer", func() {
+		warnings := []string{}
+
+		flaw.SetWarningHandler(func(msg string) {
+			warnings = append(warnings, msg)
+		})
+		defer flaw.SetWarningHandler(nil)
+
+		flaw.Errorf("oh no").WithPointer("items/3")
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring("pointer_test.go"))
+	})
+})
@@ -0,0 +1,153 @@
+package flaw
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// Decision is the outcome of a policy for an error at the service boundary
+type Decision struct {
+	// Level is the level the error is logged at
+	Level slog.Level
+	// Report is the list of the destinations the error is reported to, e.g.
+	// "sentry"
+	Report []string
+	// Message is the message that is visible to the client. The error message
+	// is visible if empty.
+	Message string
+	// Retry is true if the client might retry the operation
+	Retry bool
+	// RetryAfter is the backoff hint before the next attempt
+	RetryAfter time.Duration
+}
+
+// Policy decides how the errors are handled at the service boundaries, so
+// that the decisions are not spread over the middlewares. The rules are
+// evaluated in order.
+type Policy struct {
+	rules    []*PolicyRule
+	fallback Decision
+}
+
+// NewPolicy creates a new policy
+func NewPolicy() *Policy {
+	return &Policy{
+		fallback: Decision{Level: slog.LevelError},
+	}
+}
+
+// On registers a rule for the given target error. The rule matches the
+// errors that satisfy errors.Is(err, target). A nil target matches all
+// errors.
+func (p *Policy) On(target error) *PolicyRule {
+	rule := &PolicyRule{
+		policy:  p,
+		target:  target,
+		context: Map{},
+	}
+
+	p.rules = append(p.rules, rule)
+	return rule
+}
+
+// Otherwise sets the decision for the errors that do not match any rule. By
+// default they are logged at the error level.
+func (p *Policy) Otherwise(decision Decision) *Policy {
+	p.fallback = decision
+	return p
+}
+
+// Evaluate returns the decision of the first matching rule for the error
+func (p *Policy) Evaluate(err error) Decision {
+	for _, rule := range p.rules {
+		if rule.decision != nil && rule.match(err) {
+			return *rule.decision
+		}
+	}
+
+	return p.fallback
+}
+
+// PolicyRule matches the errors by their target, code, status, namespace and
+// context
+type PolicyRule struct {
+	policy    *Policy
+	target    error
+	code      int
+	status    int
+	namespace string
+	context   Map
+	decision  *Decision
+}
+
+// Code restricts the rule to the errors with given code
+func (r *PolicyRule) Code(code int) *PolicyRule {
+	r.code = code
+	return r
+}
+
+// Status restricts the rule to the errors with given status
+func (r *PolicyRule) Status(status int) *PolicyRule {
+	r.status = status
+	return r
+}
+
+// Namespace restricts the rule to the errors created by the namespace with
+// given name
+func (r *PolicyRule) Namespace(name string) *PolicyRule {
+	r.namespace = name
+	return r
+}
+
+// Context restricts the rule to the errors which context has given value
+func (r *PolicyRule) Context(key string, value interface{}) *PolicyRule {
+	r.context[key] = value
+	return r
+}
+
+// Decide sets the decision of the rule
+func (r *PolicyRule) Decide(decision Decision) *Policy {
+	r.decision = &decision
+	return r.policy
+}
+
+func (r *PolicyRule) match(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if r.target != nil && !errors.Is(err, r.target) {
+		return false
+	}
+
+	if r.code == 0 && r.status == 0 && r.namespace == "" && len(r.context) == 0 {
+		return true
+	}
+
+	var errx *Error
+
+	if !errors.As(err, &errx) {
+		return false
+	}
+
+	switch {
+	case r.code != 0 && r.code != errx.code:
+		return false
+	case r.status != 0 && r.status != errx.status:
+		return false
+	case r.namespace != "" && r.namespace != errx.namespace:
+		return false
+	}
+
+	context := errx.data()
+
+	for key, value := range r.context {
+		if item, ok := context[key]; !ok || !reflect.DeepEqual(item, value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+package flaw_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	var policy *flaw.Policy
+
+	BeforeEach(func() {
+		billing := flaw.Decision{
+			Level:      slog.LevelWarn,
+			Report:     []string{"sentry"},
+			Retry:      true,
+			RetryAfter: time.Second,
+		}
+
+		policy = flaw.NewPolicy().
+			On(io.EOF).Decide(flaw.Decision{Level: slog.LevelDebug}).
+			On(nil).Code(5).Decide(flaw.Decision{Level: slog.LevelInfo, Message: "not found"}).
+			On(nil).Namespace("billing").Status(503).Decide(billing).
+			On(nil).Context("tenant", "acme").Decide(flaw.Decision{Level: slog.LevelError, Report: []string{"pager"}})
+	})
+
+	It("decides by the target", func() {
+		decision := policy.Evaluate(fmt.Errorf("read: %w", io.EOF))
+		Expect(decision.Level).To(Equal(slog.LevelDebug))
+	})
+
+	It("decides by the code", func() {
+		decision := policy.Evaluate(flaw.Errorf("user not found").WithCode(5))
+		Expect(decision.Level).To(Equal(slog.LevelInfo))
+		Expect(decision.Message).To(Equal("not found"))
+	})
+
+	It("decides by the namespace and the status", func() {
+		billing := flaw.NewNamespace("billing").WithStatus(503)
+
+		decision := policy.Evaluate(billing.Errorf("gateway is down"))
+		Expect(decision.Level).To(Equal(slog.LevelWarn))
+		Expect(decision.Report).To(ConsistOf("sentry"))
+		Expect(decision.Retry).To(BeTrue())
+		Expect(decision.RetryAfter).To(Equal(time.Second))
+
+		decision = policy.Evaluate(flaw.Errorf("gateway is down").WithStatus(503))
+		Expect(decision.Report).To(BeEmpty())
+	})
+
+	It("decides by the context", func() {
+		err := flaw.Errorf("oh no").WithContext(flaw.Map{"tenant": "acme"})
+
+		decision := policy.Evaluate(fmt.Errorf("request: %w", err))
+		Expect(decision.Report).To(ConsistOf("pager"))
+	})
+
+	It("uses the first matching rule", func() {
+		err := flaw.Wrap(io.EOF).WithCode(5)
+		Expect(policy.Evaluate(err).Level).To(Equal(slog.LevelDebug))
+	})
+
+	Context("when no rule matches", func() {
+		It("logs the error at the error level", func() {
+			Expect(policy.Evaluate(fmt.Errorf("oh no"))).To(Equal(flaw.Decision{Level: slog.LevelError}))
+		})
+
+		It("returns the fallback decision", func() {
+			policy.Otherwise(flaw.Decision{Level: slog.LevelWarn})
+			Expect(policy.Evaluate(fmt.Errorf("oh no")).Level).To(Equal(slog.LevelWarn))
+		})
+	})
+})
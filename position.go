@@ -0,0 +1,136 @@
+package flaw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/phogolabs/flaw/format"
+)
+
+const keyPosition = "error_position"
+
+var positions atomic.Bool
+
+// SetCausePositions enables or disables the numbering of the errors in the
+// collectors. When enabled the verbose text format numbers the errors of the
+// nested collectors as a tree, e.g. 1., 1.1, 1.2 and 2., and the JSON
+// contains the same position of every error under the "error_position" key,
+// so the text and the JSON refer to the same error unambiguously. The errors
+// that are not flaw errors are marshaled as objects with a message and a
+// position. The numbering is disabled by default.
+func SetCausePositions(enabled bool) {
+	positions.Store(enabled)
+}
+
+// tree writes the errors numbered by their positions, e.g. " 1.2. oh no".
+// The errors of a nested collector follow the error that contains it.
+func (errs ErrorCollector) tree(w io.Writer, prefix string) {
+	for index, err := range errs {
+		if index > 0 {
+			fmt.Fprint(w, "\n")
+		}
+
+		position := prefix + strconv.Itoa(index+1)
+		text, children := branch(err)
+
+		fmt.Fprintf(w, " %s. %s", position, format.Bullet(text))
+
+		if len(children) > 0 {
+			fmt.Fprint(w, "\n")
+			children.tree(w, position+".")
+		}
+	}
+}
+
+// branch returns the text of the error without the collector in its chain, and
+// the errors of that collector
+func branch(err error) (string, ErrorCollector) {
+	switch item := err.(type) {
+	case ErrorCollector:
+		return "", item
+	case *keyed:
+		text, children := branch(item.err)
+		return item.key + ": " + text, children
+	case *Error:
+		if item.annotation || item.reason == nil || item.loops(item.reason) {
+			break
+		}
+
+		text, children := branch(item.reason)
+		if children == nil {
+			break
+		}
+
+		copy := *item
+		copy.reason = nil
+
+		if text != "" {
+			copy.reason = errors.New(text)
+		}
+
+		return fmt.Sprintf("%v", &copy), children
+	}
+
+	return err.Error(), nil
+}
+
+// positions returns the errors with their positions, e.g. "1.2"
+func (errs ErrorCollector) positions(prefix string) interface{} {
+	if errs.keyed() {
+		items := make(map[string]interface{}, len(errs))
+
+		for index, err := range errs {
+			item := err.(*keyed)
+			items[item.key] = positioned(item.err, prefix+strconv.Itoa(index+1))
+		}
+
+		return map[string]interface{}{keyItems: items}
+	}
+
+	items := make([]interface{}, len(errs))
+
+	for index, err := range errs {
+		items[index] = positioned(err, prefix+strconv.Itoa(index+1))
+	}
+
+	return items
+}
+
+// positioned returns the error with the given position
+func positioned(err error, position string) interface{} {
+	switch item := err.(type) {
+	case ErrorCollector:
+		return item.positions(position + ".")
+	case *Error:
+		data := item.chain(position)
+		data[keyPosition] = position
+		return data
+	default:
+		return dictionary{
+			keyMessage:  err.Error(),
+			keyPosition: position,
+		}
+	}
+}
+
+// chain returns the payload of the error, which collector cause has the
+// positions of the given parent
+func (x *Error) chain(parent string) dictionary {
+	data := x.payload()
+
+	if x.annotation || x.reason == nil || x.loops(x.reason) {
+		return data
+	}
+
+	switch cause := x.reason.(type) {
+	case ErrorCollector:
+		data[keyCause] = cause.positions(parent + ".")
+	case *Error:
+		data[keyCause] = cause.chain(parent)
+	}
+
+	return data
+}
@@ -0,0 +1,84 @@
+package flaw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetCausePositions", func() {
+	var errs flaw.ErrorCollector
+
+	BeforeEach(func() {
+		flaw.SetCausePositions(true)
+
+		errs = flaw.ErrorCollector{
+			fmt.Errorf("oh no"),
+			flaw.Errorf("import failed").WithError(flaw.ErrorCollector{
+				fmt.Errorf("row 1"),
+				flaw.Errorf("row 2"),
+			}),
+		}
+	})
+
+	AfterEach(func() {
+		flaw.SetCausePositions(false)
+	})
+
+	It("numbers the verbose text", func() {
+		Expect(fmt.Sprintf("%+v", errs)).To(Equal(" 1. oh no\n 2. message: import failed\n 2.1. row 1\n 2.2. message: row 2"))
+	})
+
+	It("numbers the collector in the chain", func() {
+		errx := flaw.Errorf("batch failed").WithError(errs)
+		Expect(fmt.Sprintf("%+v", errx)).To(ContainSubstring(" 2.1. row 1\n"))
+	})
+
+	It("includes the positions in the json", func() {
+		data, err := json.Marshal(errs)
+		Expect(err).NotTo(HaveOccurred())
+
+		items := []map[string]interface{}{}
+		Expect(json.Unmarshal(data, &items)).To(Succeed())
+		Expect(items).To(HaveLen(2))
+		Expect(items[0]).To(Equal(map[string]interface{}{"error_message": "oh no", "error_position": "1"}))
+		Expect(items[1]).To(HaveKeyWithValue("error_position", "2"))
+		Expect(items[1]).To(HaveKeyWithValue("error_cause", ContainElement(HaveKeyWithValue("error_position", "2.2"))))
+	})
+
+	It("includes the positions in the encoded json", func() {
+		buffer := &bytes.Buffer{}
+		Expect(flaw.NewEncoder(buffer).Encode(flaw.Errorf("batch failed").WithError(errs))).To(Succeed())
+
+		data, err := json.Marshal(flaw.Errorf("batch failed").WithError(errs))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(string(data) + "\n"))
+		Expect(buffer.String()).To(ContainSubstring(`"error_position":"2.1"`))
+	})
+
+	It("numbers the keyed errors", func() {
+		keyed := flaw.ErrorCollector{}
+		keyed.WrapKey("a", fmt.Errorf("oh no"))
+		keyed.WrapKey("b", fmt.Errorf("oh yes"))
+
+		Expect(fmt.Sprintf("%+v", keyed)).To(Equal(" 1. a: oh no\n 2. b: oh yes"))
+
+		data, err := json.Marshal(keyed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"items":{"a":{"error_message":"oh no","error_position":"1"},"b":{"error_message":"oh yes","error_position":"2"}}}`))
+	})
+
+	It("ignores the positions when decoding", func() {
+		data, err := json.Marshal(flaw.Errorf("batch failed").WithError(errs))
+		Expect(err).NotTo(HaveOccurred())
+
+		errx := &flaw.Error{}
+		Expect(json.Unmarshal(data, errx)).To(Succeed())
+		Expect(errx.Context()).NotTo(HaveKey("error_position"))
+	})
+})
@@ -0,0 +1,102 @@
+package flaw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+var (
+	_ io.WriterTo = &Error{}
+	_ fmt.State   = &printer{}
+)
+
+// FormatOption alters the format of Fprint
+type FormatOption uint8
+
+const (
+	// FormatVerbose prints the error as %+v does
+	FormatVerbose FormatOption = 1 << iota
+	// FormatStatus prints the error as %#v does
+	FormatStatus
+	// FormatJSON prints the error as %j does. FormatVerbose indents the json.
+	FormatJSON
+)
+
+// printer is a fmt.State that writes directly to the underlying writer
+type printer struct {
+	writer  io.Writer
+	options FormatOption
+	size    int64
+	err     error
+}
+
+// Write is the function to call to emit formatted output to be printed.
+func (p *printer) Write(data []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	n, err := p.writer.Write(data)
+	p.size += int64(n)
+	p.err = err
+	return n, err
+}
+
+// Width returns the value of the width option and whether it has been set.
+func (p *printer) Width() (int, bool) {
+	return 0, false
+}
+
+// Precision returns the value of the precision option and whether it has been set.
+func (p *printer) Precision() (int, bool) {
+	return 0, false
+}
+
+// Flag reports whether the flag c, a character, has been set.
+func (p *printer) Flag(c int) bool {
+	switch c {
+	case '+':
+		return p.options&FormatVerbose != 0
+	case '#':
+		return p.options&FormatStatus != 0
+	default:
+		return false
+	}
+}
+
+// Fprint formats the error according to the options and writes it to w
+// without building an intermediate string. It returns the number of bytes
+// written and any write error encountered.
+func Fprint(w io.Writer, err error, opts ...FormatOption) (int64, error) {
+	p := &printer{writer: w}
+
+	for _, opt := range opts {
+		p.options |= opt
+	}
+
+	formatter, ok := err.(fmt.Formatter)
+
+	switch {
+	case err == nil:
+		io.WriteString(p, "<nil>")
+	case p.options&FormatJSON != 0:
+		// only the package errors know how to format themselves as json
+		if _, marshaler := err.(json.Marshaler); ok && marshaler {
+			formatter.Format(p, 'j')
+		} else {
+			formatJSON(p, err.Error())
+		}
+	case ok:
+		formatter.Format(p, 'v')
+	default:
+		io.WriteString(p, err.Error())
+	}
+
+	return p.size, p.err
+}
+
+// WriteTo writes the error message to w. It implements io.WriterTo.
+func (x *Error) WriteTo(w io.Writer) (int64, error) {
+	return Fprint(w, x)
+}
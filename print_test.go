@@ -0,0 +1,115 @@
+package flaw_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fprint", func() {
+	var (
+		buffer *bytes.Buffer
+		errx   *flaw.Error
+	)
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		errx = flaw.Errorf("failed").WithCode(5).WithStatus(404).WithError(fmt.Errorf("oh no"))
+	})
+
+	It("prints the error successfully", func() {
+		n, err := flaw.Fprint(buffer, errx)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal("code: 5 message: failed cause: oh no"))
+		Expect(n).To(BeEquivalentTo(buffer.Len()))
+	})
+
+	It("prints the error verbosely", func() {
+		_, err := flaw.Fprint(buffer, errx, flaw.FormatVerbose)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(fmt.Sprintf("%+v", errx)))
+	})
+
+	It("prints the error with the status text", func() {
+		_, err := flaw.Fprint(buffer, errx, flaw.FormatStatus)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(fmt.Sprintf("%#v", errx)))
+	})
+
+	It("prints the error as json", func() {
+		_, err := flaw.Fprint(buffer, errx, flaw.FormatJSON)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(`{"error_cause":"oh no","error_code":5,"error_message":"failed"}`))
+	})
+
+	It("prints the error as indented json", func() {
+		_, err := flaw.Fprint(buffer, errx, flaw.FormatJSON, flaw.FormatVerbose)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(fmt.Sprintf("%+j", errx)))
+	})
+
+	It("prints the collector successfully", func() {
+		errs := flaw.ErrorCollector{fmt.Errorf("oh no"), fmt.Errorf("oh yes")}
+
+		_, err := flaw.Fprint(buffer, errs, flaw.FormatVerbose)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal(" --- oh no\n --- oh yes"))
+	})
+
+	Context("when the error is not a flaw error", func() {
+		It("prints the error message", func() {
+			_, err := flaw.Fprint(buffer, fmt.Errorf("oh no"))
+			Expect(err).To(BeNil())
+			Expect(buffer.String()).To(Equal("oh no"))
+		})
+
+		It("prints the error message as json", func() {
+			_, err := flaw.Fprint(buffer, fmt.Errorf("oh no"), flaw.FormatJSON)
+			Expect(err).To(BeNil())
+			Expect(buffer.String()).To(Equal(`"oh no"`))
+		})
+	})
+
+	Context("when the error is nil", func() {
+		It("prints nil", func() {
+			_, err := flaw.Fprint(buffer, nil)
+			Expect(err).To(BeNil())
+			Expect(buffer.String()).To(Equal("<nil>"))
+		})
+	})
+
+	Context("when the writer fails", func() {
+		It("returns the error", func() {
+			writer := &FailingWriter{}
+
+			n, err := flaw.Fprint(writer, errx)
+			Expect(err).To(MatchError(io.ErrShortWrite))
+			Expect(n).To(BeZero())
+		})
+	})
+})
+
+var _ = Describe("Error", func() {
+	Describe("WriteTo", func() {
+		It("writes the error successfully", func() {
+			errx := flaw.Errorf("failed").WithCode(5)
+			buffer := &bytes.Buffer{}
+
+			n, err := errx.WriteTo(buffer)
+			Expect(err).To(BeNil())
+			Expect(n).To(BeEquivalentTo(buffer.Len()))
+			Expect(buffer.String()).To(Equal(errx.Error()))
+		})
+	})
+})
+
+type FailingWriter struct{}
+
+func (w *FailingWriter) Write(data []byte) (int, error) {
+	return 0, io.ErrShortWrite
+}
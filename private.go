@@ -0,0 +1,82 @@
+package flaw
+
+import (
+	"sync/atomic"
+
+	"github.com/phogolabs/flaw/format"
+)
+
+type allowlist struct {
+	keys map[string]bool
+}
+
+var serializable atomic.Value
+
+// SerializeOnly restricts the context entries that are serialized by JSON,
+// XML, the gRPC status, the binary encoding and the transport renderers to
+// the given keys, e.g. flaw.SerializeOnly("request_id", "tenant"). The other
+// entries are still available in-process via Context. The fields of the
+// error, e.g. the code and the message, are not affected. All context entries
+// are serialized by default. Call it without keys to remove the restriction.
+func SerializeOnly(keys ...string) {
+	item := allowlist{}
+
+	if len(keys) > 0 {
+		item.keys = make(map[string]bool, len(keys))
+
+		for _, key := range keys {
+			item.keys[key] = true
+		}
+	}
+
+	serializable.Store(item)
+}
+
+// WithPrivate creates an error copy with the given context keys kept
+// in-process, so their entries are omitted from the serialized output
+func (x Error) WithPrivate(keys ...string) *Error {
+	items := append(format.StringSlice(nil), x.private...)
+
+	for _, key := range keys {
+		if !items.Contains(key) {
+			items = append(items, key)
+		}
+	}
+
+	x.private = items
+	return &x
+}
+
+// exported reports whether the context entry of the given key is serialized
+func (x *Error) exported(key string) bool {
+	if x.private.Contains(key) {
+		return false
+	}
+
+	if item, _ := serializable.Load().(allowlist); item.keys != nil {
+		return item.keys[key]
+	}
+
+	return true
+}
+
+// conceal removes the context entries that are not serialized from the data
+// of the error
+func (x *Error) conceal(data dictionary) dictionary {
+	items := []*Error{x}
+
+	if x.annotation {
+		// the context of the layers is merged into the data
+		items, _ = x.layers()
+	}
+
+	for _, item := range items {
+		for key := range item.context {
+			if !x.exported(key) || !item.exported(key) {
+				delete(data, key)
+			}
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,100 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Private", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("oh no").WithCode(13).WithContext(flaw.Map{
+			"request_id": "42",
+			"tenant":     "acme",
+			"query":      "SELECT 1",
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SerializeOnly()
+	})
+
+	Describe("WithPrivate", func() {
+		It("omits the private keys from the json", func() {
+			data, err := json.Marshal(errx.WithPrivate("query"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"error_code":13,"error_message":"oh no","request_id":"42","tenant":"acme"}`))
+		})
+
+		It("omits the private keys from the xml", func() {
+			data, err := xml.Marshal(errx.WithPrivate("query", "tenant"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).NotTo(ContainSubstring("SELECT"))
+			Expect(string(data)).NotTo(ContainSubstring("acme"))
+			Expect(string(data)).To(ContainSubstring("<RequestId>42</RequestId>"))
+		})
+
+		It("omits the private keys from the binary encoding", func() {
+			data, err := errx.WithPrivate("query").MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+
+			item := &flaw.Error{}
+			Expect(item.UnmarshalBinary(data)).To(Succeed())
+			Expect(item.Context()).To(HaveKey("request_id"))
+			Expect(item.Context()).NotTo(HaveKey("query"))
+		})
+
+		It("keeps the private keys in-process", func() {
+			Expect(errx.WithPrivate("query").Context()).To(HaveKeyWithValue("query", "SELECT 1"))
+		})
+
+		It("does not modify the original error", func() {
+			errx.WithPrivate("query")
+
+			data, err := json.Marshal(errx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("SELECT 1"))
+		})
+
+		It("omits the private keys of the annotation layers", func() {
+			err := flaw.Annotate(errx, "loading user", flaw.Map{"password": "secret"}).WithPrivate("password")
+
+			data, jerr := json.Marshal(err)
+			Expect(jerr).NotTo(HaveOccurred())
+			Expect(string(data)).NotTo(ContainSubstring("secret"))
+		})
+	})
+
+	Describe("SerializeOnly", func() {
+		It("serializes the allowed keys only", func() {
+			flaw.SerializeOnly("request_id", "tenant")
+
+			data, err := json.Marshal(errx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"error_code":13,"error_message":"oh no","request_id":"42","tenant":"acme"}`))
+		})
+
+		It("applies the private keys too", func() {
+			flaw.SerializeOnly("request_id", "tenant")
+
+			data, err := json.Marshal(errx.WithPrivate("tenant"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"error_code":13,"error_message":"oh no","request_id":"42"}`))
+		})
+
+		It("removes the restriction", func() {
+			flaw.SerializeOnly("request_id")
+			flaw.SerializeOnly()
+
+			data, err := json.Marshal(errx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("SELECT 1"))
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package flaw
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentTypeProblem is the media type of the problem details
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemDetails is the RFC 9457 representation of an error, which is served
+// as application/problem+json
+type ProblemDetails struct {
+	// Type is the URI that identifies the problem type. It's the runbook of
+	// the error or "about:blank".
+	Type string
+	// Title is the short summary of the problem type
+	Title string
+	// Status is the HTTP status code
+	Status int
+	// Detail is the explanation of this occurrence of the problem
+	Detail string
+	// Instance is the URI that identifies this occurrence of the problem,
+	// e.g. the path of the request
+	Instance string
+	// Extensions are the additional members of the problem
+	Extensions Map
+}
+
+var _ json.Marshaler = &ProblemDetails{}
+
+// MarshalJSON marshals the problem as json. The extensions are members of the
+// problem object, which cannot replace the standard members.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	data := make(map[string]interface{}, len(p.Extensions)+5)
+
+	for key, value := range p.Extensions {
+		data[key] = value
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			data[key] = value
+		} else {
+			delete(data, key)
+		}
+	}
+
+	set("type", p.Type)
+	set("title", p.Title)
+	set("detail", p.Detail)
+	set("instance", p.Instance)
+
+	if p.Status != 0 {
+		data["status"] = p.Status
+	} else {
+		delete(data, "status")
+	}
+
+	return json.Marshal(data)
+}
+
+// Problem returns the error as RFC 9457 problem details. The title is the
+// message of the error, the detail is the message of its cause and the
+// context, the details and the pointer are extensions. The instance is left
+// to the caller, since it's known by the request.
+func (x *Error) Problem() *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:   x.runbook,
+		Title:  clip(x.message()),
+		Status: x.status,
+	}
+
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	if cause := x.cause(); cause != nil {
+		if x.loops(cause) {
+			problem.Detail = cycleMarker
+		} else {
+			problem.Detail = clip(cause.Error())
+		}
+	}
+
+	data := x.conceal(x.data(keyMessage, keyRunbook, keyPointer, keyCause, keyStack, keyRemoteStack))
+
+	if x.pointer != "" {
+		data["pointer"] = x.pointer
+	}
+
+	if len(data) > 0 {
+		problem.Extensions = Map(data.render().nest())
+	}
+
+	return problem
+}
+
+// NewProblem returns the error as RFC 9457 problem details. The errors that
+// are not flaw errors are wrapped. It returns nil if err is nil.
+func NewProblem(err error) *ProblemDetails {
+	if err == nil {
+		return nil
+	}
+
+	errx, ok := err.(*Error)
+	if !ok {
+		errx = wrap(err)
+	}
+
+	return errx.Problem()
+}
@@ -0,0 +1,74 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProblemDetails", func() {
+	It("renders the error as problem details", func() {
+		errx := flaw.Errorf("invalid user").
+			WithCode(3).
+			WithStatus(422).
+			WithRunbook("https://example.com/runbooks/invalid-user").
+			WithPointer("/data/user").
+			WithDetails("the name is too short").
+			WithContext(flaw.Map{"user": "root"}).
+			WithError(fmt.Errorf("too short"))
+
+		problem := errx.Problem()
+		problem.Instance = "/users"
+
+		data, err := json.Marshal(problem)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"type": "https://example.com/runbooks/invalid-user",
+			"title": "invalid user",
+			"status": 422,
+			"detail": "too short",
+			"instance": "/users",
+			"pointer": "/data/user",
+			"error_code": 3,
+			"error_details": ["the name is too short"],
+			"user": "root"
+		}`))
+	})
+
+	It("uses the defaults of the blank problem type", func() {
+		problem := flaw.Errorf("").WithStatus(0).Problem()
+		Expect(problem.Type).To(Equal("about:blank"))
+		Expect(problem.Status).To(Equal(500))
+		Expect(problem.Title).To(Equal("Internal Server Error"))
+		Expect(problem.Extensions).To(BeEmpty())
+	})
+
+	It("does not replace the standard members by the extensions", func() {
+		problem := flaw.Errorf("oh no").WithContext(flaw.Map{"title": "boom", "instance": "/"}).Problem()
+
+		data, err := json.Marshal(problem)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{"type":"about:blank","title":"oh no","status":500}`))
+	})
+
+	Describe("NewProblem", func() {
+		It("wraps the error", func() {
+			problem := flaw.NewProblem(fmt.Errorf("oh no"))
+			Expect(problem.Title).To(Equal("Internal Server Error"))
+			Expect(problem.Detail).To(Equal("oh no"))
+		})
+
+		It("returns nil", func() {
+			Expect(flaw.NewProblem(nil)).To(BeNil())
+		})
+	})
+
+	It("is a transport", func() {
+		errx := flaw.Errorf("oh no")
+		Expect(errx.Transport(flaw.TransportProblem)).To(Equal(errx.Problem()))
+	})
+})
@@ -0,0 +1,57 @@
+//go:build race && !flaw_nogrpc
+
+package flaw_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sync"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// the specs are run by go test -race, which fails them on a data race
+var _ = Describe("Race", func() {
+	It("formats and marshals a shared frozen error", func() {
+		errx := flaw.Errorf("failed").
+			WithCode(404).
+			WithDetails("some more details").
+			WithContext(flaw.Map{"user": "root"}).
+			WithError(fmt.Errorf("oh no")).
+			Freeze()
+
+		group := sync.WaitGroup{}
+
+		for index := 0; index < 8; index++ {
+			group.Add(1)
+
+			go func(index int) {
+				defer GinkgoRecover()
+				defer group.Done()
+
+				Expect(fmt.Sprintf("%+v", errx)).NotTo(BeEmpty())
+				Expect(fmt.Sprintf("%#j", errx)).NotTo(BeEmpty())
+				Expect(errx.Error()).NotTo(BeEmpty())
+				Expect(errx.Fingerprint()).NotTo(BeEmpty())
+				Expect(errx.GRPCStatus()).NotTo(BeNil())
+
+				_, err := json.Marshal(errx)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = xml.Marshal(errx)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = errx.MarshalBinary()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(errx.WithDetails(fmt.Sprintf("detail %d", index)).Freeze().Frozen()).To(BeTrue())
+			}(index)
+		}
+
+		group.Wait()
+	})
+})
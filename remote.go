@@ -0,0 +1,54 @@
+package flaw
+
+const (
+	keyRemote      = "error_remote"
+	keyRemoteStack = "error_remote_stack"
+)
+
+// Remote is the service that an error has been received from
+type Remote struct {
+	// Service is the name of the remote service
+	Service string `json:"service"`
+	// Endpoint is the endpoint of the remote service, e.g. the gRPC method
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// WithRemote creates an error copy marked as received from the given service,
+// e.g. after it has been decoded from a response. The stack trace of the error
+// is kept as the remote stack trace and a fresh local stack trace is
+// captured, so the place where the error has originated is distinguished from
+// the place where it has been received. The remote stack trace of an error
+// that has crossed many hops is the one of the origin.
+func (x Error) WithRemote(service, endpoint string) *Error {
+	x.remote = Remote{Service: service, Endpoint: endpoint}
+
+	if x.remoteStack == nil {
+		x.remoteStack = x.stack
+	}
+
+	return x.trace(1)
+}
+
+// Remote returns the service that the error has been received from. It's
+// zero if the error is local.
+func (x *Error) Remote() Remote {
+	return x.remote
+}
+
+// RemoteStack returns the stack trace of the error in the remote service
+func (x *Error) RemoteStack() StackTrace {
+	return x.remoteStack
+}
+
+// IsRemote reports whether the error has been received from another service
+func IsRemote(err error) bool {
+	type Remoter interface {
+		Remote() Remote
+	}
+
+	if remoter, ok := err.(Remoter); ok {
+		return remoter.Remote().Service != ""
+	}
+
+	return false
+}
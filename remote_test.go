@@ -0,0 +1,75 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithRemote", func() {
+	var received *flaw.Error
+
+	BeforeEach(func() {
+		origin := flaw.Errorf("user not found").WithCode(5)
+
+		data, err := origin.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := &flaw.Error{}
+		Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+
+		received = decoded.WithRemote("users", "/users.v1.Users/Get")
+	})
+
+	It("keeps the stack of the origin as the remote stack", func() {
+		Expect(flaw.IsRemote(received)).To(BeTrue())
+		Expect(received.Remote()).To(Equal(flaw.Remote{Service: "users", Endpoint: "/users.v1.Users/Get"}))
+		Expect(received.RemoteStack()).NotTo(BeEmpty())
+		Expect(received.RemoteStack()[0].File).To(HaveSuffix("remote_test.go"))
+		Expect(received.StackTrace()).NotTo(BeEmpty())
+		Expect(received.StackTrace()[0].Line).NotTo(Equal(received.RemoteStack()[0].Line))
+	})
+
+	It("keeps the stack of the origin across the hops", func() {
+		stack := received.RemoteStack()
+
+		errx := received.WithRemote("gateway", "")
+		Expect(errx.Remote().Service).To(Equal("gateway"))
+		Expect(errx.RemoteStack()).To(Equal(stack))
+	})
+
+	It("prints the remote stack", func() {
+		Expect(fmt.Sprintf("%+v", received)).To(ContainSubstring("remote stack:"))
+		Expect(fmt.Sprintf("%v", received)).NotTo(ContainSubstring("remote stack:"))
+	})
+
+	It("marshals the remote", func() {
+		data, err := json.Marshal(received)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_remote":{"endpoint":"/users.v1.Users/Get","service":"users"}`))
+		Expect(string(data)).NotTo(ContainSubstring("error_remote_stack"))
+
+		decoded := &flaw.Error{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded.Remote()).To(Equal(received.Remote()))
+	})
+
+	It("encodes the remote stack", func() {
+		data, err := received.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := &flaw.Error{}
+		Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+		Expect(decoded.Remote()).To(Equal(received.Remote()))
+		Expect(decoded.RemoteStack()).To(HaveLen(len(received.RemoteStack())))
+	})
+
+	It("reports the local errors", func() {
+		Expect(flaw.IsRemote(flaw.Errorf("oh no"))).To(BeFalse())
+		Expect(flaw.IsRemote(fmt.Errorf("oh no"))).To(BeFalse())
+	})
+})
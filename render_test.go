@@ -0,0 +1,66 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Time values", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("failed").WithContext(flaw.Map{
+			"elapsed": 1500 * time.Millisecond,
+			"at":      time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+			"db": flaw.Map{
+				"timeout": 2 * time.Second,
+			},
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SetTimeLayout("")
+		flaw.SetNumericDurations(false)
+	})
+
+	It("marshals the values as json", func() {
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"elapsed":"1.5s"`))
+		Expect(string(data)).To(ContainSubstring(`"at":"2024-05-01T10:30:00Z"`))
+		Expect(string(data)).To(ContainSubstring(`"db":{"timeout":"2s"}`))
+	})
+
+	It("marshals the values as xml", func() {
+		data, err := xml.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`<Elapsed>1.5s</Elapsed>`))
+		Expect(string(data)).To(ContainSubstring(`<At>2024-05-01T10:30:00Z</At>`))
+	})
+
+	It("keeps the values in the context", func() {
+		Expect(errx.Context()).To(HaveKeyWithValue("elapsed", 1500*time.Millisecond))
+	})
+
+	It("uses the time layout", func() {
+		flaw.SetTimeLayout(time.DateOnly)
+
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"at":"2024-05-01"`))
+	})
+
+	It("marshals the durations as nanoseconds", func() {
+		flaw.SetNumericDurations(true)
+
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"elapsed":1500000000`))
+	})
+})
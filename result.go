@@ -0,0 +1,162 @@
+package flaw
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Result holds either the value or the error of an operation, e.g. the
+// outcome of an item in a batch API or a value sent over a channel. The error
+// keeps its structure, so it's encoded as any other error.
+type Result[T interface{}] struct {
+	value T
+	err   *Error
+}
+
+// Ok returns a successful result with the given value
+func Ok[T interface{}](value T) Result[T] {
+	return Result[T]{value: value}
+}
+
+// Fail returns a failed result with the given error. The errors that are not
+// flaw errors are wrapped. A nil error is wrapped too, so the result is
+// always failed.
+func Fail[T interface{}](err error) Result[T] {
+	errx, ok := err.(*Error)
+
+	if !ok {
+		errx = wrap(err).capture(1)
+	}
+
+	return Result[T]{err: errx}
+}
+
+// ResultOf returns the result of a function that returns a value and an
+// error, e.g. flaw.ResultOf(repository.GetUser(id)). The result is failed if
+// the error is not nil.
+func ResultOf[T interface{}](value T, err error) Result[T] {
+	if err == nil {
+		return Result[T]{value: value}
+	}
+
+	errx, ok := err.(*Error)
+
+	if !ok {
+		errx = wrap(err).capture(1)
+	}
+
+	return Result[T]{err: errx}
+}
+
+// OK reports whether the result is successful
+func (r Result[T]) OK() bool {
+	return r.err == nil
+}
+
+// Value returns the value of the result. It's the zero value if the result is
+// failed.
+func (r Result[T]) Value() T {
+	return r.value
+}
+
+// Err returns the error of the result. It's nil if the result is successful.
+func (r Result[T]) Err() *Error {
+	return r.err
+}
+
+// Get returns the value and the error of the result
+func (r Result[T]) Get() (T, error) {
+	if r.err != nil {
+		return r.value, r.err
+	}
+
+	return r.value, nil
+}
+
+// resultJSON is the json representation of a result
+type resultJSON[T interface{}] struct {
+	Value *T     `json:"value,omitempty"`
+	Error *Error `json:"error,omitempty"`
+}
+
+// MarshalJSON marshals the result as {"value": ...} or {"error": ...}
+func (r Result[T]) MarshalJSON() ([]byte, error) {
+	if r.err != nil {
+		return json.Marshal(resultJSON[T]{Error: r.err})
+	}
+
+	return json.Marshal(resultJSON[T]{Value: &r.value})
+}
+
+// UnmarshalJSON unmarshals the result from {"value": ...} or {"error": ...}
+func (r *Result[T]) UnmarshalJSON(data []byte) error {
+	item := resultJSON[T]{}
+
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+
+	switch {
+	case item.Error != nil:
+		*r = Result[T]{err: item.Error}
+	case item.Value != nil:
+		*r = Result[T]{value: *item.Value}
+	default:
+		return fmt.Errorf("flaw: result has neither a value nor an error")
+	}
+
+	return nil
+}
+
+// MapResult returns the result of the function applied to the value of a
+// successful result. A failed result is returned with the same error.
+func MapResult[T, U interface{}](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+
+	return Result[U]{value: fn(r.value)}
+}
+
+// ThenResult returns the result of the function that can fail applied to the
+// value of a successful result. A failed result is returned with the same
+// error.
+func ThenResult[T, U interface{}](r Result[T], fn func(T) (U, error)) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+
+	value, err := fn(r.value)
+
+	if err == nil {
+		return Result[U]{value: value}
+	}
+
+	errx, ok := err.(*Error)
+
+	if !ok {
+		errx = wrap(err).capture(1)
+	}
+
+	return Result[U]{err: errx}
+}
+
+// CollectResults returns the values of the successful results and the
+// collector of the errors of the failed ones, which is nil if all results are
+// successful.
+func CollectResults[T interface{}](results []Result[T]) ([]T, ErrorCollector) {
+	var (
+		values = make([]T, 0, len(results))
+		errs   ErrorCollector
+	)
+
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+		} else {
+			values = append(values, result.value)
+		}
+	}
+
+	return values, errs
+}
@@ -0,0 +1,126 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Result", func() {
+	It("holds the value", func() {
+		result := flaw.Ok(42)
+		Expect(result.OK()).To(BeTrue())
+		Expect(result.Value()).To(Equal(42))
+		Expect(result.Err()).To(BeNil())
+
+		value, err := result.Get()
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal(42))
+	})
+
+	It("holds the error", func() {
+		result := flaw.Fail[int](fmt.Errorf("oh no"))
+		Expect(result.OK()).To(BeFalse())
+		Expect(result.Value()).To(BeZero())
+		Expect(result.Err()).To(MatchError(ContainSubstring("oh no")))
+		Expect(result.Err().StackTrace()).NotTo(BeEmpty())
+
+		_, err := result.Get()
+		Expect(err).To(MatchError(ContainSubstring("oh no")))
+	})
+
+	It("keeps the flaw error", func() {
+		errx := flaw.Errorf("oh no").WithCode(5)
+		Expect(flaw.Fail[int](errx).Err()).To(BeIdenticalTo(errx))
+	})
+
+	It("creates the result of a function", func() {
+		Expect(flaw.ResultOf(strconv.Atoi("42")).Value()).To(Equal(42))
+		Expect(flaw.ResultOf(strconv.Atoi("x")).OK()).To(BeFalse())
+	})
+
+	Describe("MapResult", func() {
+		It("maps the value", func() {
+			result := flaw.MapResult(flaw.Ok(42), strconv.Itoa)
+			Expect(result.Value()).To(Equal("42"))
+		})
+
+		It("keeps the error", func() {
+			errx := flaw.Errorf("oh no")
+			result := flaw.MapResult(flaw.Fail[int](errx), strconv.Itoa)
+			Expect(result.Err()).To(BeIdenticalTo(errx))
+		})
+	})
+
+	Describe("ThenResult", func() {
+		It("chains the function", func() {
+			result := flaw.ThenResult(flaw.Ok("42"), strconv.Atoi)
+			Expect(result.Value()).To(Equal(42))
+		})
+
+		It("fails with the error of the function", func() {
+			result := flaw.ThenResult(flaw.Ok("x"), strconv.Atoi)
+			Expect(result.OK()).To(BeFalse())
+			Expect(result.Err()).To(MatchError(ContainSubstring("invalid syntax")))
+		})
+
+		It("keeps the error", func() {
+			errx := flaw.Errorf("oh no")
+			result := flaw.ThenResult(flaw.Fail[string](errx), strconv.Atoi)
+			Expect(result.Err()).To(BeIdenticalTo(errx))
+		})
+	})
+
+	Describe("CollectResults", func() {
+		It("collects the values and the errors", func() {
+			errx := flaw.Errorf("oh no")
+			values, errs := flaw.CollectResults([]flaw.Result[int]{flaw.Ok(1), flaw.Fail[int](errx), flaw.Ok(2)})
+			Expect(values).To(Equal([]int{1, 2}))
+			Expect(errs).To(Equal(flaw.ErrorCollector{errx}))
+		})
+
+		It("returns nil when there are no errors", func() {
+			values, errs := flaw.CollectResults([]flaw.Result[int]{flaw.Ok(1)})
+			Expect(values).To(Equal([]int{1}))
+			Expect(errs).To(BeNil())
+		})
+	})
+
+	Describe("JSON", func() {
+		It("marshals the value", func() {
+			data, err := json.Marshal(flaw.Ok(0))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"value":0}`))
+		})
+
+		It("marshals the error", func() {
+			data, err := json.Marshal(flaw.Fail[int](flaw.Errorf("oh no").WithCode(5)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"error":{"error_code":5,"error_message":"oh no"}}`))
+		})
+
+		It("round-trips the results", func() {
+			input := []flaw.Result[string]{flaw.Ok("a"), flaw.Fail[string](flaw.Errorf("oh no").WithCode(5))}
+
+			data, err := json.Marshal(input)
+			Expect(err).NotTo(HaveOccurred())
+
+			output := []flaw.Result[string]{}
+			Expect(json.Unmarshal(data, &output)).To(Succeed())
+			Expect(output).To(HaveLen(2))
+			Expect(output[0].Value()).To(Equal("a"))
+			Expect(output[1].Err().Code()).To(Equal(5))
+			Expect(output[1].Err().Message()).To(Equal("oh no"))
+		})
+
+		It("returns an error for an empty object", func() {
+			result := flaw.Result[int]{}
+			Expect(json.Unmarshal([]byte(`{}`), &result)).To(MatchError(ContainSubstring("neither")))
+		})
+	})
+})
@@ -0,0 +1,174 @@
+package flaw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DefaultBackoff is the backoff of the retry policies, unless the error
+// provides its own
+const DefaultBackoff = 100 * time.Millisecond
+
+// RetryPolicy decides whether a failed operation should be retried and how
+// long to wait before the next attempt
+type RetryPolicy struct {
+	codes    map[int]bool
+	statuses map[int]bool
+	backoff  time.Duration
+}
+
+// NewRetryPolicy creates a new retry policy. By default the errors with the
+// Unavailable, ResourceExhausted and Aborted codes, and the errors with the
+// 429, 502, 503 and 504 statuses are retried.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		codes: map[int]bool{
+			int(codeUnavailable):       true,
+			int(codeResourceExhausted): true,
+			int(codeAborted):           true,
+		},
+		statuses: map[int]bool{
+			http.StatusTooManyRequests:    true,
+			http.StatusBadGateway:         true,
+			http.StatusServiceUnavailable: true,
+			http.StatusGatewayTimeout:     true,
+		},
+		backoff: DefaultBackoff,
+	}
+}
+
+// WithCode creates a policy copy that retries or not the errors with given code
+func (p RetryPolicy) WithCode(code int, retry bool) *RetryPolicy {
+	p.codes = override(p.codes, code, retry)
+	return &p
+}
+
+// WithStatus creates a policy copy that retries or not the errors with given
+// status
+func (p RetryPolicy) WithStatus(status int, retry bool) *RetryPolicy {
+	p.statuses = override(p.statuses, status, retry)
+	return &p
+}
+
+// WithBackoff creates a policy copy with given default backoff
+func (p RetryPolicy) WithBackoff(backoff time.Duration) *RetryPolicy {
+	p.backoff = backoff
+	return &p
+}
+
+// Retry reports whether the operation that failed with given error should be
+// retried and the backoff before the next attempt. The decision is based on
+// the following in order:
+//
+//   - the canceled operations are never retried
+//   - the Retryable() bool or Temporary() bool method of an error in the chain
+//   - the code of the first flaw error in the chain, or its status if the
+//     policy does not know the code
+//
+// The backoff is taken from the RetryAfter() time.Duration method of an error
+// in the chain if present.
+func (p *RetryPolicy) Retry(err error) (bool, time.Duration) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false, 0
+	}
+
+	if !p.retry(err) {
+		return false, 0
+	}
+
+	var delayer interface {
+		RetryAfter() time.Duration
+	}
+
+	if errors.As(err, &delayer) {
+		if backoff := delayer.RetryAfter(); backoff > 0 {
+			return true, backoff
+		}
+	}
+
+	return true, p.backoff
+}
+
+func (p *RetryPolicy) retry(err error) bool {
+	var retrier interface {
+		Retryable() bool
+	}
+
+	if errors.As(err, &retrier) {
+		return retrier.Retryable()
+	}
+
+	var temporary interface {
+		Temporary() bool
+	}
+
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+
+	var errx *Error
+
+	if errors.As(err, &errx) {
+		// the code has precedence over the status when the policy knows it
+		if retry, ok := p.codes[errx.code]; ok {
+			return retry
+		}
+
+		return p.statuses[errx.status]
+	}
+
+	return false
+}
+
+// override returns a copy of the map with given key set
+func override(m map[int]bool, key int, value bool) map[int]bool {
+	items := make(map[int]bool, len(m)+1)
+
+	for k, v := range m {
+		items[k] = v
+	}
+
+	items[key] = value
+	return items
+}
+
+// IsHedgeable reports whether a hedged request, which is a duplicate of the
+// failed or slow request, might succeed. The timeouts, the Unavailable and
+// DeadlineExceeded codes and the 503 and 504 statuses are hedgeable. The
+// canceled operations are not.
+func IsHedgeable(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+
+	var timeout interface {
+		Timeout() bool
+	}
+
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+
+	var errx *Error
+
+	if errors.As(err, &errx) {
+		switch grpcCode(errx.code) {
+		case codeUnavailable, codeDeadlineExceeded:
+			return true
+		}
+
+		switch errx.status {
+		case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+
+	return false
+}
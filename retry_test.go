@@ -0,0 +1,137 @@
+package flaw_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	var policy *flaw.RetryPolicy
+
+	BeforeEach(func() {
+		policy = flaw.NewRetryPolicy()
+	})
+
+	It("retries the errors with retryable code", func() {
+		retry, backoff := policy.Retry(flaw.Errorf("oh no").WithCode(14))
+		Expect(retry).To(BeTrue())
+		Expect(backoff).To(Equal(flaw.DefaultBackoff))
+	})
+
+	It("retries the errors with retryable status", func() {
+		retry, _ := policy.Retry(flaw.Errorf("oh no").WithStatus(http.StatusServiceUnavailable))
+		Expect(retry).To(BeTrue())
+	})
+
+	It("does not retry the other errors", func() {
+		retry, backoff := policy.Retry(flaw.Errorf("oh no").WithCode(5).WithStatus(404))
+		Expect(retry).To(BeFalse())
+		Expect(backoff).To(BeZero())
+
+		retry, _ = policy.Retry(fmt.Errorf("oh no"))
+		Expect(retry).To(BeFalse())
+
+		retry, _ = policy.Retry(nil)
+		Expect(retry).To(BeFalse())
+	})
+
+	It("does not retry the canceled operations", func() {
+		retry, _ := policy.Retry(flaw.Errorf("oh no").WithCode(14).WithError(context.Canceled))
+		Expect(retry).To(BeFalse())
+	})
+
+	It("consults the retryable errors in the chain", func() {
+		err := flaw.Errorf("oh no").WithCode(5).WithError(&RetryableError{Retry: true, After: time.Second})
+
+		retry, backoff := policy.Retry(err)
+		Expect(retry).To(BeTrue())
+		Expect(backoff).To(Equal(time.Second))
+
+		retry, _ = policy.Retry(flaw.Wrap(&RetryableError{Retry: false}).WithCode(14))
+		Expect(retry).To(BeFalse())
+	})
+
+	Context("when the policy is overridden", func() {
+		It("retries according to the overrides", func() {
+			custom := policy.
+				WithCode(14, false).
+				WithStatus(http.StatusInternalServerError, true).
+				WithBackoff(time.Second)
+
+			retry, _ := custom.Retry(flaw.Errorf("oh no").WithCode(14))
+			Expect(retry).To(BeFalse())
+
+			retry, backoff := custom.Retry(flaw.Errorf("oh no"))
+			Expect(retry).To(BeTrue())
+			Expect(backoff).To(Equal(time.Second))
+
+			retry, _ = policy.Retry(flaw.Errorf("oh no").WithCode(14))
+			Expect(retry).To(BeTrue())
+		})
+	})
+})
+
+type RetryableError struct {
+	Retry bool
+	After time.Duration
+}
+
+func (err *RetryableError) Error() string {
+	return "retryable"
+}
+
+func (err *RetryableError) Retryable() bool {
+	return err.Retry
+}
+
+func (err *RetryableError) RetryAfter() time.Duration {
+	return err.After
+}
+
+var _ = Describe("IsHedgeable", func() {
+	It("returns true for the latency related errors", func() {
+		Expect(flaw.IsHedgeable(flaw.Errorf("oh no").WithCode(14))).To(BeTrue())
+		Expect(flaw.IsHedgeable(flaw.Errorf("oh no").WithStatus(http.StatusGatewayTimeout))).To(BeTrue())
+		Expect(flaw.IsHedgeable(fmt.Errorf("query: %w", context.DeadlineExceeded))).To(BeTrue())
+		Expect(flaw.IsHedgeable(&TimeoutError{})).To(BeTrue())
+	})
+
+	It("returns false for the other errors", func() {
+		Expect(flaw.IsHedgeable(nil)).To(BeFalse())
+		Expect(flaw.IsHedgeable(fmt.Errorf("oh no"))).To(BeFalse())
+		Expect(flaw.IsHedgeable(flaw.Errorf("oh no").WithCode(5).WithStatus(404))).To(BeFalse())
+		Expect(flaw.IsHedgeable(flaw.Errorf("oh no").WithCode(14).WithError(context.Canceled))).To(BeFalse())
+	})
+})
+
+var _ = Describe("WithFallbackUsed", func() {
+	It("marks the fallback in the context", func() {
+		err := flaw.Errorf("oh no").WithFallbackUsed("cache")
+		Expect(flaw.FallbackUsed(err)).To(Equal("cache"))
+		Expect(flaw.Context(err)).To(HaveKeyWithValue("error_fallback", "cache"))
+	})
+
+	Context("when the fallback is not used", func() {
+		It("returns an empty name", func() {
+			Expect(flaw.FallbackUsed(fmt.Errorf("oh no"))).To(BeEmpty())
+			Expect(flaw.Context(flaw.Errorf("oh no"))).NotTo(HaveKey("error_fallback"))
+		})
+	})
+})
+
+type TimeoutError struct{}
+
+func (err *TimeoutError) Error() string {
+	return "i/o timeout"
+}
+
+func (err *TimeoutError) Timeout() bool {
+	return true
+}
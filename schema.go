@@ -0,0 +1,211 @@
+package flaw
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ValueType is the type of a context value in a schema
+type ValueType uint8
+
+const (
+	// String is a string value. The numbers and the booleans are converted to
+	// strings.
+	String ValueType = iota + 1
+	// Int is an int64 value. The whole floats and the numeric strings are
+	// converted to int64.
+	Int
+	// Float is a float64 value. The integers and the numeric strings are
+	// converted to float64.
+	Float
+	// Bool is a bool value. The strings accepted by strconv.ParseBool are
+	// converted to bool.
+	Bool
+	// Duration is a time.Duration value. The strings accepted by
+	// time.ParseDuration are converted to time.Duration.
+	Duration
+	// Timestamp is a time.Time value. The RFC 3339 strings are converted to
+	// time.Time.
+	Timestamp
+)
+
+// String returns the name of the type
+func (t ValueType) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Duration:
+		return "duration"
+	case Timestamp:
+		return "timestamp"
+	default:
+		return "unknown"
+	}
+}
+
+// ContextSchema declares the types of the context values, so a key is always
+// serialized with the same type, e.g. flaw.ContextSchema{"user_id":
+// flaw.String, "attempt": flaw.Int}. The keys that are not declared are not
+// validated.
+type ContextSchema map[string]ValueType
+
+var (
+	schemas     atomic.Value
+	registering sync.Mutex
+)
+
+// RegisterSchema registers the context schema of the errors in the given
+// namespace. The context values are converted to the declared types by
+// WithContext. The values that cannot be converted are dropped and reported
+// to the warning handler. Pass nil to remove the schema.
+func RegisterSchema(namespace string, schema ContextSchema) {
+	registering.Lock()
+	defer registering.Unlock()
+
+	current, _ := schemas.Load().(map[string]ContextSchema)
+	// the map is copied, so the readers do not need a lock
+	next := make(map[string]ContextSchema, len(current)+1)
+
+	for key, item := range current {
+		next[key] = item
+	}
+
+	if schema == nil {
+		delete(next, namespace)
+	} else {
+		next[namespace] = schema
+	}
+
+	schemas.Store(next)
+}
+
+// schema returns the context schema of the namespace
+func schema(namespace string) ContextSchema {
+	registry, _ := schemas.Load().(map[string]ContextSchema)
+	return registry[namespace]
+}
+
+// apply converts the values of the context to the declared types in place.
+// It returns the keys of the values that cannot be converted, which are
+// removed from the context.
+func (s ContextSchema) apply(context Map) []string {
+	rejected := []string{}
+
+	for key, kind := range s {
+		value, ok := context[key]
+		if !ok || value == nil {
+			continue
+		}
+
+		if value, ok = kind.convert(value); ok {
+			context[key] = value
+		} else {
+			delete(context, key)
+			rejected = append(rejected, key)
+		}
+	}
+
+	sort.Strings(rejected)
+	return rejected
+}
+
+// convert converts the value to the type
+func (t ValueType) convert(value interface{}) (interface{}, bool) {
+	switch t {
+	case String:
+		switch item := value.(type) {
+		case string:
+			return item, true
+		case fmt.Stringer:
+			return item.String(), true
+		}
+
+		if number, ok := numeric(value); ok {
+			return number.String(), true
+		}
+
+		if item, ok := value.(bool); ok {
+			return strconv.FormatBool(item), true
+		}
+	case Int:
+		if number, ok := numeric(value); ok {
+			if item, err := number.Int64(); err == nil {
+				return item, true
+			}
+
+			if item, err := number.Float64(); err == nil && item == math.Trunc(item) &&
+				item >= math.MinInt64 && item < math.MaxInt64 {
+				return int64(item), true
+			}
+		}
+	case Float:
+		if number, ok := numeric(value); ok {
+			if item, err := number.Float64(); err == nil {
+				return item, true
+			}
+		}
+	case Bool:
+		switch item := value.(type) {
+		case bool:
+			return item, true
+		case string:
+			if item, err := strconv.ParseBool(item); err == nil {
+				return item, true
+			}
+		}
+	case Duration:
+		switch item := value.(type) {
+		case time.Duration:
+			return item, true
+		case string:
+			if item, err := time.ParseDuration(item); err == nil {
+				return item, true
+			}
+		}
+	case Timestamp:
+		switch item := value.(type) {
+		case time.Time:
+			return item, true
+		case string:
+			if item, err := time.Parse(time.RFC3339Nano, item); err == nil {
+				return item, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// numeric returns the value as a number if it's a number or a numeric string
+func numeric(value interface{}) (json.Number, bool) {
+	switch item := value.(type) {
+	case json.Number:
+		return item, true
+	case string:
+		if _, err := strconv.ParseFloat(item, 64); err == nil {
+			return json.Number(item), true
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return json.Number(fmt.Sprint(item)), true
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(item), 'g', -1, 32)), true
+	case float64:
+		if !math.IsInf(item, 0) && !math.IsNaN(item) {
+			return json.Number(strconv.FormatFloat(item, 'g', -1, 64)), true
+		}
+	}
+
+	return "", false
+}
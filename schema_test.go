@@ -0,0 +1,91 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContextSchema", func() {
+	var (
+		namespace *flaw.Namespace
+		warnings  []string
+	)
+
+	BeforeEach(func() {
+		warnings = nil
+		namespace = flaw.NewNamespace("billing")
+
+		flaw.SetWarningHandler(func(msg string) {
+			warnings = append(warnings, msg)
+		})
+
+		flaw.RegisterSchema("billing", flaw.ContextSchema{
+			"user_id":  flaw.String,
+			"attempt":  flaw.Int,
+			"amount":   flaw.Float,
+			"retry":    flaw.Bool,
+			"timeout":  flaw.Duration,
+			"deadline": flaw.Timestamp,
+		})
+	})
+
+	AfterEach(func() {
+		flaw.RegisterSchema("billing", nil)
+		flaw.SetWarningHandler(nil)
+	})
+
+	It("converts the context values", func() {
+		errx := namespace.Errorf("failed").WithContext(flaw.Map{
+			"user_id":  42,
+			"attempt":  "3",
+			"amount":   12,
+			"retry":    "true",
+			"timeout":  "2s",
+			"deadline": "2024-05-01T10:30:00Z",
+			"other":    7,
+		})
+
+		context := errx.Context()
+		Expect(context).To(HaveKeyWithValue("user_id", "42"))
+		Expect(context).To(HaveKeyWithValue("attempt", int64(3)))
+		Expect(context).To(HaveKeyWithValue("amount", float64(12)))
+		Expect(context).To(HaveKeyWithValue("retry", true))
+		Expect(context).To(HaveKeyWithValue("timeout", 2*time.Second))
+		Expect(context).To(HaveKeyWithValue("deadline", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)))
+		Expect(context).To(HaveKeyWithValue("other", 7))
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("converts the whole floats and the json numbers to int", func() {
+		errx := namespace.Errorf("failed").WithContext(flaw.Map{
+			"attempt": json.Number("3"),
+		})
+		Expect(errx.Context()).To(HaveKeyWithValue("attempt", int64(3)))
+
+		errx = errx.WithContext(flaw.Map{"attempt": 4.0})
+		Expect(errx.Context()).To(HaveKeyWithValue("attempt", int64(4)))
+	})
+
+	It("drops the values that cannot be converted", func() {
+		errx := namespace.Errorf("failed").WithContext(flaw.Map{
+			"attempt": "third",
+			"retry":   1,
+		})
+
+		Expect(errx.Context()).NotTo(HaveKey("attempt"))
+		Expect(errx.Context()).NotTo(HaveKey("retry"))
+		Expect(warnings).To(HaveLen(2))
+		Expect(warnings[0]).To(HaveSuffix(`flaw: context "attempt" of namespace "billing" is not int`))
+		Expect(warnings[1]).To(HaveSuffix(`flaw: context "retry" of namespace "billing" is not bool`))
+	})
+
+	It("does not validate the errors of other namespaces", func() {
+		errx := flaw.Errorf("failed").WithContext(flaw.Map{"attempt": "third"})
+		Expect(errx.Context()).To(HaveKeyWithValue("attempt", "third"))
+	})
+})
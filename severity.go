@@ -0,0 +1,149 @@
+package flaw
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	keySeverity    = "error_severity"
+	keyEscalations = "error_escalations"
+)
+
+// Severity is the severity of an error
+type Severity uint8
+
+// the severities of the errors in ascending order
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return ""
+	}
+}
+
+// MarshalText returns the name of the severity
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses the name of the severity
+func (s *Severity) UnmarshalText(data []byte) error {
+	for severity := SeverityInfo; severity <= SeverityCritical; severity++ {
+		if strings.EqualFold(severity.String(), string(data)) {
+			*s = severity
+			return nil
+		}
+	}
+
+	return fmt.Errorf("flaw: unknown severity %q", data)
+}
+
+// Escalation is a breadcrumb of the raise of the error severity
+type Escalation struct {
+	// From is the severity before the escalation
+	From Severity `json:"from,omitempty"`
+	// To is the severity after the escalation
+	To Severity `json:"to"`
+	// Reason describes why the error has been escalated
+	Reason string `json:"reason"`
+}
+
+// EscalationHandler receives every escalation made by Escalate, e.g. to page
+// the on-call when an error becomes critical
+type EscalationHandler func(err *Error, escalation Escalation)
+
+type escalationHandler struct {
+	handler EscalationHandler
+}
+
+var escalations atomic.Value
+
+// SetEscalationHandler sets the handler that receives the escalations. The
+// handler is disabled by default. Pass nil to disable it.
+func SetEscalationHandler(handler EscalationHandler) {
+	escalations.Store(escalationHandler{handler: handler})
+}
+
+// WithSeverity creates an error copy with given severity
+func (x Error) WithSeverity(severity Severity) *Error {
+	x.severity = severity
+	return &x
+}
+
+// Severity returns the severity of the error
+func (x *Error) Severity() Severity {
+	return x.severity
+}
+
+// Escalations returns the escalations of the error from the oldest to the
+// newest
+func (x *Error) Escalations() []Escalation {
+	return append([]Escalation(nil), x.escalations...)
+}
+
+// SeverityOf returns the severity of the error. It's zero if the error does
+// not have one.
+func SeverityOf(err error) Severity {
+	type Severer interface {
+		Severity() Severity
+	}
+
+	if severer, ok := err.(Severer); ok {
+		return severer.Severity()
+	}
+
+	return 0
+}
+
+// Escalate creates a copy of the error with the severity raised to the given
+// one and a breadcrumb of the reason, e.g. an exhausted retry budget turns a
+// warning into a critical error. The errors that are not flaw errors are
+// wrapped first. The severity is never lowered, so an error which severity is
+// not lower is returned unchanged. Escalate returns nil for a nil error.
+func Escalate(err error, severity Severity, reason string) *Error {
+	if err == nil {
+		return nil
+	}
+
+	errx, ok := err.(*Error)
+	if !ok {
+		errx = wrap(err).capture(1)
+	}
+
+	if errx.severity >= severity {
+		return errx
+	}
+
+	escalation := Escalation{
+		From:   errx.severity,
+		To:     severity,
+		Reason: reason,
+	}
+
+	copy := *errx
+	copy.severity = severity
+	copy.escalations = append(errx.Escalations(), escalation)
+
+	if item, _ := escalations.Load().(escalationHandler); item.handler != nil {
+		item.handler(&copy, escalation)
+	}
+
+	return &copy
+}
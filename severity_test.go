@@ -0,0 +1,85 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Escalate", func() {
+	var escalations []flaw.Escalation
+
+	BeforeEach(func() {
+		escalations = nil
+
+		flaw.SetEscalationHandler(func(err *flaw.Error, escalation flaw.Escalation) {
+			escalations = append(escalations, escalation)
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SetEscalationHandler(nil)
+	})
+
+	It("raises the severity", func() {
+		warning := flaw.Errorf("cannot charge").WithSeverity(flaw.SeverityWarning)
+
+		errx := flaw.Escalate(warning, flaw.SeverityCritical, "retry budget exhausted")
+		Expect(errx.Severity()).To(Equal(flaw.SeverityCritical))
+		Expect(errx.Escalations()).To(Equal([]flaw.Escalation{
+			{From: flaw.SeverityWarning, To: flaw.SeverityCritical, Reason: "retry budget exhausted"},
+		}))
+		Expect(escalations).To(Equal(errx.Escalations()))
+
+		By("not modifying the given error")
+		Expect(warning.Severity()).To(Equal(flaw.SeverityWarning))
+		Expect(warning.Escalations()).To(BeEmpty())
+	})
+
+	It("keeps the breadcrumbs of the previous escalations", func() {
+		errx := flaw.Escalate(fmt.Errorf("timeout"), flaw.SeverityWarning, "first attempt failed")
+		errx = flaw.Escalate(errx, flaw.SeverityError, "second attempt failed")
+
+		Expect(flaw.SeverityOf(errx)).To(Equal(flaw.SeverityError))
+		Expect(errx.Escalations()).To(HaveLen(2))
+		Expect(errx.Escalations()[0].From).To(BeZero())
+		Expect(errx.Escalations()[1].From).To(Equal(flaw.SeverityWarning))
+	})
+
+	It("does not lower the severity", func() {
+		critical := flaw.Errorf("cannot charge").WithSeverity(flaw.SeverityCritical)
+
+		Expect(flaw.Escalate(critical, flaw.SeverityWarning, "oh no")).To(BeIdenticalTo(critical))
+		Expect(escalations).To(BeEmpty())
+	})
+
+	It("returns nil for a nil error", func() {
+		Expect(flaw.Escalate(nil, flaw.SeverityCritical, "oh no")).To(BeNil())
+	})
+
+	It("marshals the severity", func() {
+		errx := flaw.Escalate(flaw.Errorf("cannot charge"), flaw.SeverityCritical, "retry budget exhausted")
+
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_severity":"critical"`))
+		Expect(string(data)).To(ContainSubstring(`"error_escalations":[{"to":"critical","reason":"retry budget exhausted"}]`))
+
+		decoded := &flaw.Error{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded.Severity()).To(Equal(flaw.SeverityCritical))
+		Expect(decoded.Escalations()).To(Equal(errx.Escalations()))
+
+		data, err = errx.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded = &flaw.Error{}
+		Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+		Expect(decoded.Severity()).To(Equal(flaw.SeverityCritical))
+		Expect(decoded.Escalations()).To(Equal(errx.Escalations()))
+	})
+})
@@ -0,0 +1,66 @@
+package flaw
+
+import (
+	"log/slog"
+	"sort"
+	"sync/atomic"
+)
+
+var _ slog.LogValuer = &Error{}
+
+var logStacks atomic.Bool
+
+// SetLogStackTrace enables or disables the stack trace in the attributes
+// returned by LogValue. The stack trace is disabled by default.
+func SetLogStackTrace(enabled bool) {
+	logStacks.Store(enabled)
+}
+
+// LogValue returns the error as a group of attributes ordered by their key,
+// e.g. the code, the status, the message, the details and the context of the
+// error, so slog logs the error as a structured value. The context entries
+// that are not serialized, e.g. the ones marked by WithPrivate, are omitted.
+func (x *Error) LogValue() slog.Value {
+	data := x.conceal(x.data(keyStack, keyRemoteStack))
+
+	if x.status != 0 {
+		data[keyStatus] = x.status
+	}
+
+	keys := make([]string, 0, len(data)+1)
+
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	if logStacks.Load() && x.stack != nil {
+		keys = append(keys, keyStack)
+	}
+
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, len(keys))
+
+	for index, key := range keys {
+		if key == keyStack {
+			attrs[index] = slog.Any(key, x.stack.lines())
+		} else {
+			attrs[index] = slog.Any(key, data[key])
+		}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// lines returns the frames of the stack trace as text
+func (stack StackTrace) lines() []string {
+	items := make([]string, 0, len(stack))
+
+	for _, frame := range stack {
+		if text, err := frame.MarshalText(); err == nil {
+			items = append(items, string(text))
+		}
+	}
+
+	return items
+}
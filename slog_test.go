@@ -0,0 +1,78 @@
+package flaw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogValue", func() {
+	var (
+		buffer *bytes.Buffer
+		logger *slog.Logger
+		errx   *flaw.Error
+	)
+
+	record := func() map[string]interface{} {
+		data := map[string]interface{}{}
+		Expect(json.Unmarshal(buffer.Bytes(), &data)).To(Succeed())
+		return data["err"].(map[string]interface{})
+	}
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		logger = slog.New(slog.NewJSONHandler(buffer, nil))
+
+		errx = flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithDetails("the user was deleted").
+			WithContext(flaw.Map{"user": "root"})
+	})
+
+	AfterEach(func() {
+		flaw.SetLogStackTrace(false)
+	})
+
+	It("logs the error as a group", func() {
+		logger.Error("failed", "err", errx)
+
+		Expect(record()).To(Equal(map[string]interface{}{
+			"error_code":    float64(5),
+			"error_status":  float64(404),
+			"error_message": "user not found",
+			"error_details": []interface{}{"the user was deleted"},
+			"user":          "root",
+		}))
+	})
+
+	It("omits the private context entries", func() {
+		logger.Error("failed", "err", errx.WithContext(flaw.Map{"user": "root", "password": "secret"}).WithPrivate("password"))
+
+		Expect(record()).To(HaveKeyWithValue("user", "root"))
+		Expect(record()).NotTo(HaveKey("password"))
+	})
+
+	It("orders the attributes by their key", func() {
+		attrs := errx.LogValue().Group()
+		Expect(attrs).To(HaveLen(5))
+		Expect(attrs[0].Key).To(Equal("error_code"))
+		Expect(attrs[4].Key).To(Equal("user"))
+	})
+
+	Context("when the stack trace is enabled", func() {
+		BeforeEach(func() {
+			flaw.SetLogStackTrace(true)
+		})
+
+		It("logs the stack trace", func() {
+			logger.Error("failed", "err", errx)
+			Expect(record()).To(HaveKeyWithValue("error_stack", HaveLen(len(errx.StackTrace()))))
+		})
+	})
+})
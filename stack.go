@@ -2,10 +2,57 @@ package flaw
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
 	"runtime"
 	"strconv"
+	"sync/atomic"
 )
 
+var sampling atomic.Uint64
+
+func init() {
+	SetStackSampling(1)
+}
+
+// SetStackSampling sets the fraction of the newly created errors that
+// capture a stack trace. The rate is between 0 and 1, where 1 (the default)
+// captures the stack trace of every error. The errors that skip the capture
+// are marked in the verbose output.
+func SetStackSampling(rate float64) {
+	rate = math.Max(0, math.Min(1, rate))
+	sampling.Store(math.Float64bits(rate))
+}
+
+func sample() bool {
+	switch rate := math.Float64frombits(sampling.Load()); {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return rand.Float64() < rate
+	}
+}
+
+// SourceMap maps a position in a generated file back to the position in its
+// source, e.g. a protobuf definition or a template. The position is returned
+// unchanged when the file is not generated.
+type SourceMap func(file string, line int) (string, int)
+
+type sourceMap struct {
+	handler SourceMap
+}
+
+var sourceMaps atomic.Value
+
+// SetSourceMap sets the source map that is applied to the stack frames when
+// they are formatted. Pass nil to disable it.
+func SetSourceMap(handler SourceMap) {
+	sourceMaps.Store(sourceMap{handler: handler})
+}
+
 // StackFrame represents a program counter inside a stack frame.
 // For historical reasons if StackFrame is interpreted as a uintptr
 // its value represents the program counter + 1.
@@ -26,8 +73,12 @@ func (frame StackFrame) Format(state fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		frame.Format(state, 's')
-		fmt.Fprintf(state, ":")
-		frame.Format(state, 'd')
+
+		// the synthetic frames do not have a line
+		if frame.Line != 0 {
+			fmt.Fprintf(state, ":")
+			frame.Format(state, 'd')
+		}
 
 		if state.Flag('+') {
 			fmt.Fprintf(state, " (")
@@ -35,19 +86,90 @@ func (frame StackFrame) Format(state fmt.State, verb rune) {
 			fmt.Fprintf(state, ")")
 		}
 	case 's':
+		file, _ := frame.source()
+
 		switch {
 		case state.Flag('+'):
-			fmt.Fprint(state, frame.File)
+			fmt.Fprint(state, file)
 		default:
-			fmt.Fprint(state, relative(frame.File))
+			fmt.Fprint(state, relative(file))
 		}
 	case 'd':
-		fmt.Fprint(state, strconv.Itoa(frame.Line))
+		_, line := frame.source()
+		fmt.Fprint(state, strconv.Itoa(line))
 	case 'n':
 		fmt.Fprint(state, function(frame.Function))
 	}
 }
 
+// source returns the position of the frame mapped by the source map
+func (frame StackFrame) source() (string, int) {
+	if item, _ := sourceMaps.Load().(sourceMap); item.handler != nil {
+		return item.handler(frame.File, frame.Line)
+	}
+
+	return frame.File, frame.Line
+}
+
+// Frame creates a synthetic frame with given name and function, e.g.
+// Frame("handler", "orders.Create"). The synthetic frames annotate the stack
+// traces that are passed to Wrap.
+func Frame(name, function string) StackFrame {
+	return StackFrame{
+		File:     name,
+		Function: function,
+	}
+}
+
+// Caller returns the frame of the caller. The argument skip is the number of
+// frames to skip, with 0 identifying the caller of Caller.
+func Caller(skip int) StackFrame {
+	stack := make([]uintptr, 1)
+
+	if runtime.Callers(skip+2, stack) == 0 {
+		return StackFrame{}
+	}
+
+	frame, _ := runtime.CallersFrames(stack).Next()
+	return StackFrame(frame)
+}
+
+// FrameFromFunc returns the frame of the function's entry. It returns an
+// empty frame if fn is not a function.
+func FrameFromFunc(fn interface{}) StackFrame {
+	value := reflect.ValueOf(fn)
+
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return StackFrame{}
+	}
+
+	item := runtime.FuncForPC(value.Pointer())
+	if item == nil {
+		return StackFrame{}
+	}
+
+	file, line := item.FileLine(item.Entry())
+
+	return StackFrame{
+		PC:       item.Entry(),
+		Func:     item,
+		Function: item.Name(),
+		File:     file,
+		Line:     line,
+		Entry:    item.Entry(),
+	}
+}
+
+func (frame StackFrame) equal(other StackFrame) bool {
+	if frame.PC != 0 && other.PC != 0 {
+		return frame.PC == other.PC
+	}
+
+	return frame.File == other.File &&
+		frame.Line == other.Line &&
+		frame.Function == other.Function
+}
+
 // MarshalText formats a stacktrace StackFrame as a text string. The output is the
 // same as that of fmt.Sprintf("%+v", f), but without newlines or tabs.
 func (frame StackFrame) MarshalText() ([]byte, error) {
@@ -118,6 +240,88 @@ func (stack StackTrace) Format(state fmt.State, verb rune) {
 	}
 }
 
+// ProgramCounters returns the raw program counters of the stack trace, which
+// can be symbolized with runtime.CallersFrames later or offline. The frames
+// without a program counter are skipped. The inlined frames share a single
+// program counter.
+func (stack StackTrace) ProgramCounters() []uintptr {
+	pcs := make([]uintptr, 0, len(stack))
+
+	for _, frame := range stack {
+		if frame.PC == 0 {
+			continue
+		}
+
+		// the frame contains the program counter of the call instruction,
+		// while the runtime expects the return address
+		pc := frame.PC + 1
+
+		if count := len(pcs); count > 0 && pcs[count-1] == pc {
+			continue
+		}
+
+		pcs = append(pcs, pc)
+	}
+
+	return pcs
+}
+
+// Hash returns a hash of the stack trace. The program counters are hashed when
+// they are available, which avoids the symbolized strings.
+func (stack StackTrace) Hash() uint64 {
+	// FNV-1a is computed inline, so the hashing does not allocate
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+
+	hash := uint64(offset)
+
+	number := func(value uint64) {
+		for index := 0; index < 8; index++ {
+			hash ^= value & 0xff
+			hash *= prime
+			value >>= 8
+		}
+	}
+
+	text := func(value string) {
+		for index := 0; index < len(value); index++ {
+			hash ^= uint64(value[index])
+			hash *= prime
+		}
+	}
+
+	for _, frame := range stack {
+		if frame.PC != 0 {
+			number(uint64(frame.PC))
+			continue
+		}
+
+		number(uint64(frame.Line))
+		text(frame.File)
+		text(frame.Function)
+	}
+
+	return hash
+}
+
+// Equal reports whether the stack traces have the same frames. The frames are
+// compared by their program counters when they are available.
+func (stack StackTrace) Equal(other StackTrace) bool {
+	if len(stack) != len(other) {
+		return false
+	}
+
+	for index, frame := range stack {
+		if !frame.equal(other[index]) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (stack StackTrace) formatBullet(state fmt.State, verb rune) {
 	count := len(stack)
 
@@ -0,0 +1,200 @@
+package flaw_test
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StackTrace", func() {
+	It("starts at the caller of the constructor", func() {
+		errs := []*flaw.Error{
+			flaw.Errorf("oh no"),
+			flaw.Wrap(fmt.Errorf("oh no")),
+			flaw.Errorf("oh no").WithError(fmt.Errorf("oh no")),
+			flaw.ErrorConstant("oh no").With(),
+			flaw.NewNamespace("billing").Errorf("oh no"),
+			flaw.NewNamespace("billing").Wrap(fmt.Errorf("oh no")),
+		}
+
+		for _, err := range errs {
+			Expect(fmt.Sprintf("%v", err.StackTrace()[0])).To(ContainSubstring("stack_test.go"))
+		}
+
+		err := flaw.NewMapper().On(io.EOF).To(flaw.Errorf("oh no")).Map(io.EOF)
+		Expect(fmt.Sprintf("%v", flaw.Wrap(err).StackTrace()[0])).To(ContainSubstring("stack_test.go"))
+	})
+
+	Describe("ProgramCounters", func() {
+		It("returns the program counters of the frames", func() {
+			stack := flaw.Errorf("oh no").StackTrace()
+			pcs := stack.ProgramCounters()
+			Expect(pcs).NotTo(BeEmpty())
+
+			frames := runtime.CallersFrames(pcs)
+			frame, _ := frames.Next()
+			Expect(frame.Function).To(Equal(stack[0].Function))
+			Expect(frame.Line).To(Equal(stack[0].Line))
+		})
+
+		It("skips the frames without program counters", func() {
+			stack := flaw.StackTrace{{File: "main.go", Line: 10, Function: "main.main"}}
+			Expect(stack.ProgramCounters()).To(BeEmpty())
+		})
+	})
+
+	Describe("Equal", func() {
+		capture := func() flaw.StackTrace {
+			return flaw.NewStackTraceAt(0)
+		}
+
+		It("compares the stack traces", func() {
+			stacks := []flaw.StackTrace{}
+
+			for index := 0; index < 2; index++ {
+				stacks = append(stacks, capture())
+			}
+
+			Expect(stacks[0].Equal(stacks[1])).To(BeTrue())
+			Expect(stacks[0].Hash()).To(Equal(stacks[1].Hash()))
+
+			other := capture()
+			Expect(stacks[0].Equal(other)).To(BeFalse())
+			Expect(stacks[0].Hash()).NotTo(Equal(other.Hash()))
+		})
+
+		It("compares the frames without program counters", func() {
+			stack := flaw.StackTrace{{File: "main.go", Line: 10, Function: "main.main"}}
+
+			Expect(stack.Equal(flaw.StackTrace{{File: "main.go", Line: 10, Function: "main.main"}})).To(BeTrue())
+			Expect(stack.Equal(flaw.StackTrace{{File: "main.go", Line: 11, Function: "main.main"}})).To(BeFalse())
+			Expect(stack.Equal(nil)).To(BeFalse())
+			Expect(stack.Hash()).NotTo(Equal(flaw.StackTrace{}.Hash()))
+		})
+	})
+})
+
+var _ = Describe("SetStackSampling", func() {
+	AfterEach(func() {
+		flaw.SetStackSampling(1)
+	})
+
+	It("captures the stack trace", func() {
+		flaw.SetStackSampling(1)
+		Expect(flaw.Errorf("oh no").StackTrace()).NotTo(BeEmpty())
+	})
+
+	Context("when the rate is zero", func() {
+		BeforeEach(func() {
+			flaw.SetStackSampling(0)
+		})
+
+		It("skips the stack trace", func() {
+			err := flaw.Errorf("oh no")
+			Expect(err.StackTrace()).To(BeEmpty())
+			Expect(fmt.Sprintf("%+v", err)).To(Equal(" message: oh no\n   stack: (skipped by sampling)"))
+		})
+	})
+
+	Context("when the rate is fractional", func() {
+		BeforeEach(func() {
+			flaw.SetStackSampling(0.5)
+		})
+
+		It("captures the stack trace of some errors", func() {
+			count := 0
+
+			for index := 0; index < 1000; index++ {
+				if len(flaw.Errorf("oh no").StackTrace()) > 0 {
+					count++
+				}
+			}
+
+			Expect(count).To(BeNumerically(">", 0))
+			Expect(count).To(BeNumerically("<", 1000))
+		})
+	})
+})
+
+var _ = Describe("SetSourceMap", func() {
+	frame := flaw.StackFrame{
+		File:     "/src/user.pb.go",
+		Line:     42,
+		Function: "github.com/phogolabs/flaw.Generated",
+	}
+
+	AfterEach(func() {
+		flaw.SetSourceMap(nil)
+	})
+
+	It("maps the generated positions", func() {
+		flaw.SetSourceMap(func(file string, line int) (string, int) {
+			if file == "/src/user.pb.go" {
+				return "/src/user.proto", line / 2
+			}
+
+			return file, line
+		})
+
+		Expect(fmt.Sprintf("%+v", frame)).To(Equal("/src/user.proto:21 (Generated)"))
+		Expect(fmt.Sprintf("%+v", flaw.StackFrame{File: "/src/user.go", Line: 1})).To(HavePrefix("/src/user.go:1"))
+	})
+
+	It("keeps the positions when disabled", func() {
+		Expect(fmt.Sprintf("%+v", frame)).To(Equal("/src/user.pb.go:42 (Generated)"))
+	})
+})
+
+func CreateOrder() {}
+
+var _ = Describe("Frame", func() {
+	It("creates a synthetic frame", func() {
+		frame := flaw.Frame("handler", "orders.Create")
+		Expect(fmt.Sprintf("%+v", frame)).To(Equal("handler (Create)"))
+
+		err := flaw.Wrap(fmt.Errorf("oh no"), frame)
+		Expect(err.StackTrace()).To(ConsistOf(frame))
+	})
+})
+
+var _ = Describe("Caller", func() {
+	It("returns the frame of the caller", func() {
+		_, file, line, _ := runtime.Caller(0)
+		frame := flaw.Caller(0)
+
+		Expect(frame.File).To(Equal(file))
+		Expect(frame.Line).To(Equal(line + 1))
+	})
+
+	It("skips the frames", func() {
+		caller := func() flaw.StackFrame {
+			return flaw.Caller(1)
+		}
+
+		_, file, line, _ := runtime.Caller(0)
+		frame := caller()
+
+		Expect(frame.File).To(Equal(file))
+		Expect(frame.Line).To(Equal(line + 1))
+	})
+})
+
+var _ = Describe("FrameFromFunc", func() {
+	It("returns the frame of the function", func() {
+		frame := flaw.FrameFromFunc(CreateOrder)
+
+		Expect(frame.Function).To(HaveSuffix("flaw_test.CreateOrder"))
+		Expect(frame.File).To(HaveSuffix("stack_test.go"))
+		Expect(frame.Line).NotTo(BeZero())
+	})
+
+	It("returns an empty frame for a value that is not a function", func() {
+		Expect(flaw.FrameFromFunc(42)).To(Equal(flaw.StackFrame{}))
+		Expect(flaw.FrameFromFunc(nil)).To(Equal(flaw.StackFrame{}))
+	})
+})
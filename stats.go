@@ -0,0 +1,34 @@
+package flaw
+
+import "sync/atomic"
+
+// Statistics contains the counters that quantify the overhead of the package
+type Statistics struct {
+	// ErrorsCreated is the number of the created errors
+	ErrorsCreated uint64
+	// StacksCaptured is the number of the captured stack traces
+	StacksCaptured uint64
+	// StacksSkipped is the number of the stack traces skipped by the sampling
+	StacksSkipped uint64
+	// BytesMarshaled is the number of bytes produced by the json marshaling,
+	// including the marshaling of the nested errors
+	BytesMarshaled uint64
+}
+
+var stats struct {
+	errors   atomic.Uint64
+	captured atomic.Uint64
+	skipped  atomic.Uint64
+	bytes    atomic.Uint64
+}
+
+// Stats returns a snapshot of the package counters. The counters are
+// cumulative since the start of the program.
+func Stats() Statistics {
+	return Statistics{
+		ErrorsCreated:  stats.errors.Load(),
+		StacksCaptured: stats.captured.Load(),
+		StacksSkipped:  stats.skipped.Load(),
+		BytesMarshaled: stats.bytes.Load(),
+	}
+}
@@ -0,0 +1,61 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stats", func() {
+	AfterEach(func() {
+		flaw.SetStackSampling(1)
+	})
+
+	It("counts the created errors and the captured stacks", func() {
+		before := flaw.Stats()
+
+		flaw.Errorf("failed")
+		flaw.Wrap(fmt.Errorf("oh no"))
+
+		after := flaw.Stats()
+		Expect(after.ErrorsCreated - before.ErrorsCreated).To(BeEquivalentTo(2))
+		Expect(after.StacksCaptured - before.StacksCaptured).To(BeEquivalentTo(2))
+	})
+
+	It("does not count the copies as created errors", func() {
+		errx := flaw.Errorf("failed")
+		before := flaw.Stats()
+
+		errx.WithError(fmt.Errorf("oh no"))
+		errx.WithRemote("users", "/users")
+
+		after := flaw.Stats()
+		Expect(after.ErrorsCreated - before.ErrorsCreated).To(BeZero())
+		Expect(after.StacksCaptured - before.StacksCaptured).To(BeEquivalentTo(2))
+	})
+
+	It("counts the skipped stacks", func() {
+		flaw.SetStackSampling(0)
+		before := flaw.Stats()
+
+		flaw.Errorf("failed")
+
+		after := flaw.Stats()
+		Expect(after.StacksSkipped - before.StacksSkipped).To(BeEquivalentTo(1))
+		Expect(after.StacksCaptured - before.StacksCaptured).To(BeZero())
+	})
+
+	It("counts the marshaled bytes", func() {
+		before := flaw.Stats()
+
+		data, err := json.Marshal(flaw.Errorf("failed"))
+		Expect(err).To(BeNil())
+
+		after := flaw.Stats()
+		Expect(after.BytesMarshaled - before.BytesMarshaled).To(BeEquivalentTo(len(data)))
+	})
+})
@@ -0,0 +1,68 @@
+//go:build !flaw_nogrpc
+
+package flaw
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// FromGRPCStatus converts a gRPC status to an error. It's the reverse of
+// GRPCStatus, so the code, the message, the details, the context, the
+// component and the pointer of an error survive the service boundaries. The
+// status of the error is 500. FromGRPCStatus returns nil if the status is nil
+// or OK.
+func FromGRPCStatus(state *status.Status) *Error {
+	if state.Err() == nil {
+		return nil
+	}
+
+	return fromStatus(state).capture(1)
+}
+
+// FromGRPCError converts a gRPC status error to an error. A flaw error is
+// returned as it is and the errors without a status have code Unknown.
+// FromGRPCError returns nil if err is nil.
+func FromGRPCError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	if errx, ok := err.(*Error); ok {
+		return errx
+	}
+
+	return fromStatus(status.Convert(err)).capture(1)
+}
+
+func fromStatus(state *status.Status) *Error {
+	errx := &Error{
+		code:   int(state.Code()),
+		status: 500,
+		msg:    state.Message(),
+	}
+
+	for _, detail := range state.Details() {
+		switch item := detail.(type) {
+		case *wrapperspb.StringValue:
+			errx.details = append(errx.details, item.GetValue())
+		case *errdetails.BadRequest:
+			if violations := item.GetFieldViolations(); len(violations) > 0 {
+				errx.pointer = violations[0].GetField()
+			}
+		case *structpb.Struct:
+			for key, value := range item.AsMap() {
+				if name, ok := value.(string); ok && key == keyComponent {
+					errx.component = name
+					continue
+				}
+
+				errx.put(key, value)
+			}
+		}
+	}
+
+	return errx
+}
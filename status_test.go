@@ -0,0 +1,73 @@
+//go:build !flaw_nogrpc
+
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FromGRPCStatus", func() {
+	It("converts the status successfully", func() {
+		errx := flaw.Errorf("user not found").
+			WithCode(int(codes.NotFound)).
+			WithDetails("the user was deleted").
+			WithContext(flaw.Map{"user": "root"}).
+			WithComponent("users").
+			WithPointer("/data/user")
+
+		err := flaw.FromGRPCStatus(errx.GRPCStatus())
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(int(codes.NotFound)))
+		Expect(err.Status()).To(Equal(500))
+		Expect(err.Message()).To(Equal("user not found"))
+		Expect(err.Details()).To(ConsistOf("the user was deleted"))
+		Expect(err.Context()).To(HaveKeyWithValue("user", "root"))
+		Expect(err.Component()).To(Equal("users"))
+		Expect(err.Pointer()).To(Equal("/data/user"))
+		Expect(err.StackTrace()).NotTo(BeEmpty())
+	})
+
+	It("converts a status without details successfully", func() {
+		err := flaw.FromGRPCStatus(status.New(codes.Unavailable, "try again"))
+		Expect(err.Code()).To(Equal(int(codes.Unavailable)))
+		Expect(err.Message()).To(Equal("try again"))
+		Expect(err.Details()).To(BeEmpty())
+	})
+
+	Context("when the status is OK", func() {
+		It("returns nil", func() {
+			Expect(flaw.FromGRPCStatus(nil)).To(BeNil())
+			Expect(flaw.FromGRPCStatus(status.New(codes.OK, ""))).To(BeNil())
+		})
+	})
+})
+
+var _ = Describe("FromGRPCError", func() {
+	It("converts the status error successfully", func() {
+		err := flaw.FromGRPCError(status.Error(codes.NotFound, "user not found"))
+		Expect(err.Code()).To(Equal(int(codes.NotFound)))
+		Expect(err.Message()).To(Equal("user not found"))
+	})
+
+	It("returns the flaw error as it is", func() {
+		errx := flaw.Errorf("oh no")
+		Expect(flaw.FromGRPCError(errx)).To(BeIdenticalTo(errx))
+	})
+
+	It("converts the plain error successfully", func() {
+		err := flaw.FromGRPCError(fmt.Errorf("oh no"))
+		Expect(err.Code()).To(Equal(int(codes.Unknown)))
+		Expect(err.Message()).To(Equal("oh no"))
+	})
+
+	It("returns nil", func() {
+		Expect(flaw.FromGRPCError(nil)).To(BeNil())
+	})
+})
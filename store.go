@@ -0,0 +1,108 @@
+package flaw
+
+import (
+	"container/list"
+	"sync"
+)
+
+// KeyFingerprint is the context key of the fingerprint of an error, e.g. of
+// the light errors returned by the Store
+const KeyFingerprint = "error_fingerprint"
+
+// Store keeps the full errors in-process by their fingerprints, so the
+// transports send the light errors only and the heavyweight details, e.g.
+// the stack trace, can be looked up later, e.g. by a debug endpoint. The
+// errors with the same fingerprint share the slot of the latest occurrence.
+// The least recently used errors are evicted when the store is full. The
+// store is safe for concurrent use.
+type Store struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+// stored is an error in the store
+type stored struct {
+	fingerprint string
+	errx        *Error
+}
+
+// NewStore creates a new store that keeps at most size errors
+func NewStore(size int) *Store {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Store{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Intern keeps the error in the store and returns a light copy with the code,
+// the status, the message and the fingerprint of the error only. The errors
+// that are not flaw errors are wrapped. It returns nil if err is nil.
+func (s *Store) Intern(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	errx, ok := err.(*Error)
+
+	if !ok {
+		errx = wrap(err).capture(1)
+	}
+
+	fingerprint := errx.Fingerprint()
+	s.put(fingerprint, errx)
+
+	return &Error{
+		code:    errx.code,
+		status:  errx.status,
+		msg:     errx.message(),
+		context: Map{KeyFingerprint: fingerprint},
+	}
+}
+
+// Lookup returns the error of the given fingerprint
+func (s *Store) Lookup(fingerprint string) (*Error, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[fingerprint]
+	if !ok {
+		return nil, false
+	}
+
+	s.order.MoveToFront(element)
+	return element.Value.(*stored).errx, true
+}
+
+// Len returns the number of the errors in the store
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+func (s *Store) put(fingerprint string, errx *Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[fingerprint]; ok {
+		element.Value.(*stored).errx = errx
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.items[fingerprint] = s.order.PushFront(&stored{fingerprint: fingerprint, errx: errx})
+
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*stored).fingerprint)
+	}
+}
@@ -0,0 +1,102 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Store", func() {
+	var store *flaw.Store
+
+	BeforeEach(func() {
+		store = flaw.NewStore(2)
+	})
+
+	It("interns the error", func() {
+		errx := flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithContext(flaw.Map{"user": "root"})
+
+		light := store.Intern(errx)
+		Expect(light.Code()).To(Equal(5))
+		Expect(light.Status()).To(Equal(404))
+		Expect(light.Message()).To(Equal("user not found"))
+		Expect(light.StackTrace()).To(BeEmpty())
+
+		data, err := json.Marshal(light)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(fmt.Sprintf(`{
+			"error_code": 5,
+			"error_message": "user not found",
+			"error_fingerprint": %q
+		}`, errx.Fingerprint())))
+
+		item, ok := store.Lookup(errx.Fingerprint())
+		Expect(ok).To(BeTrue())
+		Expect(item).To(BeIdenticalTo(errx))
+	})
+
+	It("keeps the wrapped errors of different causes apart", func() {
+		eof := flaw.Wrap(io.EOF)
+		unexpected := flaw.Wrap(io.ErrUnexpectedEOF)
+
+		store.Intern(eof)
+		store.Intern(unexpected)
+		Expect(store.Len()).To(Equal(2))
+
+		item, ok := store.Lookup(eof.Fingerprint())
+		Expect(ok).To(BeTrue())
+		Expect(item).To(BeIdenticalTo(eof))
+	})
+
+	It("wraps the other errors", func() {
+		light := store.Intern(fmt.Errorf("oh no"))
+
+		item, ok := store.Lookup(light.Context()["error_fingerprint"].(string))
+		Expect(ok).To(BeTrue())
+		Expect(item.Cause()).To(MatchError("oh no"))
+		Expect(item.StackTrace()).NotTo(BeEmpty())
+	})
+
+	It("keeps the latest occurrence", func() {
+		first := flaw.Errorf("oh no")
+		second := flaw.Errorf("oh no")
+
+		store.Intern(first)
+		store.Intern(second)
+		Expect(store.Len()).To(Equal(1))
+
+		item, _ := store.Lookup(first.Fingerprint())
+		Expect(item).To(BeIdenticalTo(second))
+	})
+
+	It("evicts the least recently used error", func() {
+		first := flaw.Errorf("first")
+		second := flaw.Errorf("second")
+		third := flaw.Errorf("third")
+
+		store.Intern(first)
+		store.Intern(second)
+		store.Lookup(first.Fingerprint())
+		store.Intern(third)
+
+		Expect(store.Len()).To(Equal(2))
+
+		_, ok := store.Lookup(second.Fingerprint())
+		Expect(ok).To(BeFalse())
+
+		_, ok = store.Lookup(first.Fingerprint())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("returns nil", func() {
+		Expect(store.Intern(nil)).To(BeNil())
+	})
+})
@@ -0,0 +1,56 @@
+package flaw
+
+import "errors"
+
+// Depth returns the number of errors in the error's chain, including the
+// error itself. The depth of nil is 0.
+func Depth(err error) int {
+	depth := 0
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		depth++
+	}
+
+	return depth
+}
+
+// Summary returns a one line summary of the error, which consists of the top
+// message and the root cause message, e.g. "user not found: sql: no rows".
+func Summary(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		top   = headline(err)
+		root  = err
+		depth = 1
+	)
+
+	for next := errors.Unwrap(root); next != nil; next = errors.Unwrap(root) {
+		root = next
+		depth++
+	}
+
+	if depth == 1 {
+		return top
+	}
+
+	bottom := headline(root)
+
+	if bottom == top {
+		return top
+	}
+
+	return buildMessage(top, bottom)
+}
+
+// headline returns the message of a flaw error without its cause, or the
+// message of any other error
+func headline(err error) string {
+	if errx, ok := err.(*Error); ok {
+		return errx.message()
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,38 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Depth", func() {
+	It("returns the depth of the chain", func() {
+		cause := fmt.Errorf("sql: no rows")
+
+		Expect(flaw.Depth(nil)).To(Equal(0))
+		Expect(flaw.Depth(cause)).To(Equal(1))
+		Expect(flaw.Depth(flaw.Errorf("user not found").WithError(cause))).To(Equal(2))
+		Expect(flaw.Depth(fmt.Errorf("query: %w", flaw.Wrap(cause)))).To(Equal(3))
+	})
+})
+
+var _ = Describe("Summary", func() {
+	It("returns the top and the root cause messages", func() {
+		cause := fmt.Errorf("sql: no rows")
+		err := flaw.Errorf("user not found").WithError(fmt.Errorf("query: %w", cause))
+
+		Expect(flaw.Summary(flaw.Errorf("user not found").WithError(cause))).To(Equal("user not found: sql: no rows"))
+		Expect(flaw.Summary(err)).To(Equal("user not found: sql: no rows"))
+	})
+
+	It("returns the single message", func() {
+		Expect(flaw.Summary(nil)).To(BeEmpty())
+		Expect(flaw.Summary(fmt.Errorf("oh no"))).To(Equal("oh no"))
+		Expect(flaw.Summary(flaw.Errorf("oh no").WithCode(5))).To(Equal("oh no"))
+		Expect(flaw.Summary(flaw.Wrap(fmt.Errorf("oh no")))).To(Equal("oh no"))
+	})
+})
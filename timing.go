@@ -0,0 +1,50 @@
+package flaw
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const keyOperation = "error_operation"
+
+// KeyElapsed is the context key of the elapsed duration of a failed operation
+const KeyElapsed = "error_elapsed"
+
+// Observer receives the outcome of every operation measured by Time. The err
+// is nil when the operation succeeds.
+type Observer func(op string, elapsed time.Duration, err error)
+
+type observer struct {
+	handler Observer
+}
+
+var observers atomic.Value
+
+// SetObserver sets the observer that receives the outcome of the operations
+// measured by Time. The observer is disabled by default. Pass nil to disable
+// it.
+func SetObserver(handler Observer) {
+	observers.Store(observer{handler: handler})
+}
+
+// Time measures the given function. If the function fails the error is wrapped
+// with the operation name and the elapsed duration in its context.
+func Time(op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+
+	if item, _ := observers.Load().(observer); item.handler != nil {
+		item.handler(op, elapsed, err)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	errx := wrap(err)
+	errx.put(keyOperation, op)
+	errx.put(KeyElapsed, elapsed)
+
+	return errx.capture(1)
+}
@@ -0,0 +1,84 @@
+package flaw_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Time", func() {
+	type Outcome struct {
+		Operation string
+		Elapsed   time.Duration
+		Err       error
+	}
+
+	var outcomes []Outcome
+
+	BeforeEach(func() {
+		outcomes = []Outcome{}
+
+		flaw.SetObserver(func(op string, elapsed time.Duration, err error) {
+			outcomes = append(outcomes, Outcome{Operation: op, Elapsed: elapsed, Err: err})
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SetObserver(nil)
+	})
+
+	It("returns nil when the function succeeds", func() {
+		err := flaw.Time("query", func() error { return nil })
+		Expect(err).To(BeNil())
+
+		Expect(outcomes).To(HaveLen(1))
+		Expect(outcomes[0].Operation).To(Equal("query"))
+		Expect(outcomes[0].Err).To(BeNil())
+	})
+
+	It("wraps the error when the function fails", func() {
+		cause := fmt.Errorf("oh no")
+
+		err := flaw.Time("query", func() error {
+			time.Sleep(time.Millisecond)
+			return cause
+		})
+
+		Expect(errors.Is(err, cause)).To(BeTrue())
+		Expect(flaw.Status(err)).To(Equal(500))
+
+		context := flaw.Context(err)
+		Expect(context).To(HaveKeyWithValue("error_operation", "query"))
+		Expect(context).To(HaveKeyWithValue("error_elapsed", BeNumerically(">=", time.Millisecond)))
+
+		Expect(outcomes).To(HaveLen(1))
+		Expect(outcomes[0].Err).To(Equal(cause))
+		Expect(outcomes[0].Elapsed).To(Equal(context["error_elapsed"]))
+	})
+
+	It("inherits the code and the status of a wrapped flaw error", func() {
+		err := flaw.Time("query", func() error {
+			return flaw.Errorf("not found").WithCode(5).WithStatus(404)
+		})
+
+		Expect(flaw.Code(err)).To(Equal(5))
+		Expect(flaw.Status(err)).To(Equal(404))
+	})
+
+	Context("when the observer is not set", func() {
+		BeforeEach(func() {
+			flaw.SetObserver(nil)
+		})
+
+		It("wraps the error", func() {
+			err := flaw.Time("query", func() error { return fmt.Errorf("oh no") })
+			Expect(flaw.Context(err)).To(HaveKeyWithValue("error_operation", "query"))
+			Expect(outcomes).To(BeEmpty())
+		})
+	})
+})
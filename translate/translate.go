@@ -0,0 +1,183 @@
+// Package translate translates the errors of the downstream vendors, such as
+// the Stripe decline codes, the Twilio errors and the SMTP replies, into flaw
+// errors by a table that is loaded at startup. It keeps the vendor specific
+// knowledge out of the business code.
+package translate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/phogolabs/flaw"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	keyVendor     = "vendor"
+	keyVendorCode = "vendor_code"
+)
+
+// Template is the flaw error that a vendor error is translated into
+type Template struct {
+	// Code is the error code
+	Code int `yaml:"code,omitempty" json:"code,omitempty"`
+	// Status is the error status
+	Status int `yaml:"status,omitempty" json:"status,omitempty"`
+	// Message is the error message
+	Message string `yaml:"message" json:"message"`
+}
+
+// Rule matches the vendor errors by their code or their message
+type Rule struct {
+	// Vendor is the name of the vendor, e.g. "stripe"
+	Vendor string `yaml:"vendor" json:"vendor"`
+	// Code matches the vendor error code, e.g. "card_declined". The code is
+	// returned by the extractor registered for the vendor.
+	Code string `yaml:"code,omitempty" json:"code,omitempty"`
+	// Pattern matches the error message as a regular expression, e.g. "^550 "
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	// Error is the template of the translated error
+	Error Template `yaml:"error" json:"error"`
+
+	pattern *regexp.Regexp
+}
+
+// Extractor returns the code of a vendor error. It returns false if the
+// error does not belong to the vendor.
+type Extractor func(err error) (string, bool)
+
+// Table represents a set of translation rules
+type Table struct {
+	// Rules are the translation rules
+	Rules []Rule `yaml:"rules" json:"rules"`
+
+	extractors map[string]Extractor
+}
+
+// Load loads the table from a YAML or JSON document
+func Load(reader io.Reader) (*Table, error) {
+	table := &Table{}
+
+	if err := yaml.NewDecoder(reader).Decode(table); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if err := table.Compile(); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// Open loads the table from a YAML or JSON file
+func Open(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// close the file
+	defer file.Close()
+
+	return Load(file)
+}
+
+// Compile validates the rules and compiles their patterns
+func (t *Table) Compile() error {
+	for index := range t.Rules {
+		rule := &t.Rules[index]
+
+		if rule.Vendor == "" {
+			return fmt.Errorf("translate: rule %d does not have a vendor", index)
+		}
+
+		if rule.Code == "" && rule.Pattern == "" {
+			return fmt.Errorf("translate: rule %d does not have a code or a pattern", index)
+		}
+
+		if rule.Error.Message == "" {
+			return fmt.Errorf("translate: rule %d does not have a message", index)
+		}
+
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("translate: rule %d has invalid pattern: %w", index, err)
+			}
+
+			rule.pattern = pattern
+		}
+	}
+
+	return nil
+}
+
+// Register registers the extractor of the vendor error codes
+func (t *Table) Register(vendor string, extract Extractor) *Table {
+	if t.extractors == nil {
+		t.extractors = make(map[string]Extractor)
+	}
+
+	t.extractors[vendor] = extract
+	return t
+}
+
+// Translate translates the error by the first matching rule. The translated
+// error wraps the original one and has the vendor and the vendor code in its
+// context. The errors that do not match any rule are wrapped by flaw.Wrap.
+func (t *Table) Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	for index := range t.Rules {
+		rule := &t.Rules[index]
+
+		if code, ok := t.match(rule, err); ok {
+			context := flaw.Map{keyVendor: rule.Vendor}
+
+			if code != "" {
+				context[keyVendorCode] = code
+			}
+
+			return flaw.Errorf("%s", rule.Error.Message).
+				WithCode(rule.Error.Code).
+				WithStatus(status(rule.Error.Status)).
+				WithContext(context).
+				WithError(err)
+		}
+	}
+
+	return flaw.Wrap(err)
+}
+
+// match reports whether the rule matches the error and returns the vendor
+// code of the error
+func (t *Table) match(rule *Rule, err error) (string, bool) {
+	var code string
+
+	if extract, ok := t.extractors[rule.Vendor]; ok {
+		code, _ = extract(err)
+	}
+
+	if rule.Code != "" && rule.Code != code {
+		return "", false
+	}
+
+	if rule.pattern != nil && !rule.pattern.MatchString(err.Error()) {
+		return "", false
+	}
+
+	return code, true
+}
+
+// status returns the status of the template, which is 500 by default as the
+// status of the flaw errors
+func status(value int) int {
+	if value == 0 {
+		return 500
+	}
+
+	return value
+}
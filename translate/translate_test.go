@@ -0,0 +1,107 @@
+package translate_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+	"github.com/phogolabs/flaw/translate"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type StripeError struct {
+	DeclineCode string
+}
+
+func (x *StripeError) Error() string {
+	return "stripe: " + x.DeclineCode
+}
+
+var _ = Describe("Table", func() {
+	var table *translate.Table
+
+	BeforeEach(func() {
+		var err error
+
+		table, err = translate.Load(strings.NewReader(`
+rules:
+  - vendor: stripe
+    code: insufficient_funds
+    error:
+      code: 9
+      status: 402
+      message: the card has insufficient funds
+  - vendor: smtp
+    pattern: "^550 "
+    error:
+      code: 5
+      status: 422
+      message: the mailbox does not exist
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		table.Register("stripe", func(err error) (string, bool) {
+			var failure *StripeError
+
+			if errors.As(err, &failure) {
+				return failure.DeclineCode, true
+			}
+
+			return "", false
+		})
+	})
+
+	It("translates the error by its vendor code", func() {
+		cause := &StripeError{DeclineCode: "insufficient_funds"}
+		err := table.Translate(fmt.Errorf("charge: %w", cause))
+
+		var errx *flaw.Error
+		Expect(errors.As(err, &errx)).To(BeTrue())
+		Expect(errx.Code()).To(Equal(9))
+		Expect(errx.Status()).To(Equal(402))
+		Expect(errx.Message()).To(Equal("the card has insufficient funds"))
+		Expect(errx.Context()).To(HaveKeyWithValue("vendor", "stripe"))
+		Expect(errx.Context()).To(HaveKeyWithValue("vendor_code", "insufficient_funds"))
+		Expect(errors.Is(err, cause)).To(BeTrue())
+	})
+
+	It("translates the error by its message", func() {
+		err := table.Translate(fmt.Errorf("550 mailbox unavailable"))
+
+		Expect(flaw.Code(err)).To(Equal(5))
+		Expect(flaw.Status(err)).To(Equal(422))
+		Expect(flaw.Context(err)).To(HaveKeyWithValue("vendor", "smtp"))
+		Expect(flaw.Context(err)).NotTo(HaveKey("vendor_code"))
+	})
+
+	It("wraps the errors that do not match", func() {
+		cause := &StripeError{DeclineCode: "expired_card"}
+		err := table.Translate(cause)
+
+		Expect(flaw.Code(err)).To(BeZero())
+		Expect(flaw.Cause(err)).To(Equal(cause))
+	})
+
+	It("returns nil for a nil error", func() {
+		Expect(table.Translate(nil)).To(BeNil())
+	})
+
+	Describe("Load", func() {
+		It("returns an error for an invalid rule", func() {
+			_, err := translate.Load(strings.NewReader(`rules: [{vendor: smtp, error: {message: oh no}}]`))
+			Expect(err).To(MatchError("translate: rule 0 does not have a code or a pattern"))
+
+			_, err = translate.Load(strings.NewReader(`rules: [{vendor: smtp, pattern: "[", error: {message: oh no}}]`))
+			Expect(err).To(MatchError(ContainSubstring("invalid pattern")))
+
+			_, err = translate.Load(strings.NewReader(`rules: [{code: "1", error: {message: oh no}}]`))
+			Expect(err).To(MatchError("translate: rule 0 does not have a vendor"))
+
+			_, err = translate.Load(strings.NewReader(`rules: [{vendor: smtp, code: "1"}]`))
+			Expect(err).To(MatchError("translate: rule 0 does not have a message"))
+		})
+	})
+})
@@ -0,0 +1,137 @@
+package flaw
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Transport is the name of a transport, which has its own representation of
+// the errors
+type Transport string
+
+const (
+	// TransportHTTP represents the error as the JSON payload of an HTTP
+	// response, which is a Map
+	TransportHTTP Transport = "http"
+	// TransportGRPC represents the error as a *status.Status
+	TransportGRPC Transport = "grpc"
+	// TransportGraphQL represents the error as a GraphQL error object, which
+	// is a Map with the message and the extensions
+	TransportGraphQL Transport = "graphql"
+	// TransportJSONAPI represents the error as a JSON:API error object, which
+	// is a Map
+	TransportJSONAPI Transport = "jsonapi"
+	// TransportProblem represents the error as RFC 9457 *ProblemDetails
+	TransportProblem Transport = "problem"
+)
+
+// Renderer returns the representation of the error for a transport
+type Renderer func(x *Error) interface{}
+
+var (
+	transports atomic.Value
+	rendering  sync.Mutex
+)
+
+// RegisterTransport registers the renderer of the given transport. The
+// renderers of the built-in transports can be replaced. Pass nil to remove
+// the renderer, which restores the built-in one.
+func RegisterTransport(transport Transport, renderer Renderer) {
+	rendering.Lock()
+	defer rendering.Unlock()
+
+	current, _ := transports.Load().(map[Transport]Renderer)
+	// the map is copied, so the readers do not need a lock
+	next := make(map[Transport]Renderer, len(current)+1)
+
+	for key, item := range current {
+		next[key] = item
+	}
+
+	if renderer == nil {
+		delete(next, transport)
+	} else {
+		next[transport] = renderer
+	}
+
+	transports.Store(next)
+}
+
+// Transport returns the representation of the error for the given transport,
+// so a middleware does not need to know how every transport represents the
+// errors. It returns nil if the transport does not have a renderer.
+func (x *Error) Transport(transport Transport) interface{} {
+	registry, _ := transports.Load().(map[Transport]Renderer)
+
+	if renderer, ok := registry[transport]; ok {
+		return renderer(x)
+	}
+
+	switch transport {
+	case TransportHTTP:
+		return Map(x.payload())
+	case TransportGRPC:
+		// the gRPC status is excluded by the flaw_nogrpc build tag
+		if status := x.rpcStatus(); status != nil {
+			return status
+		}
+	case TransportGraphQL:
+		return x.graphql()
+	case TransportJSONAPI:
+		return x.jsonapi()
+	case TransportProblem:
+		return x.Problem()
+	}
+
+	warnf("flaw: transport %q does not have a renderer", transport)
+	return nil
+}
+
+// graphql returns the error as a GraphQL error object. The code is the name
+// of the gRPC code, which is the convention of the GraphQL servers.
+func (x *Error) graphql() Map {
+	extensions := Map(x.payload())
+	delete(extensions, keyMessage)
+
+	if name := ByCode(x); name != "" {
+		extensions["code"] = name
+	}
+
+	return Map{
+		"message":    clip(x.message()),
+		"extensions": extensions,
+	}
+}
+
+// jsonapi returns the error as a JSON:API error object
+func (x *Error) jsonapi() Map {
+	item := Map{}
+
+	if x.status != 0 {
+		// the status is a string in JSON:API
+		item["status"] = strconv.Itoa(x.status)
+	}
+
+	if name := ByCode(x); name != "" {
+		item["code"] = name
+	}
+
+	if x.message() != "" {
+		item["title"] = clip(x.message())
+	}
+
+	if cause := x.cause(); cause != nil && !x.loops(cause) {
+		item["detail"] = clip(cause.Error())
+	}
+
+	if x.pointer != "" {
+		item["source"] = Map{"pointer": x.pointer}
+	}
+
+	if meta := x.conceal(x.data(keyCode, keyMessage, keyPointer, keyCause, keyStack, keyRemoteStack)); len(meta) > 0 {
+		item["meta"] = Map(meta.render().nest())
+	}
+
+	return item
+}
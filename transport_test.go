@@ -0,0 +1,82 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transport", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("invalid user").
+			WithCode(3).
+			WithStatus(422).
+			WithPointer("/data/user").
+			WithContext(flaw.Map{"user": "root"}).
+			WithError(fmt.Errorf("too short"))
+	})
+
+	AfterEach(func() {
+		flaw.RegisterTransport("teapot", nil)
+		flaw.RegisterTransport(flaw.TransportHTTP, nil)
+	})
+
+	It("returns the HTTP representation", func() {
+		data := errx.Transport(flaw.TransportHTTP)
+		Expect(data).To(HaveKeyWithValue("error_message", "invalid user"))
+		Expect(data).To(HaveKeyWithValue("error_code", 3))
+		Expect(data).To(HaveKeyWithValue("user", "root"))
+		Expect(data).NotTo(HaveKey("error_stack"))
+	})
+
+	It("returns the GraphQL representation", func() {
+		data, ok := errx.Transport(flaw.TransportGraphQL).(flaw.Map)
+		Expect(ok).To(BeTrue())
+		Expect(data).To(HaveKeyWithValue("message", "invalid user"))
+		Expect(data["extensions"]).To(HaveKeyWithValue("code", "InvalidArgument"))
+		Expect(data["extensions"]).To(HaveKeyWithValue("user", "root"))
+		Expect(data["extensions"]).NotTo(HaveKey("error_message"))
+	})
+
+	It("returns the JSON:API representation", func() {
+		data := errx.Transport(flaw.TransportJSONAPI)
+		Expect(data).To(Equal(flaw.Map{
+			"status": "422",
+			"code":   "InvalidArgument",
+			"title":  "invalid user",
+			"detail": "too short",
+			"source": flaw.Map{"pointer": "/data/user"},
+			"meta":   flaw.Map{"user": "root"},
+		}))
+	})
+
+	It("returns the representation of a registered transport", func() {
+		flaw.RegisterTransport("teapot", func(x *flaw.Error) interface{} {
+			return "short and stout: " + x.Message()
+		})
+
+		Expect(errx.Transport("teapot")).To(Equal("short and stout: invalid user"))
+	})
+
+	It("replaces a built-in transport", func() {
+		flaw.RegisterTransport(flaw.TransportHTTP, func(x *flaw.Error) interface{} {
+			return x.Code()
+		})
+
+		Expect(errx.Transport(flaw.TransportHTTP)).To(Equal(3))
+
+		flaw.RegisterTransport(flaw.TransportHTTP, nil)
+		Expect(errx.Transport(flaw.TransportHTTP)).To(HaveKey("error_message"))
+	})
+
+	Context("when the transport is unknown", func() {
+		It("returns nil", func() {
+			Expect(errx.Transport("teapot")).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package flaw_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetTruncator", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		flaw.SetTruncator(flaw.Ellipsis(12))
+
+		query := "SELECT " + strings.Repeat("id, ", 1000) + "name FROM users"
+		errx = flaw.Errorf("cannot run query %s", query).WithError(fmt.Errorf("syntax error in %s", query))
+	})
+
+	AfterEach(func() {
+		flaw.SetTruncator(nil)
+	})
+
+	It("truncates the text format", func() {
+		Expect(errx.Error()).To(Equal("message: cannot…users cause: syntax…users"))
+		Expect(fmt.Sprintf("%m", errx)).To(Equal("cannot…users"))
+	})
+
+	It("truncates the json", func() {
+		data, err := json.Marshal(errx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"error_message":"cannot…users"`))
+		Expect(string(data)).To(ContainSubstring(`"error_cause":"syntax…users"`))
+	})
+
+	It("keeps the short texts", func() {
+		Expect(flaw.Errorf("oh no").Error()).To(Equal("message: oh no"))
+	})
+
+	Describe("Ellipsis", func() {
+		It("preserves the runes", func() {
+			Expect(flaw.Ellipsis(5)("ябълка и круша")).To(Equal("яб…ша"))
+			Expect(flaw.Ellipsis(0)("oh no")).To(Equal("…"))
+		})
+	})
+})
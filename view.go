@@ -0,0 +1,107 @@
+package flaw
+
+// ErrorView is a read-only view of an error, which is safe to share with
+// plugins and templates. The returned values are copies, so they do not
+// reference the internals of the error. The views are created by View only.
+type ErrorView interface {
+	// Code returns the error code
+	Code() int
+	// Status returns the error status
+	Status() int
+	// Message returns the error message
+	Message() string
+	// Details returns a copy of the error details
+	Details() []string
+	// Context returns a copy of the error context
+	Context() Map
+	// StackTrace returns a copy of the error stack trace
+	StackTrace() StackTrace
+
+	// snapshot prevents *Error from implementing the view, so errors.As
+	// cannot assign the mutable error to an ErrorView
+	snapshot()
+}
+
+// View returns a read-only view of the error. The view is a snapshot, so it
+// does not observe later changes of the error.
+func (x *Error) View() ErrorView {
+	errx := *x
+	return &view{errx: &errx}
+}
+
+type view struct {
+	errx *Error
+}
+
+func (v *view) Code() int {
+	return v.errx.code
+}
+
+func (v *view) Status() int {
+	return v.errx.status
+}
+
+func (v *view) Message() string {
+	return v.errx.message()
+}
+
+func (v *view) Details() []string {
+	return append([]string(nil), v.errx.details...)
+}
+
+func (v *view) Context() Map {
+	return v.errx.data()
+}
+
+func (v *view) StackTrace() StackTrace {
+	return append(StackTrace(nil), v.errx.stack...)
+}
+
+func (v *view) snapshot() {}
+
+// ErrorData is a snapshot of the values of an error, which is extracted by
+// errors.As, e.g.
+//
+//	var data flaw.ErrorData
+//
+//	if errors.As(err, &data) {
+//		fmt.Println(data.Code, data.Message)
+//	}
+type ErrorData struct {
+	Code       int
+	Status     int
+	Message    string
+	Details    []string
+	Context    Map
+	StackTrace StackTrace
+}
+
+// Error returns the message of the error
+func (d ErrorData) Error() string {
+	return d.Message
+}
+
+// As sets the target to a snapshot of the error if the target is an
+// *ErrorData or an *ErrorView
+func (x *Error) As(target interface{}) bool {
+	switch item := target.(type) {
+	case *ErrorData:
+		view := x.View()
+
+		*item = ErrorData{
+			Code:       view.Code(),
+			Status:     view.Status(),
+			Message:    view.Message(),
+			Details:    view.Details(),
+			Context:    view.Context(),
+			StackTrace: view.StackTrace(),
+		}
+
+		return true
+	case *ErrorView:
+		*item = x.View()
+		return true
+	default:
+		return false
+	}
+}
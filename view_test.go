@@ -0,0 +1,118 @@
+package flaw_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("View", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithDetails("id is invalid").
+			WithContext(flaw.Map{"user_id": "42"})
+	})
+
+	It("returns the values of the error", func() {
+		view := errx.View()
+
+		Expect(view.Code()).To(Equal(5))
+		Expect(view.Status()).To(Equal(404))
+		Expect(view.Message()).To(Equal("user not found"))
+		Expect(view.Details()).To(ConsistOf("id is invalid"))
+		Expect(view.Context()).To(HaveKeyWithValue("user_id", "42"))
+		Expect(view.StackTrace()).To(Equal(errx.StackTrace()))
+	})
+
+	It("returns copies of the values", func() {
+		view := errx.View()
+
+		view.Details()[0] = "changed"
+		view.Context()["user_id"] = "changed"
+		view.StackTrace()[0] = flaw.StackFrame{}
+
+		Expect(errx.Details()).To(ConsistOf("id is invalid"))
+		Expect(errx.Context()).To(HaveKeyWithValue("user_id", "42"))
+		Expect(errx.StackTrace()[0]).NotTo(Equal(flaw.StackFrame{}))
+	})
+
+	It("does not observe the later changes", func() {
+		view := errx.View()
+		errx.Wrap(fmt.Errorf("oh no"))
+
+		Expect(view.Context()).NotTo(HaveKey("error_cause"))
+	})
+
+	It("cannot be converted to the error", func() {
+		var view interface{} = errx.View()
+
+		_, ok := view.(*flaw.Error)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("As", func() {
+	var errx *flaw.Error
+
+	BeforeEach(func() {
+		errx = flaw.Errorf("user not found").
+			WithCode(5).
+			WithStatus(404).
+			WithDetails("id is invalid").
+			WithContext(flaw.Map{"user_id": "42"})
+	})
+
+	It("extracts the error data", func() {
+		var data flaw.ErrorData
+
+		Expect(errors.As(fmt.Errorf("failed: %w", errx), &data)).To(BeTrue())
+		Expect(data.Code).To(Equal(5))
+		Expect(data.Status).To(Equal(404))
+		Expect(data.Message).To(Equal("user not found"))
+		Expect(data.Details).To(ConsistOf("id is invalid"))
+		Expect(data.Context).To(HaveKeyWithValue("user_id", "42"))
+		Expect(data.StackTrace).To(Equal(errx.StackTrace()))
+	})
+
+	It("extracts a copy of the error data", func() {
+		var data flaw.ErrorData
+
+		Expect(errors.As(errx, &data)).To(BeTrue())
+		data.Details[0] = "changed"
+		data.Context["user_id"] = "changed"
+
+		Expect(errx.Details()).To(ConsistOf("id is invalid"))
+		Expect(errx.Context()).To(HaveKeyWithValue("user_id", "42"))
+	})
+
+	It("extracts the error view", func() {
+		var view flaw.ErrorView
+
+		Expect(errors.As(fmt.Errorf("failed: %w", errx), &view)).To(BeTrue())
+		Expect(view).NotTo(BeAssignableToTypeOf(&flaw.Error{}))
+		Expect(view.Code()).To(Equal(5))
+		Expect(view.Message()).To(Equal("user not found"))
+	})
+
+	It("sets a snapshot view", func() {
+		var view flaw.ErrorView
+
+		Expect(errx.As(&view)).To(BeTrue())
+		Expect(view).NotTo(BeIdenticalTo(errx))
+		Expect(view.Code()).To(Equal(5))
+	})
+
+	It("does not match the other targets", func() {
+		var target *os.PathError
+		Expect(errors.As(errx, &target)).To(BeFalse())
+	})
+})
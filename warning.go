@@ -0,0 +1,42 @@
+package flaw
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// WarningHandler receives the warnings about deprecated or unsafe usage of
+// the package.
+type WarningHandler func(msg string)
+
+type warning struct {
+	handler WarningHandler
+}
+
+var warnings atomic.Value
+
+// SetWarningHandler sets the handler that receives the warnings about
+// deprecated or unsafe usage of the package. The warnings are disabled by
+// default. Pass nil to disable them.
+func SetWarningHandler(handler WarningHandler) {
+	warnings.Store(warning{handler: handler})
+}
+
+// warnf reports a warning annotated with the location of the caller of the
+// function that calls warnf.
+func warnf(msg string, data ...interface{}) {
+	item, _ := warnings.Load().(warning)
+
+	if item.handler == nil {
+		return
+	}
+
+	msg = fmt.Sprintf(msg, data...)
+
+	if _, file, line, ok := runtime.Caller(2); ok {
+		msg = fmt.Sprintf("%s:%d: %s", relative(file), line, msg)
+	}
+
+	item.handler(msg)
+}
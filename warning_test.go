@@ -0,0 +1,73 @@
+package flaw_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/flaw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetWarningHandler", func() {
+	var warnings []string
+
+	BeforeEach(func() {
+		warnings = []string{}
+
+		flaw.SetWarningHandler(func(msg string) {
+			warnings = append(warnings, msg)
+		})
+	})
+
+	AfterEach(func() {
+		flaw.SetWarningHandler(nil)
+	})
+
+	It("warns when the deprecated Wrap is used", func() {
+		errx := flaw.Errorf("failed")
+		errx.Wrap(fmt.Errorf("oh no"))
+
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring("warning_test.go"))
+		Expect(warnings[0]).To(HaveSuffix("flaw: (*Error).Wrap is deprecated, use WithError instead"))
+	})
+
+	It("wraps the error as WithError does", func() {
+		cause := fmt.Errorf("oh no")
+
+		errx := flaw.Errorf("failed")
+		expected := errx.WithError(cause)
+		errx.Wrap(cause)
+
+		Expect(errx.Cause()).To(Equal(cause))
+		Expect(errx.Error()).To(Equal(expected.Error()))
+		Expect(errx.StackTrace()).NotTo(BeEmpty())
+	})
+
+	It("warns when the frames of a flaw error are ignored", func() {
+		frames := flaw.NewStackTrace()
+		flaw.Wrap(flaw.Errorf("oh no"), frames...)
+
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring("warning_test.go"))
+		Expect(warnings[0]).To(HaveSuffix("flaw: Wrap of a flaw error ignores the frames"))
+	})
+
+	It("does not warn when WithError is used", func() {
+		flaw.Errorf("failed").WithError(fmt.Errorf("oh no"))
+		Expect(warnings).To(BeEmpty())
+	})
+
+	Context("when the handler is not set", func() {
+		BeforeEach(func() {
+			flaw.SetWarningHandler(nil)
+		})
+
+		It("does not warn", func() {
+			errx := flaw.Errorf("failed")
+			errx.Wrap(fmt.Errorf("oh no"))
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+})